@@ -17,6 +17,8 @@
 
 package collection
 
+import "github.com/chenquan/go-util/function"
+
 // Element 集合元素
 type Element interface{}
 
@@ -72,4 +74,11 @@ type Collection interface {
 	//
 	// 如果当前集合是有序的,则保证返回的迭代器是有序的.
 	Iterator() Iterator
+	// ForEach 按迭代器的顺序对集合中的每个元素执行 action
+	ForEach(action function.Consumer)
+	// RemoveIf 删除集合中满足 filter 的所有元素
+	//
+	// 如果有元素被删除则返回 true,否则返回 false.
+	// 如果 filter 为 nil 则返回 errs.NilPointer.
+	RemoveIf(filter function.Predicate) (bool, error)
 }
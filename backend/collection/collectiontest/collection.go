@@ -0,0 +1,282 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package collectiontest 集合接口的一致性测试
+//
+// 集合的实现只需提供一个创建空集合的工厂函数,
+// 即可验证其行为是否符合 collection 与 _map 包中接口约定的行为.
+//
+//	func TestSliceList(t *testing.T) {
+//		collectiontest.TestList(t, func() collection.List {
+//			return list.NewSliceListDefault()
+//		})
+//	}
+package collectiontest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+// with 创建包含指定元素的集合
+func with(newCollection func() collection.Collection, elements ...collection.Element) collection.Collection {
+	c := newCollection()
+	for _, e := range elements {
+		_, _ = c.Add(e)
+	}
+	return c
+}
+
+// iterate 使用迭代器遍历集合中的所有元素
+func iterate(t *testing.T, c collection.Collection) []collection.Element {
+	elements := make([]collection.Element, 0, c.Size())
+	itr := c.Iterator()
+	for itr.HasNext() {
+		e, err := itr.Next()
+		if !assert.NoError(t, err) {
+			break
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+// assertError 断言 err 与 target 匹配
+func assertError(t *testing.T, target, err error, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if errors.Is(err, target) {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("error mismatch: expected %v, got %v", target, err), msgAndArgs...)
+}
+
+// TestCollection 测试 collection.Collection 接口约定的行为
+//
+// newCollection 每次调用都应返回一个新的空集合,
+// 测试只使用互不相同的整数作为元素,因此也适用于 collection.Set 的实现.
+func TestCollection(t *testing.T, newCollection func() collection.Collection) {
+	t.Run("Empty", func(t *testing.T) {
+		c := newCollection()
+		assert.Equal(t, 0, c.Size())
+		assert.True(t, c.IsEmpty())
+		contains, err := c.Contains(1)
+		assert.NoError(t, err)
+		assert.False(t, contains)
+		assert.Empty(t, c.Slice())
+
+		itr := c.Iterator()
+		assert.False(t, itr.HasNext())
+		_, err = itr.Next()
+		assertError(t, errs.NoSuchElement, err)
+		assertError(t, errs.IllegalState, itr.Remove())
+	})
+
+	t.Run("AddRemove", func(t *testing.T) {
+		c := newCollection()
+		for i := 1; i <= 5; i++ {
+			added, err := c.Add(i)
+			assert.NoError(t, err)
+			assert.True(t, added)
+			assert.Equal(t, i, c.Size())
+		}
+		assert.False(t, c.IsEmpty())
+		for i := 1; i <= 5; i++ {
+			contains, err := c.Contains(i)
+			assert.NoError(t, err)
+			assert.True(t, contains, "contains %v", i)
+		}
+		contains, _ := c.Contains(99)
+		assert.False(t, contains)
+		assert.ElementsMatch(t, []collection.Element{1, 2, 3, 4, 5}, c.Slice())
+
+		removed, err := c.Remove(3)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+		removed, err = c.Remove(3)
+		assert.NoError(t, err)
+		assert.False(t, removed)
+		assert.Equal(t, 4, c.Size())
+		contains, _ = c.Contains(3)
+		assert.False(t, contains)
+		assert.ElementsMatch(t, []collection.Element{1, 2, 4, 5}, c.Slice())
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3)
+		slice := c.Slice()
+		slice[0] = 99
+		contains, _ := c.Contains(99)
+		assert.False(t, contains, "modifying the slice must not affect the collection")
+		assert.ElementsMatch(t, c.Slice(), iterate(t, c))
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3, 4, 5)
+
+		contains, err := c.ContainsAll(with(newCollection, 1, 5))
+		assert.NoError(t, err)
+		assert.True(t, contains)
+		contains, _ = c.ContainsAll(with(newCollection, 1, 99))
+		assert.False(t, contains)
+		contains, _ = c.ContainsAll(newCollection())
+		assert.True(t, contains)
+
+		modified, err := c.AddAll(with(newCollection, 6, 7))
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.Equal(t, 7, c.Size())
+		modified, _ = c.AddAll(newCollection())
+		assert.False(t, modified)
+
+		modified, err = c.RemoveAll(with(newCollection, 1, 2, 99))
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.ElementsMatch(t, []collection.Element{3, 4, 5, 6, 7}, c.Slice())
+		modified, _ = c.RemoveAll(with(newCollection, 99))
+		assert.False(t, modified)
+
+		modified, err = c.RetainAll(with(newCollection, 4, 5, 6, 99))
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.ElementsMatch(t, []collection.Element{4, 5, 6}, c.Slice())
+		modified, _ = c.RetainAll(with(newCollection, 4, 5, 6))
+		assert.False(t, modified)
+		assert.Equal(t, 3, c.Size())
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3, 4, 5, 6)
+		assert.ElementsMatch(t, []collection.Element{1, 2, 3, 4, 5, 6}, iterate(t, c))
+
+		itr := c.Iterator()
+		assertError(t, errs.IllegalState, itr.Remove())
+		visited := 0
+		for itr.HasNext() {
+			e, err := itr.Next()
+			assert.NoError(t, err)
+			visited++
+			if e.(int)%2 == 0 {
+				assert.NoError(t, itr.Remove())
+				assertError(t, errs.IllegalState, itr.Remove())
+			}
+		}
+		assert.Equal(t, 6, visited)
+		_, err := itr.Next()
+		assertError(t, errs.NoSuchElement, err)
+		assert.Equal(t, 3, c.Size())
+		assert.ElementsMatch(t, []collection.Element{1, 3, 5}, c.Slice())
+		assert.ElementsMatch(t, []collection.Element{1, 3, 5}, iterate(t, c))
+	})
+
+	t.Run("ForEach", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3)
+		var visited []collection.Element
+		c.ForEach(func(e interface{}) {
+			visited = append(visited, e)
+		})
+		assert.ElementsMatch(t, iterate(t, c), visited)
+		newCollection().ForEach(func(e interface{}) {
+			assert.Fail(t, "action called on empty collection")
+		})
+	})
+
+	t.Run("RemoveIf", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3, 4, 5, 6)
+		modified, err := c.RemoveIf(func(e interface{}) bool {
+			return e.(int)%2 == 0
+		})
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.Equal(t, 3, c.Size())
+		assert.ElementsMatch(t, []collection.Element{1, 3, 5}, c.Slice())
+
+		modified, err = c.RemoveIf(func(e interface{}) bool {
+			return e.(int) > 10
+		})
+		assert.NoError(t, err)
+		assert.False(t, modified)
+		assert.Equal(t, 3, c.Size())
+
+		modified, err = c.RemoveIf(func(e interface{}) bool {
+			return true
+		})
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.True(t, c.IsEmpty())
+
+		_, err = c.RemoveIf(nil)
+		assertError(t, errs.NilPointer, err)
+	})
+
+	t.Run("ClearEquals", func(t *testing.T) {
+		c := with(newCollection, 1, 2, 3)
+		assert.True(t, c.Equals(c))
+		assert.True(t, c.Equals(with(newCollection, 1, 2, 3)))
+		assert.False(t, c.Equals(with(newCollection, 1, 2)))
+		assert.False(t, c.Equals(with(newCollection, 1, 2, 4)))
+
+		assert.NoError(t, c.Clear())
+		assert.Equal(t, 0, c.Size())
+		assert.True(t, c.IsEmpty())
+		assert.Empty(t, c.Slice())
+		assert.False(t, c.Iterator().HasNext())
+		assert.True(t, c.Equals(newCollection()))
+
+		_, _ = c.Add(1)
+		assert.ElementsMatch(t, []collection.Element{1}, c.Slice())
+	})
+}
+
+// TestSet 测试 collection.Set 接口约定的行为
+//
+// 除 TestCollection 的全部测试外,还验证集不允许重复元素且相等性与顺序无关.
+func TestSet(t *testing.T, newSet func() collection.Set) {
+	newCollection := func() collection.Collection {
+		return newSet()
+	}
+	TestCollection(t, newCollection)
+
+	t.Run("Duplicates", func(t *testing.T) {
+		s := newSet()
+		added, err := s.Add(1)
+		assert.NoError(t, err)
+		assert.True(t, added)
+		added, err = s.Add(1)
+		assert.NoError(t, err)
+		assert.False(t, added)
+		assert.Equal(t, 1, s.Size())
+
+		modified, _ := s.AddAll(with(newCollection, 1))
+		assert.False(t, modified)
+		assert.Equal(t, 1, s.Size())
+		removed, _ := s.Remove(1)
+		assert.True(t, removed)
+		assert.True(t, s.IsEmpty())
+	})
+
+	t.Run("EqualsUnordered", func(t *testing.T) {
+		s1 := with(newCollection, 1, 2, 3)
+		s2 := with(newCollection, 3, 2, 1)
+		assert.True(t, s1.Equals(s2))
+		assert.True(t, s2.Equals(s1))
+	})
+}
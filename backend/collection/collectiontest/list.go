@@ -0,0 +1,195 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package collectiontest
+
+import (
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestList 测试 collection.List 接口约定的行为
+//
+// 除 TestCollection 的全部测试外,还验证列表保持插入顺序、允许重复元素以及按索引访问的行为.
+func TestList(t *testing.T, newList func() collection.List) {
+	newCollection := func() collection.Collection {
+		return newList()
+	}
+	TestCollection(t, newCollection)
+
+	t.Run("Order", func(t *testing.T) {
+		l := with(newCollection, 3, 1, 2, 1)
+		assert.Equal(t, 4, l.Size())
+		assert.Equal(t, []collection.Element{3, 1, 2, 1}, l.Slice())
+		assert.Equal(t, []collection.Element{3, 1, 2, 1}, iterate(t, l))
+
+		removed, _ := l.Remove(1)
+		assert.True(t, removed)
+		assert.Equal(t, []collection.Element{3, 2, 1}, l.Slice())
+
+		assert.True(t, l.Equals(with(newCollection, 3, 2, 1)))
+		assert.False(t, l.Equals(with(newCollection, 1, 2, 3)))
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		l := newList()
+		_, _ = l.AddAll(with(newCollection, 1, 2, 3, 2, 1))
+		assert.Equal(t, 0, l.Index(1))
+		assert.Equal(t, 4, l.LastIndex(1))
+		assert.Equal(t, 1, l.Index(2))
+		assert.Equal(t, 3, l.LastIndex(2))
+		assert.Equal(t, -1, l.Index(99))
+		assert.Equal(t, -1, l.LastIndex(99))
+
+		for i, want := range []collection.Element{1, 2, 3, 2, 1} {
+			e, err := l.Get(i)
+			assert.NoError(t, err)
+			assert.Equal(t, want, e)
+		}
+		for _, i := range []int{-1, 5} {
+			_, err := l.Get(i)
+			assertError(t, errs.IndexOutOfBound, err, "Get(%d)", i)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		l := newList()
+		_, _ = l.AddAll(with(newCollection, 1, 2, 3))
+		old, err := l.Set(1, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, old)
+		assert.Equal(t, []collection.Element{1, 20, 3}, l.Slice())
+		for _, i := range []int{-1, 3} {
+			_, err := l.Set(i, 0)
+			assertError(t, errs.IndexOutOfBound, err, "Set(%d)", i)
+		}
+		assert.Equal(t, []collection.Element{1, 20, 3}, l.Slice())
+	})
+
+	t.Run("AddIndex", func(t *testing.T) {
+		l := newList()
+		assert.NoError(t, l.AddIndex(0, 2))
+		assert.NoError(t, l.AddIndex(0, 1))
+		assert.NoError(t, l.AddIndex(2, 4))
+		assert.NoError(t, l.AddIndex(2, 3))
+		assert.Equal(t, []collection.Element{1, 2, 3, 4}, l.Slice())
+		for _, i := range []int{-1, 5} {
+			assertError(t, errs.IndexOutOfBound, l.AddIndex(i, 0), "AddIndex(%d)", i)
+		}
+		assert.Equal(t, 4, l.Size())
+
+		modified, err := l.AddAllIndex(1, with(newCollection, 10, 11))
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.Equal(t, []collection.Element{1, 10, 11, 2, 3, 4}, l.Slice())
+		modified, err = l.AddAllIndex(6, with(newCollection, 12))
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.Equal(t, []collection.Element{1, 10, 11, 2, 3, 4, 12}, l.Slice())
+		modified, _ = l.AddAllIndex(0, newCollection())
+		assert.False(t, modified)
+		for _, i := range []int{-1, 8} {
+			_, err := l.AddAllIndex(i, with(newCollection, 0))
+			assertError(t, errs.IndexOutOfBound, err, "AddAllIndex(%d)", i)
+		}
+		assert.Equal(t, 7, l.Size())
+	})
+
+	t.Run("RemoveIndex", func(t *testing.T) {
+		l := newList()
+		_, _ = l.AddAll(with(newCollection, 1, 2, 3, 4))
+		e, err := l.RemoveIndex(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, e)
+		e, err = l.RemoveIndex(2)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, e)
+		e, err = l.RemoveIndex(0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, e)
+		assert.Equal(t, []collection.Element{3}, l.Slice())
+		for _, i := range []int{-1, 1} {
+			_, err := l.RemoveIndex(i)
+			assertError(t, errs.IndexOutOfBound, err, "RemoveIndex(%d)", i)
+		}
+		assert.Equal(t, 1, l.Size())
+	})
+
+	t.Run("RemoveIfOrder", func(t *testing.T) {
+		l := with(newCollection, 1, 2, 3, 2, 1)
+		modified, err := l.RemoveIf(func(e interface{}) bool {
+			return e == 2
+		})
+		assert.NoError(t, err)
+		assert.True(t, modified)
+		assert.Equal(t, []collection.Element{1, 3, 1}, l.Slice())
+
+		var visited []collection.Element
+		l.ForEach(func(e interface{}) {
+			visited = append(visited, e)
+		})
+		assert.Equal(t, []collection.Element{1, 3, 1}, visited)
+	})
+
+	t.Run("ReplaceAll", func(t *testing.T) {
+		l := newList()
+		_, _ = l.AddAll(with(newCollection, 1, 2, 3))
+		l.ReplaceAll(func(e interface{}) interface{} {
+			return e.(int) * 10
+		})
+		assert.Equal(t, []collection.Element{10, 20, 30}, l.Slice())
+		e, _ := l.Get(1)
+		assert.Equal(t, 20, e)
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		l := newList()
+		_, _ = l.AddAll(with(newCollection, 3, 1, 4, 1, 5, 9, 2, 6))
+		l.Sort(nil)
+		assert.Equal(t, []collection.Element{1, 1, 2, 3, 4, 5, 6, 9}, l.Slice())
+		l.Sort(function.Comparator(function.NaturalOrder).Reversed())
+		assert.Equal(t, []collection.Element{9, 6, 5, 4, 3, 2, 1, 1}, l.Slice())
+
+		// 排序是稳定的:按个位数排序时,个位数相同的元素保持原有的相对顺序
+		l = newList()
+		_, _ = l.AddAll(with(newCollection, 21, 12, 11, 22, 1, 2))
+		l.Sort(func(o1, o2 interface{}) int {
+			return o1.(int)%10 - o2.(int)%10
+		})
+		assert.Equal(t, []collection.Element{21, 11, 1, 12, 22, 2}, l.Slice())
+
+		l = newList()
+		l.Sort(nil)
+		assert.True(t, l.IsEmpty())
+	})
+
+	t.Run("IteratorRemoveOrder", func(t *testing.T) {
+		l := with(newCollection, 1, 2, 3, 4, 5)
+		itr := l.Iterator()
+		for itr.HasNext() {
+			e, _ := itr.Next()
+			if e == 1 || e == 3 || e == 5 {
+				assert.NoError(t, itr.Remove())
+			}
+		}
+		assert.Equal(t, []collection.Element{2, 4}, l.Slice())
+	})
+}
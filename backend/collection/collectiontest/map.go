@@ -0,0 +1,364 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package collectiontest
+
+import (
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMap 测试 _map.Map 接口约定的行为
+//
+// newMap 每次调用都应返回一个新的空映射,
+// 测试中不同的键总是映射到不同的值,因此也适用于 _map.BiMap 的实现.
+func TestMap(t *testing.T, newMap func() _map.Map) {
+	withEntries := func(kvs ...interface{}) _map.Map {
+		m := newMap()
+		for i := 0; i < len(kvs); i += 2 {
+			_, _ = m.Put(kvs[i], kvs[i+1])
+		}
+		return m
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		m := newMap()
+		assert.Equal(t, 0, m.Size())
+		assert.True(t, m.IsEmpty())
+		contains, err := m.ContainsKey("a")
+		assert.NoError(t, err)
+		assert.False(t, contains)
+		contains, err = m.ContainsValue(1)
+		assert.NoError(t, err)
+		assert.False(t, contains)
+		v, err := m.Get("a")
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		assert.Equal(t, 0, m.GetOrDefault("a", 0))
+		assert.True(t, m.KeySet().IsEmpty())
+		assert.True(t, m.Values().IsEmpty())
+		assert.True(t, m.EntrySet().IsEmpty())
+	})
+
+	t.Run("PutGetRemove", func(t *testing.T) {
+		m := newMap()
+		old, err := m.Put("a", 1)
+		assert.NoError(t, err)
+		assert.Nil(t, old)
+		_, _ = m.Put("b", 2)
+		assert.Equal(t, 2, m.Size())
+		assert.False(t, m.IsEmpty())
+
+		v, err := m.Get("a")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+		assert.Equal(t, 2, m.GetOrDefault("b", 0))
+		contains, _ := m.ContainsKey("b")
+		assert.True(t, contains)
+		contains, _ = m.ContainsValue(2)
+		assert.True(t, contains)
+
+		old, err = m.Put("a", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, old)
+		assert.Equal(t, 2, m.Size())
+		contains, _ = m.ContainsValue(1)
+		assert.False(t, contains)
+
+		old, err = m.Remove("a")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, old)
+		old, err = m.Remove("a")
+		assert.NoError(t, err)
+		assert.Nil(t, old)
+		assert.Equal(t, 1, m.Size())
+		contains, _ = m.ContainsKey("a")
+		assert.False(t, contains)
+	})
+
+	t.Run("NilValue", func(t *testing.T) {
+		m := newMap()
+		_, _ = m.Put("a", nil)
+		contains, _ := m.ContainsKey("a")
+		assert.True(t, contains)
+		assert.Nil(t, m.GetOrDefault("a", 1))
+		assert.Equal(t, 1, m.Size())
+
+		// 映射到 nil 的键对 PutIfAbsent 等方法视为不存在
+		old, err := m.PutIfAbsent("a", 1)
+		assert.NoError(t, err)
+		assert.Nil(t, old)
+		assert.Equal(t, 1, m.GetOrDefault("a", nil))
+
+		_, _ = m.Put("b", nil)
+		v, err := m.ComputeIfAbsent("b", func(k interface{}) interface{} { return 2 })
+		assert.NoError(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, 2, m.GetOrDefault("b", nil))
+
+		_, _ = m.Put("c", nil)
+		calls := 0
+		v, err = m.ComputeIfPresent("c", func(k, v interface{}) interface{} {
+			calls++
+			return 3
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		assert.Equal(t, 0, calls)
+		assert.Nil(t, m.GetOrDefault("c", 0))
+
+		v, err = m.Merge("c", 3, func(old, v interface{}) interface{} {
+			calls++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, v)
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, 3, m.GetOrDefault("c", nil))
+
+		_, _ = m.Put("d", nil)
+		v, err = m.Compute("d", func(k, v interface{}) interface{} {
+			assert.Nil(t, v)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		contains, _ = m.ContainsKey("d")
+		assert.False(t, contains)
+		assert.Equal(t, 3, m.Size())
+	})
+
+	t.Run("PutAllClear", func(t *testing.T) {
+		m := withEntries("a", 1)
+		assert.NoError(t, m.PutAll(withEntries("b", 2, "c", 3)))
+		assert.Equal(t, 3, m.Size())
+		assert.Equal(t, 3, m.GetOrDefault("c", 0))
+		assertError(t, errs.NilPointer, m.PutAll(nil))
+
+		assert.NoError(t, m.Clear())
+		assert.Equal(t, 0, m.Size())
+		assert.True(t, m.IsEmpty())
+		contains, _ := m.ContainsKey("a")
+		assert.False(t, contains)
+	})
+
+	t.Run("Views", func(t *testing.T) {
+		m := withEntries("a", 1, "b", 2, "c", 3)
+		assert.ElementsMatch(t, []collection.Element{"a", "b", "c"}, m.KeySet().Slice())
+		assert.ElementsMatch(t, []collection.Element{1, 2, 3}, m.Values().Slice())
+
+		entries := m.EntrySet()
+		assert.Equal(t, 3, entries.Size())
+		itr := entries.Iterator()
+		for itr.HasNext() {
+			next, err := itr.Next()
+			assert.NoError(t, err)
+			e := next.(_map.Entry)
+			k, err := e.Key()
+			assert.NoError(t, err)
+			v, err := e.Value()
+			assert.NoError(t, err)
+			assert.Equal(t, m.GetOrDefault(k, nil), v)
+			if k == "b" {
+				old, err := e.SetValue(20)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, old)
+			}
+		}
+		assert.Equal(t, 20, m.GetOrDefault("b", nil))
+	})
+
+	t.Run("EqualsHashCode", func(t *testing.T) {
+		m1 := withEntries("a", 1, "b", 2)
+		m2 := withEntries("b", 2, "a", 1)
+		assert.True(t, m1.Equals(m1))
+		assert.True(t, m1.Equals(m2))
+		assert.True(t, m2.Equals(m1))
+		assert.Equal(t, m1.HashCode(), m2.HashCode())
+		assert.False(t, m1.Equals(withEntries("a", 1)))
+		assert.False(t, m1.Equals(withEntries("a", 1, "b", 3)))
+		assert.False(t, m1.Equals(withEntries("a", 1, "c", 2)))
+		assert.False(t, m1.Equals(nil))
+		assert.True(t, newMap().Equals(newMap()))
+	})
+
+	t.Run("ForEach", func(t *testing.T) {
+		m := withEntries("a", 1, "b", 2, "c", 3)
+		got := make(map[interface{}]interface{})
+		m.ForEach(func(k, v interface{}) {
+			got[k] = v
+		})
+		assert.Equal(t, map[interface{}]interface{}{"a": 1, "b": 2, "c": 3}, got)
+
+		calls := 0
+		newMap().ForEach(func(k, v interface{}) { calls++ })
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("PutIfAbsent", func(t *testing.T) {
+		m := withEntries("a", 1)
+		v, err := m.PutIfAbsent("a", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+		v, err = m.PutIfAbsent("b", 2)
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		assert.Equal(t, 2, m.GetOrDefault("b", nil))
+		assert.Equal(t, 2, m.Size())
+	})
+
+	t.Run("ComputeIfAbsent", func(t *testing.T) {
+		m := withEntries("a", 1)
+		calls := 0
+		mapping := func(k interface{}) interface{} {
+			calls++
+			return k.(string) + "!"
+		}
+		v, err := m.ComputeIfAbsent("a", mapping)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+		assert.Equal(t, 0, calls)
+
+		v, err = m.ComputeIfAbsent("b", mapping)
+		assert.NoError(t, err)
+		assert.Equal(t, "b!", v)
+		assert.Equal(t, "b!", m.GetOrDefault("b", nil))
+		assert.Equal(t, 1, calls)
+
+		v, err = m.ComputeIfAbsent("c", func(k interface{}) interface{} { return nil })
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		contains, _ := m.ContainsKey("c")
+		assert.False(t, contains)
+
+		_, err = m.ComputeIfAbsent("d", nil)
+		assertError(t, errs.NilPointer, err)
+	})
+
+	t.Run("ComputeIfPresent", func(t *testing.T) {
+		m := withEntries("a", 1, "b", 2)
+		var add function.BiFunction = func(k, v interface{}) interface{} { return v.(int) + 10 }
+		v, err := m.ComputeIfPresent("a", add)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, v)
+		assert.Equal(t, 11, m.GetOrDefault("a", nil))
+
+		v, err = m.ComputeIfPresent("c", add)
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		contains, _ := m.ContainsKey("c")
+		assert.False(t, contains)
+
+		v, err = m.ComputeIfPresent("b", func(k, v interface{}) interface{} { return nil })
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		contains, _ = m.ContainsKey("b")
+		assert.False(t, contains)
+
+		_, err = m.ComputeIfPresent("a", nil)
+		assertError(t, errs.NilPointer, err)
+	})
+
+	t.Run("Compute", func(t *testing.T) {
+		m := withEntries("a", 1)
+		var count function.BiFunction = func(k, v interface{}) interface{} {
+			if v == nil {
+				return 100
+			}
+			return v.(int) + 10
+		}
+		v, err := m.Compute("a", count)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, v)
+		v, err = m.Compute("b", count)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, v)
+		assert.Equal(t, 2, m.Size())
+
+		v, err = m.Compute("a", func(k, v interface{}) interface{} { return nil })
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		assert.Equal(t, 1, m.Size())
+		v, err = m.Compute("c", func(k, v interface{}) interface{} { return nil })
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		assert.Equal(t, 1, m.Size())
+
+		_, err = m.Compute("a", nil)
+		assertError(t, errs.NilPointer, err)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		m := withEntries("a", 1)
+		var sum function.BiFunction = func(old, v interface{}) interface{} { return old.(int) + v.(int) }
+		v, err := m.Merge("a", 10, sum)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, v)
+		v, err = m.Merge("b", 2, sum)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, 2, m.GetOrDefault("b", nil))
+
+		v, err = m.Merge("a", 1, func(old, v interface{}) interface{} { return nil })
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+		contains, _ := m.ContainsKey("a")
+		assert.False(t, contains)
+
+		_, err = m.Merge("a", nil, sum)
+		assertError(t, errs.NilPointer, err)
+		_, err = m.Merge("a", 1, nil)
+		assertError(t, errs.NilPointer, err)
+	})
+
+	t.Run("ReplaceAll", func(t *testing.T) {
+		m := withEntries("a", 1, "b", 2, "c", 3)
+		assert.NoError(t, m.ReplaceAll(func(k, v interface{}) interface{} {
+			return k.(string) + string(rune('0'+v.(int)))
+		}))
+		assert.Equal(t, 3, m.Size())
+		assert.Equal(t, "a1", m.GetOrDefault("a", nil))
+		assert.Equal(t, "b2", m.GetOrDefault("b", nil))
+		assert.Equal(t, "c3", m.GetOrDefault("c", nil))
+		assert.ElementsMatch(t, []collection.Element{"a1", "b2", "c3"}, m.Values().Slice())
+
+		assertError(t, errs.NilPointer, m.ReplaceAll(nil))
+	})
+
+	t.Run("RemoveIfEquals", func(t *testing.T) {
+		m := withEntries("a", 1, "b", 2)
+		removed, err := m.RemoveIfEquals("a", 2)
+		assert.NoError(t, err)
+		assert.False(t, removed)
+		removed, err = m.RemoveIfEquals("c", 1)
+		assert.NoError(t, err)
+		assert.False(t, removed)
+		assert.Equal(t, 2, m.Size())
+
+		removed, err = m.RemoveIfEquals("a", 1)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+		contains, _ := m.ContainsKey("a")
+		assert.False(t, contains)
+		assert.Equal(t, 1, m.Size())
+	})
+}
@@ -0,0 +1,584 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package collectiontest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// 随机测试的步数
+	modelSteps = 2000
+	// 随机元素的取值范围,较小的取值范围使重复元素与删除命中更频繁
+	modelRange = 32
+	// 随机种子
+	modelSeed = 2021
+)
+
+// model 随机测试的上下文
+type model struct {
+	t    *testing.T
+	rand *rand.Rand
+	step int
+	op   string
+}
+
+// newModel 创建随机测试的上下文
+func newModel(t *testing.T) *model {
+	return &model{t: t, rand: rand.New(rand.NewSource(modelSeed))}
+}
+
+// run 执行 modelSteps 步随机操作,每一步从 ops 中随机选择一个操作执行
+//
+// 每次操作后调用 check 校验被测对象与参考实现的状态一致,出现不一致时立即停止.
+func (m *model) run(ops map[string]func(), check func() bool) {
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	// map 的遍历顺序不确定,排序以保证相同的种子产生相同的操作序列
+	sort.Strings(names)
+	for m.step = 0; m.step < modelSteps; m.step++ {
+		m.op = names[m.rand.Intn(len(names))]
+		ops[m.op]()
+		if !check() || m.t.Failed() {
+			m.t.Fatalf("model mismatch at step %d after %s (seed %d)", m.step, m.op, modelSeed)
+		}
+	}
+}
+
+// element 返回随机元素
+func (m *model) element() collection.Element {
+	return m.rand.Intn(modelRange)
+}
+
+// msg 返回当前步骤的描述
+func (m *model) msg() string {
+	return fmt.Sprintf("step %d: %s", m.step, m.op)
+}
+
+// index 返回元素在切片中首次出现的索引,不存在则返回-1
+func index(s []collection.Element, e collection.Element) int {
+	for i, x := range s {
+		if x == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndex 返回元素在切片中最后一次出现的索引,不存在则返回-1
+func lastIndex(s []collection.Element, e collection.Element) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeAt 删除切片中指定索引的元素
+func removeAt(s []collection.Element, i int) []collection.Element {
+	return append(s[:i], s[i+1:]...)
+}
+
+// insertAt 在切片的指定索引处插入元素
+func insertAt(s []collection.Element, i int, e collection.Element) []collection.Element {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = e
+	return s
+}
+
+// iteratorRemove 使用迭代器遍历集合,随机删除部分元素,同时对参考切片做相同的删除
+//
+// 要求迭代器按参考切片的顺序返回元素.
+func (m *model) iteratorRemove(itr collection.Iterator, ref []collection.Element, descending bool) []collection.Element {
+	kept := make([]collection.Element, 0, len(ref))
+	for i := 0; i < len(ref); i++ {
+		j := i
+		if descending {
+			j = len(ref) - 1 - i
+		}
+		if !assert.True(m.t, itr.HasNext(), m.msg()) {
+			return ref
+		}
+		e, err := itr.Next()
+		assert.NoError(m.t, err, m.msg())
+		assert.Equal(m.t, ref[j], e, m.msg())
+		if m.rand.Intn(3) == 0 {
+			assert.NoError(m.t, itr.Remove(), m.msg())
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	assert.False(m.t, itr.HasNext(), m.msg())
+	if descending {
+		for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+			kept[i], kept[j] = kept[j], kept[i]
+		}
+	}
+	return kept
+}
+
+// removeIf 随机选择一个断言调用集合的 RemoveIf 方法,同时对参考切片做相同的删除
+//
+// 要求断言按参考切片的顺序被调用.
+func (m *model) removeIf(c collection.Collection, ref []collection.Element) []collection.Element {
+	x := m.rand.Intn(modelRange) + 2
+	i := 0
+	modified, err := c.RemoveIf(func(e interface{}) bool {
+		if assert.Less(m.t, i, len(ref), m.msg()) {
+			assert.Equal(m.t, ref[i], e, m.msg())
+		}
+		i++
+		return e.(int)%x == 0
+	})
+	assert.NoError(m.t, err, m.msg())
+	assert.Equal(m.t, len(ref), i, m.msg())
+	kept := ref[:0]
+	for _, e := range ref {
+		if e.(int)%x != 0 {
+			kept = append(kept, e)
+		}
+	}
+	assert.Equal(m.t, len(kept) != len(ref), modified, m.msg())
+	return kept
+}
+
+// TestListModel 对 collection.List 的实现进行随机测试
+//
+// 以固定的随机种子执行一系列随机操作,并与基于切片的参考实现逐步比较结果.
+func TestListModel(t *testing.T, newList func() collection.List) {
+	m := newModel(t)
+	l := newList()
+	var ref []collection.Element
+	ops := map[string]func(){
+		"Add": func() {
+			e := m.element()
+			added, err := l.Add(e)
+			assert.NoError(t, err, m.msg())
+			assert.True(t, added, m.msg())
+			ref = append(ref, e)
+		},
+		"AddIndex": func() {
+			i, e := m.rand.Intn(len(ref)+1), m.element()
+			assert.NoError(t, l.AddIndex(i, e), m.msg())
+			ref = insertAt(ref, i, e)
+		},
+		"AddAllIndex": func() {
+			i := m.rand.Intn(len(ref) + 1)
+			c := newList()
+			for n := m.rand.Intn(3); n > 0; n-- {
+				e := m.element()
+				_, _ = c.Add(e)
+				ref = insertAt(ref, i+c.Size()-1, e)
+			}
+			modified, err := l.AddAllIndex(i, c)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, c.Size() != 0, modified, m.msg())
+		},
+		"RemoveIndex": func() {
+			if len(ref) == 0 {
+				return
+			}
+			i := m.rand.Intn(len(ref))
+			e, err := l.RemoveIndex(i)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[i], e, m.msg())
+			ref = removeAt(ref, i)
+		},
+		"Remove": func() {
+			e := m.element()
+			removed, err := l.Remove(e)
+			assert.NoError(t, err, m.msg())
+			i := index(ref, e)
+			assert.Equal(t, i >= 0, removed, m.msg())
+			if i >= 0 {
+				ref = removeAt(ref, i)
+			}
+		},
+		"Set": func() {
+			if len(ref) == 0 {
+				return
+			}
+			i, e := m.rand.Intn(len(ref)), m.element()
+			old, err := l.Set(i, e)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[i], old, m.msg())
+			ref[i] = e
+		},
+		"Get": func() {
+			if len(ref) == 0 {
+				return
+			}
+			i := m.rand.Intn(len(ref))
+			e, err := l.Get(i)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[i], e, m.msg())
+		},
+		"Index": func() {
+			e := m.element()
+			assert.Equal(t, index(ref, e), l.Index(e), m.msg())
+			assert.Equal(t, lastIndex(ref, e), l.LastIndex(e), m.msg())
+			contains, _ := l.Contains(e)
+			assert.Equal(t, index(ref, e) >= 0, contains, m.msg())
+		},
+		"IteratorRemove": func() {
+			ref = m.iteratorRemove(l.Iterator(), ref, false)
+		},
+		"RemoveIf": func() {
+			ref = m.removeIf(l, ref)
+		},
+		"ReplaceAll": func() {
+			delta := m.rand.Intn(3) - 1
+			l.ReplaceAll(func(e interface{}) interface{} {
+				return (e.(int) + delta + modelRange) % modelRange
+			})
+			for i, e := range ref {
+				ref[i] = (e.(int) + delta + modelRange) % modelRange
+			}
+		},
+		"Sort": func() {
+			// 按元素除以4的商排序,商相同的元素应保持原有的相对顺序
+			c := func(o1, o2 interface{}) int {
+				return o1.(int)/4 - o2.(int)/4
+			}
+			l.Sort(c)
+			sort.SliceStable(ref, func(i, j int) bool {
+				return c(ref[i], ref[j]) < 0
+			})
+		},
+		"Clear": func() {
+			// 降低清空的频率,使列表能增长到一定的规模
+			if m.rand.Intn(10) == 0 {
+				assert.NoError(t, l.Clear(), m.msg())
+				ref = ref[:0]
+			}
+		},
+	}
+	m.run(ops, func() bool {
+		return assert.Equal(t, len(ref), l.Size(), m.msg()) &&
+			assert.Equal(t, sliceOf(ref), sliceOf(l.Slice()), m.msg())
+	})
+}
+
+// TestDeQueueModel 对 collection.DeQueue 的实现进行随机测试
+//
+// 以固定的随机种子执行一系列随机操作,并与基于切片的参考实现逐步比较结果.
+func TestDeQueueModel(t *testing.T, newDeQueue func() collection.DeQueue) {
+	m := newModel(t)
+	d := newDeQueue()
+	var ref []collection.Element
+	ops := map[string]func(){
+		"AddFirst": func() {
+			e := m.element()
+			assert.NoError(t, d.AddFirst(e), m.msg())
+			ref = insertAt(ref, 0, e)
+		},
+		"AddLast": func() {
+			e := m.element()
+			assert.NoError(t, d.AddLast(e), m.msg())
+			ref = append(ref, e)
+		},
+		"Offer": func() {
+			e := m.element()
+			offered, err := d.Offer(e)
+			assert.NoError(t, err, m.msg())
+			assert.True(t, offered, m.msg())
+			ref = append(ref, e)
+		},
+		"RemoveFirst": func() {
+			e, err := d.RemoveFirst()
+			if len(ref) == 0 {
+				assert.Error(t, err, m.msg())
+				return
+			}
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[0], e, m.msg())
+			ref = removeAt(ref, 0)
+		},
+		"RemoveLast": func() {
+			e, err := d.RemoveLast()
+			if len(ref) == 0 {
+				assert.Error(t, err, m.msg())
+				return
+			}
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[len(ref)-1], e, m.msg())
+			ref = ref[:len(ref)-1]
+		},
+		"Poll": func() {
+			e := d.Poll()
+			if len(ref) == 0 {
+				assert.Nil(t, e, m.msg())
+				return
+			}
+			assert.Equal(t, ref[0], e, m.msg())
+			ref = removeAt(ref, 0)
+		},
+		"Peek": func() {
+			first, _ := d.GetFirst()
+			last, _ := d.GetLast()
+			if len(ref) == 0 {
+				assert.Nil(t, d.Peek(), m.msg())
+				return
+			}
+			assert.Equal(t, ref[0], d.Peek(), m.msg())
+			assert.Equal(t, ref[0], first, m.msg())
+			assert.Equal(t, ref[len(ref)-1], last, m.msg())
+		},
+		"RemoveFirstOccurrence": func() {
+			e := m.element()
+			removed, err := d.RemoveFirstOccurrence(e)
+			assert.NoError(t, err, m.msg())
+			i := index(ref, e)
+			assert.Equal(t, i >= 0, removed, m.msg())
+			if i >= 0 {
+				ref = removeAt(ref, i)
+			}
+		},
+		"RemoveLastOccurrence": func() {
+			e := m.element()
+			removed, err := d.RemoveLastOccurrence(e)
+			assert.NoError(t, err, m.msg())
+			i := lastIndex(ref, e)
+			assert.Equal(t, i >= 0, removed, m.msg())
+			if i >= 0 {
+				ref = removeAt(ref, i)
+			}
+		},
+		"Contains": func() {
+			e := m.element()
+			contains, err := d.Contains(e)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, index(ref, e) >= 0, contains, m.msg())
+		},
+		"IteratorRemove": func() {
+			ref = m.iteratorRemove(d.Iterator(), ref, false)
+		},
+		"DescendingIteratorRemove": func() {
+			ref = m.iteratorRemove(d.DescendingIterator(), ref, true)
+		},
+		"RemoveIf": func() {
+			ref = m.removeIf(d, ref)
+		},
+		"Clear": func() {
+			if m.rand.Intn(10) == 0 {
+				assert.NoError(t, d.Clear(), m.msg())
+				ref = ref[:0]
+			}
+		},
+	}
+	m.run(ops, func() bool {
+		return assert.Equal(t, len(ref), d.Size(), m.msg()) &&
+			assert.Equal(t, sliceOf(ref), sliceOf(d.Slice()), m.msg())
+	})
+}
+
+// TestSetModel 对 collection.Set 的实现进行随机测试
+//
+// 以固定的随机种子执行一系列随机操作,并与基于 map 的参考实现逐步比较结果.
+func TestSetModel(t *testing.T, newSet func() collection.Set) {
+	m := newModel(t)
+	s := newSet()
+	ref := make(map[collection.Element]struct{})
+	ops := map[string]func(){
+		"Add": func() {
+			e := m.element()
+			added, err := s.Add(e)
+			assert.NoError(t, err, m.msg())
+			_, ok := ref[e]
+			assert.Equal(t, !ok, added, m.msg())
+			ref[e] = struct{}{}
+		},
+		"Remove": func() {
+			e := m.element()
+			removed, err := s.Remove(e)
+			assert.NoError(t, err, m.msg())
+			_, ok := ref[e]
+			assert.Equal(t, ok, removed, m.msg())
+			delete(ref, e)
+		},
+		"Contains": func() {
+			e := m.element()
+			contains, err := s.Contains(e)
+			assert.NoError(t, err, m.msg())
+			_, ok := ref[e]
+			assert.Equal(t, ok, contains, m.msg())
+		},
+		"RemoveIf": func() {
+			x := m.element().(int)
+			modified, err := s.RemoveIf(func(e interface{}) bool {
+				return e.(int)%(x+2) == 0
+			})
+			assert.NoError(t, err, m.msg())
+			removed := false
+			for e := range ref {
+				if e.(int)%(x+2) == 0 {
+					delete(ref, e)
+					removed = true
+				}
+			}
+			assert.Equal(t, removed, modified, m.msg())
+		},
+		"IteratorRemove": func() {
+			itr := s.Iterator()
+			for itr.HasNext() {
+				e, err := itr.Next()
+				assert.NoError(t, err, m.msg())
+				if m.rand.Intn(3) == 0 {
+					assert.NoError(t, itr.Remove(), m.msg())
+					delete(ref, e)
+				}
+			}
+		},
+		"Clear": func() {
+			if m.rand.Intn(10) == 0 {
+				assert.NoError(t, s.Clear(), m.msg())
+				ref = make(map[collection.Element]struct{})
+			}
+		},
+	}
+	m.run(ops, func() bool {
+		elements := make([]collection.Element, 0, len(ref))
+		for e := range ref {
+			elements = append(elements, e)
+		}
+		return assert.Equal(t, len(ref), s.Size(), m.msg()) &&
+			assert.ElementsMatch(t, elements, s.Slice(), m.msg())
+	})
+}
+
+// TestMapModel 对 _map.Map 的实现进行随机测试
+//
+// 以固定的随机种子执行一系列随机操作,并与基于 map 的参考实现逐步比较结果.
+// 每个键总是映射到由键唯一确定的值,因此也适用于 _map.BiMap 的实现.
+func TestMapModel(t *testing.T, newMap func() _map.Map) {
+	m := newModel(t)
+	mp := newMap()
+	ref := make(map[interface{}]interface{})
+	// 值的取值与键一一对应,但每次写入的值可能不同
+	value := func(k interface{}) interface{} {
+		return fmt.Sprintf("%v-%d", k, m.rand.Intn(2))
+	}
+	ops := map[string]func(){
+		"Put": func() {
+			k := m.element()
+			v := value(k)
+			old, err := mp.Put(k, v)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k], old, m.msg())
+			ref[k] = v
+		},
+		"Remove": func() {
+			k := m.element()
+			old, err := mp.Remove(k)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k], old, m.msg())
+			delete(ref, k)
+		},
+		"Get": func() {
+			k := m.element()
+			v, err := mp.Get(k)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k], v, m.msg())
+			contains, _ := mp.ContainsKey(k)
+			_, ok := ref[k]
+			assert.Equal(t, ok, contains, m.msg())
+			assert.Equal(t, "default", mp.GetOrDefault(-1, "default"), m.msg())
+		},
+		"ContainsValue": func() {
+			k := m.element()
+			v := value(k)
+			contains, err := mp.ContainsValue(v)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k] == v, contains, m.msg())
+		},
+		"PutIfAbsent": func() {
+			k := m.element()
+			v := value(k)
+			old, err := mp.PutIfAbsent(k, v)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k], old, m.msg())
+			if _, ok := ref[k]; !ok {
+				ref[k] = v
+			}
+		},
+		"Merge": func() {
+			k := m.element()
+			v := value(k)
+			merged, err := mp.Merge(k, v, func(old, v interface{}) interface{} {
+				if old == v {
+					return nil
+				}
+				return v
+			})
+			assert.NoError(t, err, m.msg())
+			if ref[k] == v {
+				assert.Nil(t, merged, m.msg())
+				delete(ref, k)
+			} else {
+				assert.Equal(t, v, merged, m.msg())
+				ref[k] = v
+			}
+		},
+		"RemoveIfEquals": func() {
+			k := m.element()
+			v := value(k)
+			removed, err := mp.RemoveIfEquals(k, v)
+			assert.NoError(t, err, m.msg())
+			assert.Equal(t, ref[k] == v, removed, m.msg())
+			if removed {
+				delete(ref, k)
+			}
+		},
+		"Clear": func() {
+			if m.rand.Intn(10) == 0 {
+				assert.NoError(t, mp.Clear(), m.msg())
+				ref = make(map[interface{}]interface{})
+			}
+		},
+	}
+	m.run(ops, func() bool {
+		keys := make([]collection.Element, 0, len(ref))
+		values := make([]collection.Element, 0, len(ref))
+		for k, v := range ref {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		return assert.Equal(t, len(ref), mp.Size(), m.msg()) &&
+			assert.ElementsMatch(t, keys, mp.KeySet().Slice(), m.msg()) &&
+			assert.ElementsMatch(t, values, mp.Values().Slice(), m.msg())
+	})
+}
+
+// sliceOf 返回非 nil 的切片,使 nil 切片与空切片可以直接比较
+func sliceOf(s []collection.Element) []collection.Element {
+	if s == nil {
+		return []collection.Element{}
+	}
+	return s
+}
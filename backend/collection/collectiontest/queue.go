@@ -0,0 +1,195 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package collectiontest
+
+import (
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQueue 测试 collection.Queue 接口约定的先进先出行为
+//
+// 除 TestCollection 的全部测试外,还验证队列头部操作在队列为空时的返回值.
+func TestQueue(t *testing.T, newQueue func() collection.Queue) {
+	TestCollection(t, func() collection.Collection {
+		return newQueue()
+	})
+
+	t.Run("EmptyHead", func(t *testing.T) {
+		q := newQueue()
+		assert.Nil(t, q.Peek())
+		assert.Nil(t, q.Poll())
+		_, err := q.Element()
+		assertError(t, errs.NoSuchElement, err)
+		_, err = q.Delete()
+		assertError(t, errs.NoSuchElement, err)
+	})
+
+	t.Run("FIFO", func(t *testing.T) {
+		q := newQueue()
+		for i := 1; i <= 3; i++ {
+			offered, err := q.Offer(i)
+			assert.NoError(t, err)
+			assert.True(t, offered)
+		}
+		assert.Equal(t, 1, q.Peek())
+		e, err := q.Element()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, e)
+		assert.Equal(t, 3, q.Size())
+
+		assert.Equal(t, 1, q.Poll())
+		e, err = q.Delete()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, e)
+		assert.Equal(t, 1, q.Size())
+		_, _ = q.Offer(4)
+		assert.Equal(t, 3, q.Poll())
+		assert.Equal(t, 4, q.Poll())
+		assert.Nil(t, q.Poll())
+		assert.True(t, q.IsEmpty())
+	})
+}
+
+// TestDeQueue 测试 collection.DeQueue 接口约定的行为
+//
+// 除 TestQueue 的全部测试外,还验证双端操作、栈操作以及逆序迭代器的行为.
+func TestDeQueue(t *testing.T, newDeQueue func() collection.DeQueue) {
+	TestQueue(t, func() collection.Queue {
+		return newDeQueue()
+	})
+
+	t.Run("EmptyEnds", func(t *testing.T) {
+		d := newDeQueue()
+		_, err := d.GetFirst()
+		assertError(t, errs.NoSuchElement, err)
+		_, err = d.GetLast()
+		assertError(t, errs.NoSuchElement, err)
+		_, err = d.RemoveFirst()
+		assertError(t, errs.NoSuchElement, err)
+		_, err = d.RemoveLast()
+		assertError(t, errs.NoSuchElement, err)
+		_, err = d.Pop()
+		assertError(t, errs.NoSuchElement, err)
+		itr := d.DescendingIterator()
+		assert.False(t, itr.HasNext())
+		_, err = itr.Next()
+		assertError(t, errs.NoSuchElement, err)
+	})
+
+	t.Run("Ends", func(t *testing.T) {
+		d := newDeQueue()
+		assert.NoError(t, d.AddLast(2))
+		assert.NoError(t, d.AddFirst(1))
+		assert.NoError(t, d.AddLast(3))
+		assert.Equal(t, []collection.Element{1, 2, 3}, d.Slice())
+		first, err := d.GetFirst()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, first)
+		last, err := d.GetLast()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, last)
+
+		e, err := d.RemoveLast()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, e)
+		e, err = d.RemoveFirst()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, e)
+		assert.Equal(t, 1, d.Size())
+		assert.Equal(t, []collection.Element{2}, d.Slice())
+	})
+
+	t.Run("Stack", func(t *testing.T) {
+		d := newDeQueue()
+		for i := 1; i <= 3; i++ {
+			assert.NoError(t, d.Push(i))
+		}
+		assert.Equal(t, 3, d.Peek())
+		for i := 3; i >= 1; i-- {
+			e, err := d.Pop()
+			assert.NoError(t, err)
+			assert.Equal(t, i, e)
+		}
+		assert.True(t, d.IsEmpty())
+	})
+
+	t.Run("Occurrence", func(t *testing.T) {
+		d := newDeQueue()
+		for _, e := range []int{1, 2, 3, 2, 1} {
+			_ = d.AddLast(e)
+		}
+		removed, err := d.RemoveFirstOccurrence(2)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+		assert.Equal(t, []collection.Element{1, 3, 2, 1}, d.Slice())
+		removed, err = d.RemoveLastOccurrence(1)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+		assert.Equal(t, []collection.Element{1, 3, 2}, d.Slice())
+		removed, _ = d.RemoveFirstOccurrence(99)
+		assert.False(t, removed)
+		removed, _ = d.RemoveLastOccurrence(99)
+		assert.False(t, removed)
+		assert.Equal(t, 3, d.Size())
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		d := newDeQueue()
+		want := make([]collection.Element, 0, 100)
+		for i := 0; i < 50; i++ {
+			_ = d.AddFirst(-i)
+			_ = d.AddLast(i + 100)
+		}
+		for i := 49; i >= 0; i-- {
+			want = append(want, -i)
+		}
+		for i := 0; i < 50; i++ {
+			want = append(want, i+100)
+		}
+		assert.Equal(t, 100, d.Size())
+		assert.Equal(t, want, d.Slice())
+		assert.Equal(t, want, iterate(t, d))
+	})
+
+	t.Run("DescendingIterator", func(t *testing.T) {
+		d := newDeQueue()
+		for i := 1; i <= 5; i++ {
+			_ = d.AddLast(i)
+		}
+		var got []collection.Element
+		itr := d.DescendingIterator()
+		assertError(t, errs.IllegalState, itr.Remove())
+		for itr.HasNext() {
+			e, err := itr.Next()
+			assert.NoError(t, err)
+			got = append(got, e)
+			if e.(int)%2 == 0 {
+				assert.NoError(t, itr.Remove())
+				assertError(t, errs.IllegalState, itr.Remove())
+			}
+		}
+		assert.Equal(t, []collection.Element{5, 4, 3, 2, 1}, got)
+		_, err := itr.Next()
+		assertError(t, errs.NoSuchElement, err)
+		assert.Equal(t, []collection.Element{1, 3, 5}, d.Slice())
+	})
+}
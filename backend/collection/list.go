@@ -17,6 +17,8 @@
 
 package collection
 
+import "github.com/chenquan/go-util/function"
+
 // List 有序集合,也称为序列
 //
 // 该界面的用户可以精确控制列表中每个元素的插入位置.
@@ -46,6 +48,12 @@ type List interface {
 	Index(e Element) int
 	// LastIndex 返回此列表中指定元素的最后一次出现的索引,如果此列表不包含该元素，则返回-1
 	LastIndex(e Element) int
+	// ReplaceAll 使用 operator 作用于每个元素的结果替换该元素
+	ReplaceAll(operator function.UnaryOperator)
+	// Sort 按 c 对列表进行稳定排序
+	//
+	// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+	Sort(c function.Comparator)
 }
 
 type IteratorList interface {
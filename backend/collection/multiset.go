@@ -0,0 +1,39 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package collection
+
+// Multiset 多重集,也称为包(bag)
+//
+// 与 Set 不同,多重集允许包含重复的元素,并记录每个元素出现的次数.
+// Collection 中的方法均按元素的每次出现处理,
+// 例如 Add 使元素的次数加一, Remove 使元素的次数减一.
+type Multiset interface {
+	Collection
+	// Count 返回指定元素在当前多重集中出现的次数
+	Count(e Element) int
+	// AddCount 将指定元素的次数增加 n,并返回操作前的次数
+	AddCount(e Element, n int) (int, error)
+	// RemoveCount 将指定元素的次数减少 n(最少减为0),并返回操作前的次数
+	RemoveCount(e Element, n int) (int, error)
+	// SetCount 将指定元素的次数设置为 n,并返回操作前的次数
+	SetCount(e Element, n int) (int, error)
+	// ElementSet 返回当前多重集中不重复元素的集合
+	//
+	// 返回的集合是当前多重集的快照,修改它不会影响当前多重集.
+	ElementSet() Set
+}
@@ -0,0 +1,33 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package _map
+
+// BiMap 双向映射
+//
+// 在 Map 的基础上要求值也是唯一的,从而可以通过 Inverse 由值查找键.
+type BiMap interface {
+	Map
+	// ForcePut 添加键值对
+	//
+	// 与 Put 不同,如果值 v 已经映射到其他键,则先删除该键值对再添加.
+	ForcePut(k Key, v Value) (Value, error)
+	// Inverse 返回当前双向映射的逆视图,即值到键的映射
+	//
+	// 逆视图与当前双向映射共享数据,对任意一方的修改都会反映到另一方.
+	Inverse() BiMap
+}
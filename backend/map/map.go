@@ -22,18 +22,27 @@ import (
 	"github.com/chenquan/go-util/function"
 )
 
-type key interface{}
+// Key 映射的键
+type Key interface{}
+
+// Value 映射的值
 type Value interface{}
 
-// Map
+// Map 将键映射到值的对象
+//
+// 映射不能包含重复的键,每个键最多只能映射到一个值.
+//
+// 键可以映射到 nil.Get、ContainsKey 与 GetOrDefault 只关心键是否存在;
+// PutIfAbsent、ComputeIfAbsent、ComputeIfPresent、Compute 与 Merge 则将映射到 nil 的键视为不存在,
+// 与这些方法中计算结果为 nil 时删除映射的行为保持一致.
 type Map interface {
 	Size() int
 	IsEmpty() bool
-	ContainsKey(k key) (bool, error)
+	ContainsKey(k Key) (bool, error)
 	ContainsValue(v Value) (bool, error)
-	Get(k key) (Value, error)
-	Put(k key, v Value) (Value, error)
-	Remove(k key) (Value, error)
+	Get(k Key) (Value, error)
+	Put(k Key, v Value) (Value, error)
+	Remove(k Key) (Value, error)
 	PutAll(m Map) error
 	Clear() error
 	KeySet() collection.Set
@@ -41,11 +50,36 @@ type Map interface {
 	EntrySet() collection.Set
 	Equals(o interface{}) bool
 	HashCode() int
-	GetOrDefault(k key, defaultValue Value)
+	GetOrDefault(k Key, defaultValue Value) Value
+	// ForEach 对每个键值对执行 action,遍历顺序由实现决定
+	ForEach(action function.BiConsumer)
+	// PutIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到值 v 并返回 nil,否则返回键 k 当前映射的值
+	PutIfAbsent(k Key, v Value) (Value, error)
+	// ComputeIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到 mapping(k) 的结果,并返回键 k 当前映射的值
+	//
+	// mapping 返回 nil 时不添加映射.
+	ComputeIfAbsent(k Key, mapping function.Function) (Value, error)
+	// ComputeIfPresent 如果键 k 映射到非 nil 的值则将其映射到 remapping(k, 旧值) 的结果,并返回新值
+	//
+	// remapping 返回 nil 时删除键 k 的映射.
+	ComputeIfPresent(k Key, remapping function.BiFunction) (Value, error)
+	// Compute 将键 k 映射到 remapping(k, 旧值) 的结果,并返回新值,键 k 不存在时旧值为 nil
+	//
+	// remapping 返回 nil 时删除键 k 的映射.
+	Compute(k Key, remapping function.BiFunction) (Value, error)
+	// Merge 如果键 k 不存在或映射到 nil 则将其映射到值 v,否则映射到 remapping(旧值, v) 的结果,并返回新值
+	//
+	// remapping 返回 nil 时删除键 k 的映射.
+	Merge(k Key, v Value, remapping function.BiFunction) (Value, error)
+	// ReplaceAll 将每个键映射的值替换为 f(键, 值) 的结果
+	ReplaceAll(f function.BiFunction) error
+	// RemoveIfEquals 仅当键 k 映射到值 v 时删除该映射,删除成功则返回 true
+	RemoveIfEquals(k Key, v Value) (bool, error)
 }
 
+// Entry 映射中的键值对
 type Entry interface {
-	Key() (key, error)
+	Key() (Key, error)
 	Value() (Value, error)
 	SetValue(value Value) (Value, error)
 	Equals(o interface{}) bool
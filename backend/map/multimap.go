@@ -0,0 +1,88 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package _map
+
+import "github.com/chenquan/go-util/backend/collection"
+
+// Multimap 多值映射,一个键可以映射到多个值
+//
+// 可以将其看作 Map[Key]collection.Collection,
+// 但键对应的值集合为空时,该键不会出现在映射中.
+type Multimap interface {
+	// Size 返回所有键值对的个数
+	Size() int
+	// IsEmpty 如果不存在任何键值对则返回 true,否则返回 false
+	IsEmpty() bool
+	// ContainsKey 如果存在至少一个键为 k 的键值对则返回 true,否则返回 false
+	ContainsKey(k Key) (bool, error)
+	// ContainsValue 如果存在至少一个值为 v 的键值对则返回 true,否则返回 false
+	ContainsValue(v Value) (bool, error)
+	// ContainsEntry 如果存在键为 k 且值为 v 的键值对则返回 true,否则返回 false
+	ContainsEntry(k Key, v Value) (bool, error)
+	// Put 添加键值对
+	//
+	// 如果当前多值映射由于调用而更改,则返回 true.
+	Put(k Key, v Value) (bool, error)
+	// PutAll 将指定集合中的所有值添加到键 k 下
+	PutAll(k Key, c collection.Collection) (bool, error)
+	// Remove 删除一个键为 k 且值为 v 的键值对
+	Remove(k Key, v Value) (bool, error)
+	// RemoveAll 删除键 k 的所有值,并返回被删除的值
+	RemoveAll(k Key) (collection.Collection, error)
+	// ReplaceValues 用指定集合替换键 k 的所有值,并返回被替换的值
+	ReplaceValues(k Key, c collection.Collection) (collection.Collection, error)
+	// Clear 清空所有键值对
+	Clear() error
+	// Get 返回键 k 对应的值集合
+	//
+	// 返回的集合是实时视图,对其的修改会反映到当前多值映射中,反之亦然.
+	// 即使键 k 当前不存在也不会返回 nil.
+	Get(k Key) collection.Collection
+	// KeySet 返回所有不重复键的集合
+	KeySet() collection.Set
+	// Keys 返回所有键的多重集,每个键的次数等于其值的个数
+	Keys() collection.Multiset
+	// Values 返回所有值的集合
+	Values() collection.Collection
+	// AsMap 返回键到值集合的映射
+	//
+	// 映射中的值集合与 Get 返回的值集合相同,均为实时视图.
+	AsMap() Map
+}
+
+// ListMultimap 值以列表形式保存的多值映射
+//
+// 同一个键下允许重复的值,并保持值的插入顺序.
+type ListMultimap interface {
+	Multimap
+	// GetList 返回键 k 对应的值列表
+	//
+	// 返回的列表是实时视图,与 Get 返回的值集合相同.
+	GetList(k Key) collection.List
+}
+
+// SetMultimap 值以集的形式保存的多值映射
+//
+// 同一个键下不允许重复的值.
+type SetMultimap interface {
+	Multimap
+	// GetSet 返回键 k 对应的值集
+	//
+	// 返回的集是实时视图,与 Get 返回的值集合相同.
+	GetSet(k Key) collection.Set
+}
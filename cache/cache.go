@@ -0,0 +1,415 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package cache 进程内缓存
+//
+// 提供 LRU、LFU 与 ARC 三种淘汰策略,支持条目的存活时间(TTL)与最大空闲时间过期,
+// 支持加载函数并合并同一个键的并发加载,支持删除监听器与命中率等统计信息.
+// Cache 协程安全.
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+)
+
+var (
+	NotFoundErr      = fmt.Errorf("key not found: %w", errs.NoSuchElement)
+	LoaderPanicErr   = fmt.Errorf("loader panicked: %w", errs.IllegalState)
+	UnknownPolicyErr = fmt.Errorf("unknown eviction policy: %w", errs.IllegalArgument)
+)
+
+// LoaderFunc 缓存未命中时加载键对应值的函数
+type LoaderFunc func(key interface{}) (interface{}, error)
+
+// RemovalCause 条目被删除的原因
+type RemovalCause int
+
+const (
+	// Explicit 被显式删除
+	Explicit RemovalCause = iota
+	// Replaced 值被替换
+	Replaced
+	// Size 因容量不足被淘汰
+	Size
+	// Expired 已过期
+	Expired
+)
+
+// String 返回删除原因的名称
+func (c RemovalCause) String() string {
+	switch c {
+	case Explicit:
+		return "explicit"
+	case Replaced:
+		return "replaced"
+	case Size:
+		return "size"
+	case Expired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// RemovalListener 条目被删除时的监听器
+//
+// 监听器在释放缓存的锁之后调用,可以安全地访问缓存.
+type RemovalListener func(key, value interface{}, cause RemovalCause)
+
+// Config 缓存的配置
+type Config struct {
+	// MaxSize 最大条目数,小于等于0表示不限制
+	MaxSize int
+	// Policy 容量不足时的淘汰策略,默认为 LRU
+	Policy Policy
+	// TTL 条目的默认存活时间,0表示不过期
+	TTL time.Duration
+	// MaxIdle 条目的最大空闲时间,超过该时间未被访问的条目过期,0表示不限制
+	MaxIdle time.Duration
+	// Loader 缓存未命中时的加载函数,为 nil 时 Get 未命中返回 NotFoundErr
+	Loader LoaderFunc
+	// Clock 时钟,默认为 xtime.SystemClock
+	Clock xtime.Clock
+}
+
+// Stats 缓存的统计信息
+type Stats struct {
+	Hits          uint64 // 命中次数
+	Misses        uint64 // 未命中次数
+	LoadSuccesses uint64 // 加载成功次数
+	LoadFailures  uint64 // 加载失败次数
+	Evictions     uint64 // 因容量不足被淘汰的条目数
+	Expirations   uint64 // 过期被删除的条目数
+}
+
+// Requests 返回请求总数
+func (s Stats) Requests() uint64 {
+	return s.Hits + s.Misses
+}
+
+// HitRate 返回命中率,没有请求时返回0
+func (s Stats) HitRate() float64 {
+	requests := s.Requests()
+	if requests == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(requests)
+}
+
+// entry 缓存条目
+type entry struct {
+	key      interface{}
+	value    interface{}
+	expireAt time.Time // 过期时间,零值表示不过期
+	accessAt time.Time // 最后一次访问的时间
+
+	element   *list.Element // 所在淘汰策略链表中的节点
+	frequency *list.Element // LFU 中所在的频率节点
+	hot       bool          // ARC 中是否位于 T2
+}
+
+// notification 待通知监听器的删除事件
+type notification struct {
+	key   interface{}
+	value interface{}
+	cause RemovalCause
+}
+
+// call 正在进行的加载
+type call struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// Cache 进程内缓存
+type Cache struct {
+	mu        sync.Mutex
+	maxSize   int
+	ttl       time.Duration
+	maxIdle   time.Duration
+	loader    LoaderFunc
+	clock     xtime.Clock
+	entries   map[interface{}]*entry
+	policy    policy
+	calls     map[interface{}]*call // 正在加载的键
+	listeners []RemovalListener
+	stats     Stats
+}
+
+// New 按配置创建缓存
+//
+// 如果配置的淘汰策略未知则引发 panic.
+func New(config Config) *Cache {
+	clock := config.Clock
+	if clock == nil {
+		clock = xtime.SystemClock
+	}
+	p, err := newPolicy(config.Policy, config.MaxSize)
+	if err != nil {
+		panic(err)
+	}
+	return &Cache{
+		maxSize: config.MaxSize,
+		ttl:     config.TTL,
+		maxIdle: config.MaxIdle,
+		loader:  config.Loader,
+		clock:   clock,
+		entries: make(map[interface{}]*entry),
+		policy:  p,
+		calls:   make(map[interface{}]*call),
+	}
+}
+
+// NewDefault 创建不限制容量且条目不过期的缓存
+func NewDefault() *Cache {
+	return New(Config{})
+}
+
+// AddRemovalListener 添加条目被删除时的监听器
+func (c *Cache) AddRemovalListener(listener RemovalListener) {
+	if listener == nil {
+		return
+	}
+	c.mu.Lock()
+	c.listeners = append(c.listeners, listener)
+	c.mu.Unlock()
+}
+
+// Get 返回键 key 对应的值
+//
+// 未命中时如果配置了加载函数则调用加载函数并缓存其结果,
+// 同一个键的并发加载只调用一次加载函数,其余调用等待并共享其结果.
+// 未配置加载函数时返回 NotFoundErr,加载函数引发 panic 时等待的调用返回 LoaderPanicErr.
+func (c *Cache) Get(key interface{}) (interface{}, error) {
+	c.mu.Lock()
+	value, ok, ns := c.get(key)
+	if ok || c.loader == nil {
+		c.mu.Unlock()
+		c.notify(ns)
+		if !ok {
+			return nil, NotFoundErr
+		}
+		return value, nil
+	}
+	if cl, loading := c.calls[key]; loading {
+		c.mu.Unlock()
+		c.notify(ns)
+		cl.wg.Wait()
+		return cl.value, cl.err
+	}
+	cl := &call{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.mu.Unlock()
+	c.notify(ns)
+
+	c.load(key, cl)
+	return cl.value, cl.err
+}
+
+// load 调用加载函数并缓存加载成功的结果
+func (c *Cache) load(key interface{}, cl *call) {
+	panicked := true
+	defer func() {
+		if panicked {
+			cl.value, cl.err = nil, LoaderPanicErr
+		}
+		c.mu.Lock()
+		delete(c.calls, key)
+		var ns []notification
+		if cl.err == nil {
+			c.stats.LoadSuccesses++
+			ns = c.put(key, cl.value, c.ttl)
+		} else {
+			c.stats.LoadFailures++
+		}
+		c.mu.Unlock()
+		cl.wg.Done()
+		c.notify(ns)
+	}()
+	cl.value, cl.err = c.loader(key)
+	panicked = false
+}
+
+// GetIfPresent 返回键 key 对应的值,不会调用加载函数
+//
+// 如果键存在且未过期则第二个返回值为 true,否则为 false.
+func (c *Cache) GetIfPresent(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	value, ok, ns := c.get(key)
+	c.mu.Unlock()
+	c.notify(ns)
+	return value, ok
+}
+
+// get 查找键 key 对应的值并更新统计信息,调用方必须持有锁
+func (c *Cache) get(key interface{}) (interface{}, bool, []notification) {
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false, nil
+	}
+	now := c.clock.Now()
+	if c.expired(e, now) {
+		c.stats.Misses++
+		return nil, false, []notification{c.remove(e, Expired)}
+	}
+	c.stats.Hits++
+	e.accessAt = now
+	c.policy.record(e)
+	return e.value, true, nil
+}
+
+// Put 缓存键值对,使用默认的存活时间
+func (c *Cache) Put(key, value interface{}) {
+	c.PutWithTTL(key, value, c.ttl)
+}
+
+// PutWithTTL 缓存键值对,存活时间为 ttl,ttl 小于等于0表示不过期
+func (c *Cache) PutWithTTL(key, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	ns := c.put(key, value, ttl)
+	c.mu.Unlock()
+	c.notify(ns)
+}
+
+// put 缓存键值对,容量不足时淘汰条目,调用方必须持有锁
+func (c *Cache) put(key, value interface{}, ttl time.Duration) []notification {
+	var ns []notification
+	now := c.clock.Now()
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = now.Add(ttl)
+	}
+	if e, ok := c.entries[key]; ok {
+		if !c.expired(e, now) {
+			ns = append(ns, notification{key: key, value: e.value, cause: Replaced})
+			e.value, e.expireAt, e.accessAt = value, expireAt, now
+			c.policy.record(e)
+			return ns
+		}
+		ns = append(ns, c.remove(e, Expired))
+	}
+	e := &entry{key: key, value: value, expireAt: expireAt, accessAt: now}
+	c.entries[key] = e
+	c.policy.add(e)
+	for c.maxSize > 0 && len(c.entries) > c.maxSize {
+		victim := c.policy.evict()
+		delete(c.entries, victim.key)
+		c.stats.Evictions++
+		ns = append(ns, notification{key: victim.key, value: victim.value, cause: Size})
+	}
+	return ns
+}
+
+// Invalidate 删除键 key 对应的条目
+//
+// 如果键存在则返回 true,否则返回 false.
+func (c *Cache) Invalidate(key interface{}) bool {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	var ns []notification
+	if ok {
+		ns = append(ns, c.remove(e, Explicit))
+	}
+	c.mu.Unlock()
+	c.notify(ns)
+	return ok
+}
+
+// InvalidateAll 删除所有条目
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	ns := make([]notification, 0, len(c.entries))
+	for _, e := range c.entries {
+		ns = append(ns, notification{key: e.key, value: e.value, cause: Explicit})
+	}
+	c.entries = make(map[interface{}]*entry)
+	c.policy.clear()
+	c.mu.Unlock()
+	c.notify(ns)
+}
+
+// CleanUp 删除所有已过期的条目,并返回删除的条目数
+//
+// 过期的条目在被访问时才会删除,需要及时释放内存时可定期调用该方法.
+func (c *Cache) CleanUp() int {
+	c.mu.Lock()
+	now := c.clock.Now()
+	var ns []notification
+	for _, e := range c.entries {
+		if c.expired(e, now) {
+			ns = append(ns, c.remove(e, Expired))
+		}
+	}
+	c.mu.Unlock()
+	c.notify(ns)
+	return len(ns)
+}
+
+// Len 返回条目数,可能包含已过期但尚未删除的条目
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Stats 返回统计信息的快照
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// expired 如果条目在 now 时已过期则返回 true,否则返回 false
+func (c *Cache) expired(e *entry, now time.Time) bool {
+	if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+		return true
+	}
+	return c.maxIdle > 0 && now.Sub(e.accessAt) >= c.maxIdle
+}
+
+// remove 删除条目并返回删除事件,调用方必须持有锁
+func (c *Cache) remove(e *entry, cause RemovalCause) notification {
+	delete(c.entries, e.key)
+	c.policy.remove(e)
+	if cause == Expired {
+		c.stats.Expirations++
+	}
+	return notification{key: e.key, value: e.value, cause: cause}
+}
+
+// notify 通知监听器删除事件,调用方不能持有锁
+func (c *Cache) notify(ns []notification) {
+	if len(ns) == 0 {
+		return
+	}
+	c.mu.Lock()
+	listeners := c.listeners
+	c.mu.Unlock()
+	for _, n := range ns {
+		for _, listener := range listeners {
+			listener(n.key, n.value, n.cause)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+type removal struct {
+	key   interface{}
+	value interface{}
+	cause RemovalCause
+}
+
+func recordRemovals(c *Cache) *[]removal {
+	var removals []removal
+	c.AddRemovalListener(func(key, value interface{}, cause RemovalCause) {
+		removals = append(removals, removal{key, value, cause})
+	})
+	return &removals
+}
+
+func TestCache(t *testing.T) {
+	c := NewDefault()
+	removals := recordRemovals(c)
+	_, err := c.Get("a")
+	assert.Equal(t, NotFoundErr, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	v, err := c.Get("a")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+	c.Put("a", 3)
+	v, ok := c.GetIfPresent("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, 2, c.Len())
+
+	assert.True(t, c.Invalidate("b"))
+	assert.False(t, c.Invalidate("b"))
+	c.InvalidateAll()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, []removal{{"a", 1, Replaced}, {"b", 2, Explicit}, {"a", 3, Explicit}}, *removals)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.InDelta(t, 2.0/3, stats.HitRate(), 1e-9)
+	assert.Equal(t, 0.0, Stats{}.HitRate())
+}
+
+func TestCache_MaxSize(t *testing.T) {
+	c := New(Config{MaxSize: 2})
+	removals := recordRemovals(c)
+	c.Put(1, "a")
+	c.Put(2, "b")
+	_, _ = c.Get(1)
+	c.Put(3, "c")
+	_, ok := c.GetIfPresent(2)
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, []removal{{2, "b", Size}}, *removals)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+
+	assert.Panics(t, func() { New(Config{Policy: Policy(42)}) })
+}
+
+func TestCache_TTL(t *testing.T) {
+	clock := xtime.NewManualClock(time.Unix(0, 0))
+	c := New(Config{TTL: time.Minute, Clock: clock})
+	removals := recordRemovals(c)
+	c.Put("a", 1)
+	c.PutWithTTL("b", 2, 3*time.Minute)
+	c.PutWithTTL("c", 3, 0)
+
+	clock.Advance(time.Minute - time.Nanosecond)
+	_, ok := c.GetIfPresent("a")
+	assert.True(t, ok)
+	clock.Advance(time.Nanosecond)
+	_, ok = c.GetIfPresent("a")
+	assert.False(t, ok)
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 1, c.CleanUp())
+	_, ok = c.GetIfPresent("c")
+	assert.True(t, ok)
+	assert.Equal(t, []removal{{"a", 1, Expired}, {"b", 2, Expired}}, *removals)
+	assert.Equal(t, uint64(2), c.Stats().Expirations)
+}
+
+func TestCache_MaxIdle(t *testing.T) {
+	clock := xtime.NewManualClock(time.Unix(0, 0))
+	c := New(Config{MaxIdle: time.Minute, Clock: clock})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	for i := 0; i < 3; i++ {
+		clock.Advance(30 * time.Second)
+		_, ok := c.GetIfPresent("a")
+		assert.True(t, ok)
+	}
+	_, ok := c.GetIfPresent("b")
+	assert.False(t, ok)
+
+	// 替换过期的条目视为新加入
+	removals := recordRemovals(c)
+	clock.Advance(time.Minute)
+	c.Put("a", 3)
+	assert.Equal(t, []removal{{"a", 1, Expired}}, *removals)
+}
+
+func TestCache_Loader(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	loadErr := errors.New("load failed")
+	c := New(Config{Loader: func(key interface{}) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		if key == "bad" {
+			return nil, loadErr
+		}
+		return key.(string) + "!", nil
+	}})
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Get("a")
+		}(i)
+	}
+	// 等待第一个调用开始加载
+	for atomic.LoadInt32(&loads) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+	for _, r := range results {
+		assert.Equal(t, "a!", r)
+	}
+
+	_, err := c.Get("bad")
+	assert.Equal(t, loadErr, err)
+	_, ok := c.GetIfPresent("bad")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.LoadSuccesses)
+	assert.Equal(t, uint64(1), stats.LoadFailures)
+}
+
+func TestCache_LoaderPanic(t *testing.T) {
+	c := New(Config{Loader: func(key interface{}) (interface{}, error) {
+		panic("boom")
+	}})
+	assert.Panics(t, func() { _, _ = c.Get("a") })
+	// 加载状态已清理,再次加载仍会调用加载函数
+	assert.Panics(t, func() { _, _ = c.Get("a") })
+	assert.Equal(t, uint64(2), c.Stats().LoadFailures)
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	for _, p := range []Policy{LRU, LFU, ARC} {
+		c := New(Config{MaxSize: 64, Policy: p, Loader: func(key interface{}) (interface{}, error) {
+			return key, nil
+		}})
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					key := (g*31 + i*7) % 200
+					v, err := c.Get(key)
+					assert.Nil(t, err)
+					assert.Equal(t, key, v)
+					if i%10 == 0 {
+						c.Invalidate(key)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, c.Len(), 64, p.String())
+	}
+}
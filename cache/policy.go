@@ -0,0 +1,336 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package cache
+
+import "container/list"
+
+// Policy 淘汰策略
+type Policy int
+
+const (
+	// LRU 淘汰最近最少使用的条目
+	LRU Policy = iota
+	// LFU 淘汰访问次数最少的条目,次数相同时淘汰最近最少使用的条目
+	LFU
+	// ARC 自适应替换缓存,根据访问模式在最近使用与经常使用之间自动调整
+	ARC
+)
+
+// String 返回淘汰策略的名称
+func (p Policy) String() string {
+	switch p {
+	case LRU:
+		return "LRU"
+	case LFU:
+		return "LFU"
+	case ARC:
+		return "ARC"
+	}
+	return "unknown"
+}
+
+// policy 淘汰策略的实现
+//
+// 所有方法均由持有缓存锁的调用方调用.
+type policy interface {
+	// add 记录新加入的条目
+	add(e *entry)
+	// record 记录对条目的访问
+	record(e *entry)
+	// remove 删除条目
+	remove(e *entry)
+	// evict 选择并删除一个被淘汰的条目,不存在条目时返回 nil
+	evict() *entry
+	// clear 删除所有条目
+	clear()
+}
+
+// newPolicy 创建淘汰策略
+//
+// 不限制容量时不会淘汰条目,ARC 退化为 LRU.
+func newPolicy(p Policy, capacity int) (policy, error) {
+	switch p {
+	case LRU:
+		return newLRUPolicy(), nil
+	case LFU:
+		return newLFUPolicy(), nil
+	case ARC:
+		if capacity <= 0 {
+			return newLRUPolicy(), nil
+		}
+		return newARCPolicy(capacity), nil
+	}
+	return nil, UnknownPolicyErr
+}
+
+// lruPolicy 最近最少使用淘汰策略
+type lruPolicy struct {
+	entries *list.List // 从最近使用到最久未使用排列
+}
+
+// newLRUPolicy 创建最近最少使用淘汰策略
+func newLRUPolicy() *lruPolicy {
+	return &lruPolicy{entries: list.New()}
+}
+
+// add 记录新加入的条目
+func (p *lruPolicy) add(e *entry) {
+	e.element = p.entries.PushFront(e)
+}
+
+// record 记录对条目的访问
+func (p *lruPolicy) record(e *entry) {
+	p.entries.MoveToFront(e.element)
+}
+
+// remove 删除条目
+func (p *lruPolicy) remove(e *entry) {
+	p.entries.Remove(e.element)
+}
+
+// evict 选择并删除一个被淘汰的条目
+func (p *lruPolicy) evict() *entry {
+	back := p.entries.Back()
+	if back == nil {
+		return nil
+	}
+	return p.entries.Remove(back).(*entry)
+}
+
+// clear 删除所有条目
+func (p *lruPolicy) clear() {
+	p.entries.Init()
+}
+
+// frequencyNode 访问次数相同的条目
+type frequencyNode struct {
+	count   int
+	entries *list.List // 从最近使用到最久未使用排列
+}
+
+// lfuPolicy 最不经常使用淘汰策略
+//
+// 按访问次数升序维护频率节点链表,各操作的时间复杂度均为 O(1).
+type lfuPolicy struct {
+	frequencies *list.List // 按访问次数升序排列的 *frequencyNode
+}
+
+// newLFUPolicy 创建最不经常使用淘汰策略
+func newLFUPolicy() *lfuPolicy {
+	return &lfuPolicy{frequencies: list.New()}
+}
+
+// add 记录新加入的条目
+func (p *lfuPolicy) add(e *entry) {
+	front := p.frequencies.Front()
+	if front == nil || front.Value.(*frequencyNode).count != 1 {
+		front = p.frequencies.PushFront(&frequencyNode{count: 1, entries: list.New()})
+	}
+	e.frequency = front
+	e.element = front.Value.(*frequencyNode).entries.PushFront(e)
+}
+
+// record 记录对条目的访问
+func (p *lfuPolicy) record(e *entry) {
+	current := e.frequency
+	node := current.Value.(*frequencyNode)
+	next := current.Next()
+	if next == nil || next.Value.(*frequencyNode).count != node.count+1 {
+		next = p.frequencies.InsertAfter(&frequencyNode{count: node.count + 1, entries: list.New()}, current)
+	}
+	p.remove(e)
+	e.frequency = next
+	e.element = next.Value.(*frequencyNode).entries.PushFront(e)
+}
+
+// remove 删除条目
+func (p *lfuPolicy) remove(e *entry) {
+	node := e.frequency.Value.(*frequencyNode)
+	node.entries.Remove(e.element)
+	if node.entries.Len() == 0 {
+		p.frequencies.Remove(e.frequency)
+	}
+}
+
+// evict 选择并删除一个被淘汰的条目
+func (p *lfuPolicy) evict() *entry {
+	front := p.frequencies.Front()
+	if front == nil {
+		return nil
+	}
+	e := front.Value.(*frequencyNode).entries.Back().Value.(*entry)
+	p.remove(e)
+	return e
+}
+
+// clear 删除所有条目
+func (p *lfuPolicy) clear() {
+	p.frequencies.Init()
+}
+
+// ghost ARC 中只保留键的已淘汰条目
+type ghost struct {
+	key interface{}
+	hot bool // 是否位于 B2
+}
+
+// arcPolicy 自适应替换缓存淘汰策略
+//
+// T1 保存只访问过一次的条目,T2 保存访问过多次的条目,
+// B1 与 B2 分别保存从 T1 与 T2 淘汰的键.
+// 新加入的键命中 B1 时增大 T1 的目标大小 p,命中 B2 时减小 p.
+type arcPolicy struct {
+	capacity int
+	p        int                           // T1 的目标大小
+	t1, t2   *list.List                    // *entry,从最近使用到最久未使用排列
+	b1, b2   *list.List                    // *ghost,从最近淘汰到最久淘汰排列
+	ghosts   map[interface{}]*list.Element // 键在 B1 或 B2 中的节点
+
+	incoming *entry // 最近加入的条目,淘汰时不计入 T1
+	fromB2   bool   // 最近加入的键是否命中 B2
+}
+
+// newARCPolicy 创建容量为 capacity 的自适应替换缓存淘汰策略
+func newARCPolicy(capacity int) *arcPolicy {
+	return &arcPolicy{
+		capacity: capacity,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		ghosts:   make(map[interface{}]*list.Element),
+	}
+}
+
+// add 记录新加入的条目
+func (p *arcPolicy) add(e *entry) {
+	p.incoming, p.fromB2 = e, false
+	g, ok := p.ghosts[e.key]
+	if !ok {
+		e.hot = false
+		e.element = p.t1.PushFront(e)
+		p.trim()
+		return
+	}
+	delete(p.ghosts, e.key)
+	if g.Value.(*ghost).hot {
+		p.p = max(0, p.p-max(p.b1.Len()/p.b2.Len(), 1))
+		p.b2.Remove(g)
+		p.fromB2 = true
+	} else {
+		p.p = min(p.capacity, p.p+max(p.b2.Len()/p.b1.Len(), 1))
+		p.b1.Remove(g)
+	}
+	e.hot = true
+	e.element = p.t2.PushFront(e)
+}
+
+// record 记录对条目的访问
+func (p *arcPolicy) record(e *entry) {
+	if e.hot {
+		p.t2.MoveToFront(e.element)
+		return
+	}
+	p.t1.Remove(e.element)
+	e.hot = true
+	e.element = p.t2.PushFront(e)
+}
+
+// remove 删除条目
+func (p *arcPolicy) remove(e *entry) {
+	if e.hot {
+		p.t2.Remove(e.element)
+	} else {
+		p.t1.Remove(e.element)
+	}
+	if p.incoming == e {
+		p.incoming = nil
+	}
+}
+
+// evict 选择并删除一个被淘汰的条目
+func (p *arcPolicy) evict() *entry {
+	t1 := p.t1.Len()
+	if p.incoming != nil && !p.incoming.hot {
+		t1--
+	}
+	fromT1 := t1 >= 1 && (t1 > p.p || (p.fromB2 && t1 == p.p))
+	if !fromT1 {
+		back := p.t2.Back()
+		fromT1 = back == nil || back.Value.(*entry) == p.incoming
+	}
+	l, b := p.t2, p.b2
+	if fromT1 {
+		l, b = p.t1, p.b1
+	}
+	if l.Len() == 0 {
+		l, b = p.t1, p.b1
+		if fromT1 {
+			l, b = p.t2, p.b2
+		}
+	}
+	back := l.Back()
+	if back == nil {
+		return nil
+	}
+	e := l.Remove(back).(*entry)
+	p.ghosts[e.key] = b.PushFront(&ghost{key: e.key, hot: e.hot})
+	p.trim()
+	return e
+}
+
+// trim 限制 B1 与 B2 的大小
+func (p *arcPolicy) trim() {
+	for p.t1.Len()+p.b1.Len() > p.capacity && p.b1.Len() > 0 {
+		p.dropGhost(p.b1)
+	}
+	for p.t1.Len()+p.t2.Len()+p.b1.Len()+p.b2.Len() > 2*p.capacity && p.b2.Len() > 0 {
+		p.dropGhost(p.b2)
+	}
+}
+
+// dropGhost 删除 B1 或 B2 中最久淘汰的键
+func (p *arcPolicy) dropGhost(b *list.List) {
+	g := b.Remove(b.Back()).(*ghost)
+	delete(p.ghosts, g.key)
+}
+
+// clear 删除所有条目
+func (p *arcPolicy) clear() {
+	p.p = 0
+	p.t1.Init()
+	p.t2.Init()
+	p.b1.Init()
+	p.b2.Init()
+	p.ghosts = make(map[interface{}]*list.Element)
+	p.incoming = nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
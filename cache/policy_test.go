@@ -0,0 +1,119 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package cache
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keys 按淘汰顺序返回策略中所有条目的键
+func keys(p policy) []interface{} {
+	var ks []interface{}
+	for e := p.evict(); e != nil; e = p.evict() {
+		ks = append(ks, e.key)
+	}
+	return ks
+}
+
+func TestLRUPolicy(t *testing.T) {
+	p := newLRUPolicy()
+	entries := make([]*entry, 4)
+	for i := range entries {
+		entries[i] = &entry{key: i}
+		p.add(entries[i])
+	}
+	p.record(entries[0])
+	p.remove(entries[2])
+	assert.Equal(t, []interface{}{1, 3, 0}, keys(p))
+}
+
+func TestLFUPolicy(t *testing.T) {
+	p := newLFUPolicy()
+	entries := make([]*entry, 4)
+	for i := range entries {
+		entries[i] = &entry{key: i}
+		p.add(entries[i])
+	}
+	p.record(entries[0])
+	p.record(entries[0])
+	p.record(entries[1])
+	p.record(entries[3])
+	p.remove(entries[3])
+	// 2 访问1次;1 访问2次;0 访问3次
+	assert.Equal(t, []interface{}{2, 1, 0}, keys(p))
+
+	p.add(&entry{key: "a"})
+	p.add(&entry{key: "b"})
+	assert.Equal(t, []interface{}{"a", "b"}, keys(p))
+}
+
+func TestARCPolicy(t *testing.T) {
+	c := New(Config{MaxSize: 3, Policy: ARC})
+	// 经常访问的键不会被一次性扫描淘汰
+	for i := 0; i < 3; i++ {
+		c.Put("hot", i)
+		_, _ = c.Get("hot")
+	}
+	for i := 0; i < 10; i++ {
+		c.Put(i, i)
+	}
+	_, ok := c.GetIfPresent("hot")
+	assert.True(t, ok)
+
+	p := c.policy.(*arcPolicy)
+	assert.LessOrEqual(t, p.t1.Len()+p.b1.Len(), 3)
+	assert.LessOrEqual(t, p.t1.Len()+p.t2.Len()+p.b1.Len()+p.b2.Len(), 6)
+
+	// 命中 B1 的键增大 T1 的目标大小并直接进入 T2
+	var evicted interface{}
+	for e := p.b1.Front(); e != nil; e = e.Next() {
+		evicted = e.Value.(*ghost).key
+	}
+	c.Put(evicted, "again")
+	assert.Equal(t, 1, p.p)
+	assert.True(t, c.entries[evicted].hot)
+}
+
+func TestPolicy_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(2021))
+	for _, policy := range []Policy{LRU, LFU, ARC} {
+		c := New(Config{MaxSize: 16, Policy: policy})
+		for i := 0; i < 5000; i++ {
+			key := r.Intn(48)
+			switch r.Intn(4) {
+			case 0:
+				c.Invalidate(key)
+			case 1:
+				c.Put(key, i)
+			default:
+				if _, ok := c.GetIfPresent(key); !ok {
+					c.Put(key, i)
+				}
+			}
+			assert.LessOrEqual(t, len(c.entries), 16)
+		}
+		if p, ok := c.policy.(*arcPolicy); ok {
+			assert.Equal(t, len(c.entries), p.t1.Len()+p.t2.Len())
+			assert.Equal(t, len(p.ghosts), p.b1.Len()+p.b2.Len())
+		}
+		assert.Len(t, keys(c.policy), len(c.entries), policy.String())
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Code 错误码,用于将错误分类并映射为 HTTP 状态码或 gRPC 风格的错误码
+type Code int
+
+const (
+	OK                 Code = iota // 没有错误
+	Unknown                        // 未知错误
+	InvalidArgument                // 参数不合法
+	NotFound                       // 资源不存在
+	AlreadyExists                  // 资源已存在
+	Conflict                       // 与当前状态冲突,如并发修改
+	PermissionDenied               // 没有权限
+	Unauthenticated                // 未认证
+	ResourceExhausted              // 资源耗尽,如容量已满或超出配额
+	FailedPrecondition             // 不满足执行操作的前提条件
+	OutOfRange                     // 超出有效范围
+	Unimplemented                  // 操作未实现或不支持
+	Internal                       // 内部错误
+	Unavailable                    // 服务暂时不可用
+	DeadlineExceeded               // 超时
+	Canceled                       // 操作被取消
+	DataLoss                       // 数据丢失或损坏
+)
+
+// codeInfo 错误码的名称与映射
+type codeInfo struct {
+	name       string
+	httpStatus int
+	grpcCode   int
+}
+
+// codeInfos 错误码的名称、HTTP 状态码与 gRPC 错误码
+var codeInfos = [...]codeInfo{
+	OK:                 {"OK", 200, 0},
+	Unknown:            {"Unknown", 500, 2},
+	InvalidArgument:    {"InvalidArgument", 400, 3},
+	NotFound:           {"NotFound", 404, 5},
+	AlreadyExists:      {"AlreadyExists", 409, 6},
+	Conflict:           {"Conflict", 409, 10},
+	PermissionDenied:   {"PermissionDenied", 403, 7},
+	Unauthenticated:    {"Unauthenticated", 401, 16},
+	ResourceExhausted:  {"ResourceExhausted", 429, 8},
+	FailedPrecondition: {"FailedPrecondition", 400, 9},
+	OutOfRange:         {"OutOfRange", 400, 11},
+	Unimplemented:      {"Unimplemented", 501, 12},
+	Internal:           {"Internal", 500, 13},
+	Unavailable:        {"Unavailable", 503, 14},
+	DeadlineExceeded:   {"DeadlineExceeded", 504, 4},
+	Canceled:           {"Canceled", 499, 1},
+	DataLoss:           {"DataLoss", 500, 15},
+}
+
+// info 返回错误码的信息,未知的错误码按 Unknown 处理
+func (c Code) info() codeInfo {
+	if c < 0 || int(c) >= len(codeInfos) {
+		return codeInfos[Unknown]
+	}
+	return codeInfos[c]
+}
+
+// String 返回错误码的名称
+func (c Code) String() string {
+	if c < 0 || int(c) >= len(codeInfos) {
+		return "Code(" + strconv.Itoa(int(c)) + ")"
+	}
+	return codeInfos[c].name
+}
+
+// HTTPStatus 返回错误码对应的 HTTP 状态码
+func (c Code) HTTPStatus() int {
+	return c.info().httpStatus
+}
+
+// GRPCCode 返回错误码对应的 gRPC 错误码数值
+//
+// Conflict 对应 Aborted(10).
+func (c Code) GRPCCode() int {
+	return c.info().grpcCode
+}
+
+// CodeFromHTTPStatus 返回 HTTP 状态码对应的错误码
+//
+// 多个错误码对应同一个状态码时返回最通用的错误码,如 400 对应 InvalidArgument,409 对应 Conflict.
+// 未列出的 2xx 状态码对应 OK,4xx 对应 FailedPrecondition,其余对应 Unknown.
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case 400:
+		return InvalidArgument
+	case 409:
+		return Conflict
+	case 500:
+		return Internal
+	}
+	for c, info := range codeInfos {
+		if info.httpStatus == status {
+			return Code(c)
+		}
+	}
+	switch {
+	case status >= 200 && status < 300:
+		return OK
+	case status >= 400 && status < 500:
+		return FailedPrecondition
+	}
+	return Unknown
+}
+
+// CodeFromGRPC 返回 gRPC 错误码数值对应的错误码,未知的数值对应 Unknown
+func CodeFromGRPC(code int) Code {
+	for c, info := range codeInfos {
+		if info.grpcCode == code {
+			return Code(c)
+		}
+	}
+	return Unknown
+}
+
+// coder 携带错误码的错误
+type coder interface {
+	Code() Code
+}
+
+// retryable 声明是否可重试的错误
+type retryable interface {
+	Retryable() bool
+}
+
+// temporary 声明是否为临时错误的错误,如 net.Error
+type temporary interface {
+	Temporary() bool
+}
+
+// codeError 附加了错误码的错误
+type codeError struct {
+	error
+	code      Code
+	retryable *bool // 是否可重试,nil 表示由错误码决定
+}
+
+// Code 返回附加的错误码
+func (e *codeError) Code() Code {
+	return e.code
+}
+
+// Retryable 返回是否可重试
+func (e *codeError) Retryable() bool {
+	if e.retryable != nil {
+		return *e.retryable
+	}
+	return e.code.retryable()
+}
+
+// Unwrap 返回被附加错误码的错误
+func (e *codeError) Unwrap() error {
+	return e.error
+}
+
+// WithCode 返回附加了错误码 code 的 err,如果 err 为 nil 则返回 nil
+//
+// 返回的错误的消息与 err 相同,errors.Is 与 errors.As 仍可匹配 err.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	return &codeError{error: err, code: code}
+}
+
+// WithRetryable 返回声明了是否可重试的 err,如果 err 为 nil 则返回 nil
+//
+// 声明优先于错误码,错误码保持为 CodeOf(err).
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+	return &codeError{error: err, code: CodeOf(err), retryable: &retryable}
+}
+
+// CodeOf 返回错误的错误码
+//
+// 如果 err 为 nil 则返回 OK.
+// 优先使用错误链中通过 WithCode 附加或由 Code() Code 方法提供的错误码,
+// 否则根据本包的哨兵错误与 context 包的错误推断,无法推断时返回 Unknown.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	var c coder
+	if errors.As(err, &c) {
+		return c.Code()
+	}
+	switch {
+	case errors.Is(err, NoSuchElement):
+		return NotFound
+	case errors.Is(err, IllegalArgument), errors.Is(err, NilPointer):
+		return InvalidArgument
+	case errors.Is(err, IndexOutOfBound):
+		return OutOfRange
+	case errors.Is(err, IllegalState):
+		return FailedPrecondition
+	case errors.Is(err, ConcurrentModification):
+		return Conflict
+	case errors.Is(err, UnsupportedOperation):
+		return Unimplemented
+	case errors.Is(err, Full):
+		return ResourceExhausted
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	}
+	return Unknown
+}
+
+// IsCode 如果错误的错误码为 code 则返回 true,否则返回 false
+func IsCode(err error, code Code) bool {
+	return CodeOf(err) == code
+}
+
+// IsRetryable 如果重试可能成功则返回 true,否则返回 false
+//
+// 优先使用错误链中最外层的 Retryable() bool 声明(WithCode 只附加错误码,不视为声明),
+// 其次使用 Temporary() bool 方法的结果,否则错误码为 Unavailable、DeadlineExceeded、ResourceExhausted 或 Conflict 时可重试.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	// 只附加了错误码的 codeError 不是声明,继续在其包装的错误中查找
+	for next := err; next != nil; {
+		var r retryable
+		if !errors.As(next, &r) {
+			break
+		}
+		if e, ok := r.(*codeError); ok && e.retryable == nil {
+			next = e.error
+			continue
+		}
+		return r.Retryable()
+	}
+	if IsTemporary(err) {
+		return true
+	}
+	return CodeOf(err).retryable()
+}
+
+// IsTemporary 如果错误链中的错误声明自身为临时错误则返回 true,否则返回 false
+func IsTemporary(err error) bool {
+	var t temporary
+	return errors.As(err, &t) && t.Temporary()
+}
+
+// retryable 如果该错误码的错误重试可能成功则返回 true
+func (c Code) retryable() bool {
+	switch c {
+	case Unavailable, DeadlineExceeded, ResourceExhausted, Conflict:
+		return true
+	}
+	return false
+}
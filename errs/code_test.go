@@ -0,0 +1,106 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// timeoutError 声明自身为临时错误的错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "NotFound", NotFound.String())
+	assert.Equal(t, "Code(99)", Code(99).String())
+	assert.Equal(t, 404, NotFound.HTTPStatus())
+	assert.Equal(t, 5, NotFound.GRPCCode())
+	assert.Equal(t, 409, Conflict.HTTPStatus())
+	assert.Equal(t, 10, Conflict.GRPCCode())
+	assert.Equal(t, 500, Code(99).HTTPStatus())
+	assert.Equal(t, 2, Code(-1).GRPCCode())
+
+	for c := OK; c <= DataLoss; c++ {
+		assert.Equal(t, c, CodeFromGRPC(c.GRPCCode()), c.String())
+	}
+	assert.Equal(t, Unknown, CodeFromGRPC(42))
+
+	assert.Equal(t, NotFound, CodeFromHTTPStatus(404))
+	assert.Equal(t, InvalidArgument, CodeFromHTTPStatus(400))
+	assert.Equal(t, Conflict, CodeFromHTTPStatus(409))
+	assert.Equal(t, Internal, CodeFromHTTPStatus(500))
+	assert.Equal(t, OK, CodeFromHTTPStatus(204))
+	assert.Equal(t, FailedPrecondition, CodeFromHTTPStatus(418))
+	assert.Equal(t, Unknown, CodeFromHTTPStatus(302))
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, OK, CodeOf(nil))
+	assert.Equal(t, Unknown, CodeOf(errors.New("x")))
+	assert.Equal(t, NotFound, CodeOf(NewError(NoSuchElement, "Stack.Pop")))
+	assert.Equal(t, OutOfRange, CodeOf(NewIndexError("SliceList.Get", 1, 0)))
+	assert.Equal(t, InvalidArgument, CodeOf(NilPointer))
+	assert.Equal(t, ResourceExhausted, CodeOf(Wrap(Full, "add")))
+	assert.Equal(t, Unimplemented, CodeOf(UnsupportedOperation))
+	assert.Equal(t, Conflict, CodeOf(ConcurrentModification))
+	assert.Equal(t, DeadlineExceeded, CodeOf(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.Equal(t, Canceled, CodeOf(context.Canceled))
+
+	// 附加的错误码优先于推断
+	err := WithCode(NoSuchElement, PermissionDenied)
+	assert.Equal(t, "no such element", err.Error())
+	assert.True(t, errors.Is(err, NoSuchElement))
+	assert.Equal(t, PermissionDenied, CodeOf(Wrap(err, "get")))
+	assert.True(t, IsCode(err, PermissionDenied))
+	assert.Nil(t, WithCode(nil, Internal))
+
+	// MultiError 返回第一个可确定的错误码
+	assert.Equal(t, NotFound, CodeOf(Append(errors.New("x"), WithCode(errors.New("y"), NotFound))))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsRetryable(errors.New("x")))
+	assert.True(t, IsRetryable(WithCode(errors.New("x"), Unavailable)))
+	assert.False(t, IsRetryable(WithCode(errors.New("x"), InvalidArgument)))
+	assert.True(t, IsRetryable(context.DeadlineExceeded))
+	assert.True(t, IsRetryable(Full))
+
+	assert.True(t, IsTemporary(Wrap(timeoutError{}, "dial")))
+	assert.True(t, IsRetryable(Wrap(timeoutError{}, "dial")))
+	assert.False(t, IsTemporary(errors.New("x")))
+
+	err := WithRetryable(NoSuchElement, true)
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, NotFound, CodeOf(err))
+	assert.False(t, IsRetryable(WithRetryable(WithCode(errors.New("x"), Unavailable), false)))
+	assert.Nil(t, WithRetryable(nil, true))
+
+	assert.True(t, IsRetryable(WithCode(WithRetryable(errors.New("x"), true), Internal)))
+	assert.False(t, IsRetryable(WithCode(WithRetryable(errors.New("x"), false), Unavailable)))
+	assert.True(t, IsRetryable(WithCode(timeoutError{}, Internal)))
+	assert.True(t, IsRetryable(Wrap(WithCode(Wrap(timeoutError{}, "dial"), Internal), "call")))
+	assert.False(t, IsRetryable(WithCode(WithCode(errors.New("x"), Unavailable), Internal)))
+}
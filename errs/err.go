@@ -18,7 +18,11 @@
 // 错误处理包
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	//NotFound        = errors.New("not found")
@@ -27,29 +31,129 @@ var (
 	IllegalState           = errors.New("illegal state")
 	NilPointer             = errors.New("nil pointer")
 	ConcurrentModification = errors.New("concurrent Modification")
+	UnsupportedOperation   = errors.New("unsupported operation")
+	IllegalArgument        = errors.New("illegal argument")
+	Full                   = errors.New("full")
+)
+
+// 错误中已设置的可选字段
+const (
+	hasIndex = 1 << iota
+	hasSize
+	hasElement
 )
 
-// indexOutOfBoundsException 实现 errs 接口
-type indexOutOfBoundsError struct {
-	str string
+// Error 携带上下文的集合错误
+//
+// Kind 为本包中的哨兵错误,errors.Is(err, Kind) 返回 true,
+// 可通过 errors.As 取得 *Error 读取出错的操作、下标、大小与元素.
+type Error struct {
+	Kind    error       // 错误的类别
+	Op      string      // 出错的操作,形如 SliceList.Get
+	Index   int         // 出错的下标,HasIndex 返回 true 时有效
+	Size    int         // 集合的大小或容量,HasSize 返回 true 时有效
+	Element interface{} // 出错的元素,HasElement 返回 true 时有效
+	Detail  string      // 附加说明
+	fields  uint8       // 已设置的可选字段
+}
+
+// NewError 创建操作 op 中类别为 kind 的错误
+func NewError(kind error, op string) *Error {
+	return &Error{Kind: kind, Op: op}
+}
+
+// NewIndexError 创建操作 op 中下标越界的错误
+func NewIndexError(op string, index, size int) *Error {
+	return NewError(IndexOutOfBound, op).WithIndex(index).WithSize(size)
+}
+
+// NewIndexOutOfBoundsErrorDefault 新增默认索引超出错误
+func NewIndexOutOfBoundsErrorDefault() *Error {
+	return NewError(IndexOutOfBound, "")
+}
+
+// NewIndexOutOfBoundsError 新增附加说明为 str 的索引超出错误
+func NewIndexOutOfBoundsError(str string) *Error {
+	return NewIndexOutOfBoundsErrorDefault().WithDetail(str)
+}
+
+// WithIndex 设置出错的下标,并返回错误本身
+func (e *Error) WithIndex(index int) *Error {
+	e.Index = index
+	e.fields |= hasIndex
+	return e
+}
+
+// WithSize 设置集合的大小或容量,并返回错误本身
+func (e *Error) WithSize(size int) *Error {
+	e.Size = size
+	e.fields |= hasSize
+	return e
+}
+
+// WithElement 设置出错的元素,并返回错误本身
+func (e *Error) WithElement(element interface{}) *Error {
+	e.Element = element
+	e.fields |= hasElement
+	return e
 }
 
-// NewIndexOutOfBoundsExceptionDefault 新增默认索引超出错误
-func NewIndexOutOfBoundsErrorDefault() *indexOutOfBoundsError {
-	return &indexOutOfBoundsError{}
+// WithDetail 设置附加说明,并返回错误本身
+func (e *Error) WithDetail(detail string) *Error {
+	e.Detail = detail
+	return e
 }
 
-// NewIndexOutOfBoundsError 新增默认索引超出错误
-func NewIndexOutOfBoundsError(str string) *indexOutOfBoundsError {
-	return &indexOutOfBoundsError{str: str}
+// HasIndex 如果设置了出错的下标则返回 true,否则返回 false
+func (e *Error) HasIndex() bool {
+	return e.fields&hasIndex != 0
 }
 
-// Error 实现 errs 接口
-func (i indexOutOfBoundsError) Error() string {
-	str := "index out of bound"
-	if len(i.str) != 0 {
+// HasSize 如果设置了集合的大小则返回 true,否则返回 false
+func (e *Error) HasSize() bool {
+	return e.fields&hasSize != 0
+}
 
-		return str + "," + i.str
+// HasElement 如果设置了出错的元素则返回 true,否则返回 false
+func (e *Error) HasElement() bool {
+	return e.fields&hasElement != 0
+}
+
+// Error 实现 error 接口
+//
+// 形如 SliceList.Get: index out of bound (index 5, size 3): 附加说明
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Op != "" {
+		b.WriteString(e.Op)
+		b.WriteString(": ")
+	}
+	if e.Kind != nil {
+		b.WriteString(e.Kind.Error())
 	}
-	return str
+	var context []string
+	if e.HasIndex() {
+		context = append(context, fmt.Sprintf("index %d", e.Index))
+	}
+	if e.HasSize() {
+		context = append(context, fmt.Sprintf("size %d", e.Size))
+	}
+	if e.HasElement() {
+		context = append(context, fmt.Sprintf("element %v", e.Element))
+	}
+	if len(context) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(context, ", "))
+		b.WriteString(")")
+	}
+	if e.Detail != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Detail)
+	}
+	return b.String()
+}
+
+// Unwrap 返回错误的类别,使 errors.Is 可以与哨兵错误比较
+func (e *Error) Unwrap() error {
+	return e.Kind
 }
@@ -18,8 +18,12 @@
 package errs
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewIndexOutOfBoundsError(t *testing.T) {
@@ -29,22 +33,22 @@ func TestNewIndexOutOfBoundsError(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want *indexOutOfBoundsError
+		want *Error
 	}{
 		{
 			"1",
 			args{""},
-			&indexOutOfBoundsError{str: ""},
+			&Error{Kind: IndexOutOfBound},
 		}, {
 			"2",
 			args{"errs"},
-			&indexOutOfBoundsError{str: "errs"},
+			&Error{Kind: IndexOutOfBound, Detail: "errs"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := NewIndexOutOfBoundsError(tt.args.str); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewIndexOutOfBoundsException() = %v, want %v", got, tt.want)
+				t.Errorf("NewIndexOutOfBoundsError() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -53,49 +57,77 @@ func TestNewIndexOutOfBoundsError(t *testing.T) {
 func TestNewIndexOutOfBoundsErrorDefault(t *testing.T) {
 	tests := []struct {
 		name string
-		want *indexOutOfBoundsError
+		want *Error
 	}{
 		{
 			"1",
-			&indexOutOfBoundsError{},
+			&Error{Kind: IndexOutOfBound},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := NewIndexOutOfBoundsErrorDefault(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewIndexOutOfBoundsExceptionDefault() = %v, want %v", got, tt.want)
+				t.Errorf("NewIndexOutOfBoundsErrorDefault() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_indexOutOfBoundsError_Error(t *testing.T) {
-	type fields struct {
-		str string
-	}
+func TestError_Error(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name string
+		err  *Error
+		want string
 	}{
 		{
 			"1",
-			fields{str: "1"},
-			"index out of bound,1",
+			NewIndexOutOfBoundsError("1"),
+			"index out of bound: 1",
 		}, {
 			"2",
-			fields{str: ""},
+			NewIndexOutOfBoundsErrorDefault(),
 			"index out of bound",
+		}, {
+			"3",
+			NewIndexError("SliceList.Get", 5, 3),
+			"SliceList.Get: index out of bound (index 5, size 3)",
+		}, {
+			"4",
+			NewError(Full, "RingBuffer.Add").WithSize(4).WithElement("x"),
+			"RingBuffer.Add: full (size 4, element x)",
+		}, {
+			"5",
+			NewError(NilPointer, "SliceDeQueue.AddFirst"),
+			"SliceDeQueue.AddFirst: nil pointer",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			i := indexOutOfBoundsError{
-				str: tt.fields.str,
-			}
-			if got := i.Error(); got != tt.want {
+			if got := tt.err.Error(); got != tt.want {
 				t.Errorf("Error() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestError_Is(t *testing.T) {
+	var err error = NewIndexError("LinkedList.Get", -1, 0)
+	assert.True(t, errors.Is(err, IndexOutOfBound))
+	assert.False(t, errors.Is(err, NoSuchElement))
+
+	wrapped := fmt.Errorf("load: %w", err)
+	assert.True(t, errors.Is(wrapped, IndexOutOfBound))
+	var e *Error
+	assert.True(t, errors.As(wrapped, &e))
+	assert.Equal(t, "LinkedList.Get", e.Op)
+	assert.True(t, e.HasIndex())
+	assert.Equal(t, -1, e.Index)
+	assert.True(t, e.HasSize())
+	assert.Equal(t, 0, e.Size)
+	assert.False(t, e.HasElement())
+
+	e = NewError(IllegalArgument, "op").WithElement(nil)
+	assert.True(t, e.HasElement())
+	assert.Nil(t, e.Element)
+	assert.True(t, errors.Is(e, IllegalArgument))
+}
@@ -0,0 +1,194 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MultiError 多个错误的集合
+//
+// errors.Is 与 errors.As 会依次检查每个错误.
+// 零值为空的错误集合,可直接使用.
+// 注意 MultiError 协程不安全,在多个协程中收集错误应使用 Group.
+type MultiError struct {
+	errors []error
+}
+
+// Append 将错误 err 与 errs 合并为一个错误
+//
+// nil 错误会被忽略,*MultiError 会被展开.
+// 如果 err 为 *MultiError 则直接向其追加,所有错误均为 nil 时返回 nil.
+func Append(err error, errs ...error) error {
+	m, ok := err.(*MultiError)
+	if !ok || m == nil {
+		m = &MultiError{}
+		m.Append(err)
+	}
+	m.Append(errs...)
+	return m.ErrorOrNil()
+}
+
+// Append 追加错误,nil 错误会被忽略,*MultiError 会被展开
+func (m *MultiError) Append(errs ...error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if other, ok := err.(*MultiError); ok {
+			if other != nil && other != m {
+				m.errors = append(m.errors, other.errors...)
+			}
+			continue
+		}
+		m.errors = append(m.errors, err)
+	}
+}
+
+// Len 返回错误的个数
+func (m *MultiError) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.errors)
+}
+
+// Errors 按追加的顺序返回所有错误
+//
+// 返回的切片是安全的,可任意修改不会影响错误集合.
+func (m *MultiError) Errors() []error {
+	if m == nil {
+		return nil
+	}
+	return append([]error(nil), m.errors...)
+}
+
+// ErrorOrNil 如果不存在错误则返回 nil,否则返回错误集合本身
+//
+// 返回 error 接口时应使用该方法,避免返回包含 nil 指针的非 nil 接口.
+func (m *MultiError) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error 实现 error 接口
+//
+// 只有一个错误时返回该错误的消息,否则形如 2 errors occurred: a; b
+func (m *MultiError) Error() string {
+	switch m.Len() {
+	case 0:
+		return "no errors"
+	case 1:
+		return m.errors[0].Error()
+	}
+	messages := make([]string, len(m.errors))
+	for i, err := range m.errors {
+		messages[i] = err.Error()
+	}
+	return strconv.Itoa(len(m.errors)) + " errors occurred: " + strings.Join(messages, "; ")
+}
+
+// Format 实现 fmt.Formatter 接口
+//
+// %s 与 %v 输出错误消息,%q 输出带引号的错误消息,
+// %+v 逐行输出每个错误,每个错误使用 %+v 格式化,可输出其调用栈.
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = fmt.Fprintf(s, "%d errors occurred:", m.Len())
+			for i, err := range m.Errors() {
+				_, _ = fmt.Fprintf(s, "\n[%d] %+v", i, err)
+			}
+			return
+		}
+		_, _ = io.WriteString(s, m.Error())
+	case 's':
+		_, _ = io.WriteString(s, m.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", m.Error())
+	}
+}
+
+// Is 如果任一错误与 target 匹配则返回 true,供 errors.Is 使用
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 将第一个可以赋值给 target 的错误赋值给 target,供 errors.As 使用
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Group 在多个协程中收集错误
+//
+// 零值可直接使用,所有方法协程安全.
+type Group struct {
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errors MultiError
+}
+
+// Go 在新的协程中调用 f,并收集其返回的错误
+func (g *Group) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.Add(f())
+	}()
+}
+
+// Add 收集错误 err,nil 错误会被忽略
+func (g *Group) Add(err error) {
+	if err == nil {
+		return
+	}
+	g.mu.Lock()
+	g.errors.Append(err)
+	g.mu.Unlock()
+}
+
+// Wait 等待所有通过 Go 启动的协程结束,并返回收集到的错误
+//
+// 错误按收集的顺序排列,不存在错误时返回 nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.errors.Len() == 0 {
+		return nil
+	}
+	return &MultiError{errors: g.errors.Errors()}
+}
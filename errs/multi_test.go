@@ -0,0 +1,109 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiError(t *testing.T) {
+	var m MultiError
+	assert.Nil(t, m.ErrorOrNil())
+	assert.Equal(t, 0, m.Len())
+	var nilMulti *MultiError
+	assert.Nil(t, nilMulti.ErrorOrNil())
+	assert.Nil(t, nilMulti.Errors())
+
+	m.Append(nil, NoSuchElement)
+	assert.Equal(t, "no such element", m.Error())
+	m.Append(NewIndexError("SliceList.Get", 2, 1))
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, "2 errors occurred: no such element; SliceList.Get: index out of bound (index 2, size 1)", m.Error())
+
+	err := m.ErrorOrNil()
+	assert.True(t, errors.Is(err, NoSuchElement))
+	assert.True(t, errors.Is(err, IndexOutOfBound))
+	assert.False(t, errors.Is(err, Full))
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 2, e.Index)
+
+	// 包装后仍可遍历
+	wrapped := fmt.Errorf("batch: %w", err)
+	assert.True(t, errors.Is(wrapped, IndexOutOfBound))
+
+	errs := m.Errors()
+	errs[0] = nil
+	assert.Equal(t, NoSuchElement, m.Errors()[0])
+}
+
+func TestAppend(t *testing.T) {
+	assert.Nil(t, Append(nil))
+	assert.Nil(t, Append(nil, nil))
+	assert.Equal(t, "a", Append(nil, errors.New("a")).Error())
+
+	err := Append(errors.New("a"), errors.New("b"))
+	err = Append(err, nil, errors.New("c"))
+	assert.Equal(t, 3, err.(*MultiError).Len())
+
+	// 展开嵌套的 MultiError
+	err = Append(errors.New("x"), err)
+	assert.Equal(t, "4 errors occurred: x; a; b; c", err.Error())
+	err = Append(err, err)
+	assert.Equal(t, 4, err.(*MultiError).Len())
+}
+
+func TestMultiError_Format(t *testing.T) {
+	err := Append(New("a"), errors.New("b"))
+	assert.Equal(t, "2 errors occurred: a; b", fmt.Sprintf("%v", err))
+	assert.Equal(t, `"2 errors occurred: a; b"`, fmt.Sprintf("%q", err))
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, "2 errors occurred:\n[0] a\n"), verbose)
+	assert.Contains(t, verbose, "errs.TestMultiError_Format")
+	assert.True(t, strings.HasSuffix(verbose, "\n[1] b"), verbose)
+}
+
+func TestGroup(t *testing.T) {
+	var g Group
+	assert.Nil(t, g.Wait())
+
+	var calls int32
+	for i := 0; i < 100; i++ {
+		i := i
+		g.Go(func() error {
+			atomic.AddInt32(&calls, 1)
+			if i%10 == 0 {
+				return fmt.Errorf("task %d: %w", i, IllegalState)
+			}
+			return nil
+		})
+	}
+	g.Add(nil)
+	g.Add(Full)
+	err := g.Wait()
+	assert.Equal(t, int32(100), atomic.LoadInt32(&calls))
+	assert.Equal(t, 11, err.(*MultiError).Len())
+	assert.True(t, errors.Is(err, IllegalState))
+	assert.True(t, errors.Is(err, Full))
+}
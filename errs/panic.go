@@ -0,0 +1,136 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// panicHook 报告被恢复的 panic 的钩子
+var panicHook atomic.Value // func(err *PanicError)
+
+// SetPanicHook 设置报告被恢复的 panic 的钩子,hook 为 nil 时不报告
+//
+// 可以与 Recover 并发调用,例如调用 logging.ReportPanics 通过日志报告.
+func SetPanicHook(hook func(err *PanicError)) {
+	panicHook.Store(hook)
+}
+
+// PanicHook 返回当前报告被恢复的 panic 的钩子,未设置时返回 nil
+func PanicHook() func(err *PanicError) {
+	hook, _ := panicHook.Load().(func(err *PanicError))
+	return hook
+}
+
+// PanicError 由 panic 转换得到的错误
+type PanicError struct {
+	Value interface{} // panic 的值
+	stack Frames      // 引发 panic 时的调用栈
+}
+
+// Error 实现 error 接口,形如 panic: 值
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap 如果 panic 的值为 error 则返回该值,否则返回 nil
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Stack 返回引发 panic 时的调用栈,第一帧为调用 panic 的函数
+func (e *PanicError) Stack() Frames {
+	return e.stack
+}
+
+// Format 实现 fmt.Formatter 接口
+//
+// %s 与 %v 输出错误消息,%q 输出带引号的错误消息,%+v 在错误消息之后输出调用栈.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		_, _ = io.WriteString(s, e.Error())
+		if s.Flag('+') && len(e.stack) > 0 {
+			_, _ = io.WriteString(s, "\n")
+			_, _ = io.WriteString(s, e.stack.String())
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// Recover 恢复 panic 并将其转换为 *PanicError 赋值给 *err
+//
+// 必须直接以 defer errs.Recover(&err) 的方式调用.
+// 恢复的 panic 会先交给 SetPanicHook 设置的钩子报告.err 为 nil 时只报告不赋值.
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	pe := &PanicError{Value: r, stack: panicStack()}
+	if hook := PanicHook(); hook != nil {
+		hook(pe)
+	}
+	if err != nil {
+		*err = pe
+	}
+}
+
+// Try 调用 f 并返回其错误,f 引发的 panic 会被转换为 *PanicError 返回
+func Try(f func() error) (err error) {
+	defer Recover(&err)
+	return f()
+}
+
+// SafeGo 在新的协程中调用 f,f 引发的 panic 会被恢复并交给 SetPanicHook 设置的钩子报告
+func SafeGo(f func()) {
+	go func() {
+		defer Recover(nil)
+		f()
+	}()
+}
+
+// panicStack 返回引发 panic 时的调用栈
+//
+// 在 Recover 中捕获的调用栈依次包含 Recover、runtime 中处理 panic 的帧与引发 panic 的帧,
+// 只保留引发 panic 的帧及其调用方.
+func panicStack() Frames {
+	frames := runtime.CallersFrames(callers(3))
+	var stack Frames
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && (len(stack) > 0 || !strings.HasPrefix(frame.Function, "runtime.")):
+			stack = append(stack, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			return stack
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func indexPanic() error {
+	var s []int
+	_ = s[len(s)+1]
+	return nil
+}
+
+func boomPanic() error {
+	panic("boom")
+}
+
+func goroutinePanic() {
+	panic("in goroutine")
+}
+
+func TestTry(t *testing.T) {
+	assert.Nil(t, Try(func() error { return nil }))
+	assert.Equal(t, NoSuchElement, Try(func() error { return NoSuchElement }))
+
+	err := Try(boomPanic)
+	var pe *PanicError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "boom", pe.Value)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.True(t, strings.HasSuffix(pe.Stack()[0].Function, "errs.boomPanic"), pe.Stack()[0].Function)
+
+	err = Try(indexPanic)
+	var re runtime.Error
+	assert.True(t, errors.As(err, &re))
+	assert.True(t, strings.HasSuffix(err.(*PanicError).Stack()[0].Function, "errs.indexPanic"))
+
+	err = Try(func() error { panic(Full) })
+	assert.True(t, errors.Is(err, Full))
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, "panic: full\n"), verbose)
+	assert.Contains(t, verbose, "panic_test.go:")
+	assert.Equal(t, "panic: full", fmt.Sprintf("%v", err))
+}
+
+func TestRecover(t *testing.T) {
+	f := func() (err error) {
+		defer Recover(&err)
+		panic(42)
+	}
+	err := f()
+	assert.Equal(t, 42, err.(*PanicError).Value)
+
+	g := func() (err error) {
+		defer Recover(&err)
+		return IllegalState
+	}
+	assert.Equal(t, IllegalState, g())
+}
+
+func TestSafeGo(t *testing.T) {
+	var (
+		wg       sync.WaitGroup
+		reported *PanicError
+	)
+	SetPanicHook(func(err *PanicError) {
+		reported = err
+		wg.Done()
+	})
+	defer SetPanicHook(nil)
+
+	wg.Add(1)
+	SafeGo(goroutinePanic)
+	wg.Wait()
+	assert.Equal(t, "in goroutine", reported.Value)
+	assert.True(t, strings.HasSuffix(reported.Stack()[0].Function, "errs.goroutinePanic"), reported.Stack()[0].Function)
+}
+
+func TestSetPanicHook(t *testing.T) {
+	assert.Nil(t, PanicHook())
+	defer SetPanicHook(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetPanicHook(func(err *PanicError) {})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = Try(func() error { panic("concurrent") })
+		}()
+	}
+	wg.Wait()
+	assert.NotNil(t, PanicHook())
+
+	SetPanicHook(nil)
+	assert.Nil(t, PanicHook())
+}
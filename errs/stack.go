@@ -0,0 +1,174 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth 捕获调用栈的最大深度
+const maxStackDepth = 32
+
+// Frame 调用栈中的一帧
+type Frame struct {
+	Function string // 函数的完整名称
+	File     string // 源文件的完整路径
+	Line     int    // 行号
+}
+
+// String 返回帧的字符串表示,形如 函数名\n\t文件:行号
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// Frames 调用栈,第一帧为最内层的调用
+type Frames []Frame
+
+// String 返回调用栈的字符串表示,每帧占两行
+func (st Frames) String() string {
+	frames := make([]string, len(st))
+	for i, f := range st {
+		frames[i] = f.String()
+	}
+	return strings.Join(frames, "\n")
+}
+
+// stackError 携带调用栈的错误
+type stackError struct {
+	msg   string
+	cause error
+	pcs   []uintptr // 捕获的调用栈,cause 的错误链中已有调用栈时为 nil
+}
+
+// New 返回消息为 message 并捕获当前调用栈的错误
+func New(message string) error {
+	return &stackError{msg: message, pcs: callers(3)}
+}
+
+// Wrap 返回使用消息 message 包装 err 的错误,如果 err 为 nil 则返回 nil
+//
+// 如果 err 的错误链中没有调用栈则捕获当前调用栈,否则保留原有的调用栈.
+// 返回的错误的消息形如 message: err.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: message, cause: err, pcs: callersIfMissing(err)}
+}
+
+// Wrapf 返回使用格式化消息包装 err 的错误,如果 err 为 nil 则返回 nil
+//
+// 调用栈的捕获规则与 Wrap 相同.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: fmt.Sprintf(format, args...), cause: err, pcs: callersIfMissing(err)}
+}
+
+// Cause 返回被 Wrap 与 Wrapf 包装的最内层错误
+//
+// 只展开本包的包装,如果 err 不是包装的错误则返回 err 本身.
+func Cause(err error) error {
+	for {
+		e, ok := err.(*stackError)
+		if !ok || e.cause == nil {
+			return err
+		}
+		err = e.cause
+	}
+}
+
+// StackTrace 返回错误链中最早捕获的调用栈,不存在时返回 nil
+func StackTrace(err error) Frames {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*stackError); ok && e.pcs != nil {
+			pcs = e.pcs
+		}
+	}
+	if pcs == nil {
+		return nil
+	}
+	var st Frames
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		st = append(st, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			return st
+		}
+	}
+}
+
+// Error 实现 error 接口
+func (e *stackError) Error() string {
+	switch {
+	case e.cause == nil:
+		return e.msg
+	case e.msg == "":
+		return e.cause.Error()
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+// Unwrap 返回被包装的错误
+func (e *stackError) Unwrap() error {
+	return e.cause
+}
+
+// Format 实现 fmt.Formatter 接口
+//
+// %s 与 %v 输出错误消息,%q 输出带引号的错误消息,%+v 在错误消息之后输出调用栈.
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		_, _ = io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			if st := StackTrace(e); st != nil {
+				_, _ = io.WriteString(s, "\n")
+				_, _ = io.WriteString(s, st.String())
+			}
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// callers 捕获调用栈,跳过 skip 帧
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
+
+// callersIfMissing 如果 err 的错误链中没有调用栈则捕获 Wrap 与 Wrapf 调用方的调用栈
+func callersIfMissing(err error) []uintptr {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if se, ok := e.(*stackError); ok && se.pcs != nil {
+			return nil
+		}
+	}
+	return callers(4)
+}
@@ -0,0 +1,86 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	err := New("boom")
+	assert.Equal(t, "boom", err.Error())
+	assert.Nil(t, errors.Unwrap(err))
+	assert.Equal(t, err, Cause(err))
+
+	st := StackTrace(err)
+	assert.NotEmpty(t, st)
+	assert.True(t, strings.HasSuffix(st[0].Function, "errs.TestNew"), st[0].Function)
+	assert.True(t, strings.HasSuffix(st[0].File, "stack_test.go"), st[0].File)
+}
+
+func wrapHere(err error) error {
+	return Wrapf(err, "wrap %d", 1)
+}
+
+func TestWrap(t *testing.T) {
+	assert.Nil(t, Wrap(nil, "msg"))
+	assert.Nil(t, Wrapf(nil, "msg %d", 1))
+
+	err := Wrap(NoSuchElement, "load")
+	assert.Equal(t, "load: no such element", err.Error())
+	assert.True(t, errors.Is(err, NoSuchElement))
+	assert.Equal(t, NoSuchElement, Cause(err))
+	assert.True(t, strings.HasSuffix(StackTrace(err)[0].Function, "errs.TestWrap"))
+
+	// 已有调用栈时保留最早捕获的调用栈
+	wrapped := wrapHere(err)
+	assert.Equal(t, "wrap 1: load: no such element", wrapped.Error())
+	assert.Equal(t, StackTrace(err), StackTrace(wrapped))
+	assert.Equal(t, NoSuchElement, Cause(wrapped))
+
+	// 包装其他方式包装的错误
+	indexErr := NewIndexError("SliceList.Get", 1, 0)
+	wrapped = wrapHere(fmt.Errorf("outer: %w", indexErr))
+	assert.True(t, strings.HasSuffix(StackTrace(wrapped)[0].Function, "errs.wrapHere"))
+	var e *Error
+	assert.True(t, errors.As(wrapped, &e))
+	assert.Equal(t, 1, e.Index)
+
+	assert.Nil(t, StackTrace(NoSuchElement))
+	assert.Nil(t, StackTrace(nil))
+}
+
+func TestStackError_Format(t *testing.T) {
+	err := Wrap(New("inner"), "outer")
+	assert.Equal(t, "outer: inner", fmt.Sprintf("%s", err))
+	assert.Equal(t, "outer: inner", fmt.Sprintf("%v", err))
+	assert.Equal(t, `"outer: inner"`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	lines := strings.Split(verbose, "\n")
+	assert.Equal(t, "outer: inner", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], "errs.TestStackError_Format"), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "\t"))
+	assert.Contains(t, lines[2], "stack_test.go:")
+	assert.Equal(t, StackTrace(err).String(), strings.Join(lines[1:], "\n"))
+}
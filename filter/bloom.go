@@ -0,0 +1,244 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const bloomMagic = 'B'
+
+// BloomFilter 布隆过滤器
+//
+// 判断元素"可能存在"或"一定不存在",存在一定的误判率但不会漏判.
+// 注意 BloomFilter 协程不安全.
+type BloomFilter struct {
+	m    uint64   // 位数组大小
+	k    uint64   // 哈希函数个数
+	bits []uint64 // 位数组
+}
+
+// NewBloomFilter 创建位数组大小为 m,哈希函数个数为 k 的布隆过滤器
+//
+// 如果 m 或 k 为0,则取值为1.
+func NewBloomFilter(m, k uint) *BloomFilter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &BloomFilter{
+		m:    uint64(m),
+		k:    uint64(k),
+		bits: make([]uint64, (m+63)/64),
+	}
+}
+
+// NewBloomFilterWithEstimates 根据预期元素个数 n 与期望误判率 fp 创建布隆过滤器
+func NewBloomFilterWithEstimates(n uint, fp float64) *BloomFilter {
+	m, k := EstimateParameters(n, fp)
+	return NewBloomFilter(m, k)
+}
+
+// EstimateParameters 根据预期元素个数 n 与期望误判率 p 估算位数组大小 m 与哈希函数个数 k
+func EstimateParameters(n uint, p float64) (m, k uint) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	mf := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	kf := math.Ceil(math.Ln2 * mf / float64(n))
+	return uint(mf), uint(kf)
+}
+
+// Cap 返回位数组大小
+func (f *BloomFilter) Cap() uint {
+	return uint(f.m)
+}
+
+// K 返回哈希函数个数
+func (f *BloomFilter) K() uint {
+	return uint(f.k)
+}
+
+// location 返回第 i 个哈希函数对应的位下标
+func (f *BloomFilter) location(h1, h2, i uint64) uint64 {
+	return (h1 + i*h2) % f.m
+}
+
+// Add 添加数据
+func (f *BloomFilter) Add(data []byte) {
+	h1, h2 := baseHashes(data)
+	for i := uint64(0); i < f.k; i++ {
+		l := f.location(h1, h2, i)
+		f.bits[l>>6] |= 1 << (l & 63)
+	}
+}
+
+// AddString 添加字符串
+func (f *BloomFilter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Test 如果数据可能存在则返回 true,如果一定不存在则返回 false
+func (f *BloomFilter) Test(data []byte) bool {
+	h1, h2 := baseHashes(data)
+	for i := uint64(0); i < f.k; i++ {
+		l := f.location(h1, h2, i)
+		if f.bits[l>>6]&(1<<(l&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestString 如果字符串可能存在则返回 true,如果一定不存在则返回 false
+func (f *BloomFilter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// TestAndAdd 判断数据是否可能存在,并添加该数据
+//
+// 返回值与添加前调用 Test 的结果相同.
+func (f *BloomFilter) TestAndAdd(data []byte) bool {
+	h1, h2 := baseHashes(data)
+	present := true
+	for i := uint64(0); i < f.k; i++ {
+		l := f.location(h1, h2, i)
+		if f.bits[l>>6]&(1<<(l&63)) == 0 {
+			present = false
+			f.bits[l>>6] |= 1 << (l & 63)
+		}
+	}
+	return present
+}
+
+// EstimatedCount 根据已置位的位数估算已添加的元素个数
+func (f *BloomFilter) EstimatedCount() uint {
+	x := 0
+	for _, w := range f.bits {
+		x += bits.OnesCount64(w)
+	}
+	if uint64(x) >= f.m {
+		return uint(math.MaxUint32)
+	}
+	m, k := float64(f.m), float64(f.k)
+	return uint(math.Round(-m / k * math.Log(1-float64(x)/m)))
+}
+
+// Clear 清空过滤器
+func (f *BloomFilter) Clear() {
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
+
+// Copy 返回当前过滤器的拷贝
+func (f *BloomFilter) Copy() *BloomFilter {
+	b := make([]uint64, len(f.bits))
+	copy(b, f.bits)
+	return &BloomFilter{m: f.m, k: f.k, bits: b}
+}
+
+// compatible 判断两个过滤器是否具有相同的参数
+func (f *BloomFilter) compatible(o *BloomFilter) error {
+	if o == nil || f.m != o.m || f.k != o.k {
+		return IncompatibleErr
+	}
+	return nil
+}
+
+// Union 将指定过滤器合并到当前过滤器(并集)
+//
+// 两个过滤器的位数组大小与哈希函数个数必须相同,否则返回 IncompatibleErr.
+func (f *BloomFilter) Union(o *BloomFilter) error {
+	if err := f.compatible(o); err != nil {
+		return err
+	}
+	for i, w := range o.bits {
+		f.bits[i] |= w
+	}
+	return nil
+}
+
+// Intersect 将当前过滤器与指定过滤器求交集
+//
+// 两个过滤器的位数组大小与哈希函数个数必须相同,否则返回 IncompatibleErr.
+func (f *BloomFilter) Intersect(o *BloomFilter) error {
+	if err := f.compatible(o); err != nil {
+		return err
+	}
+	for i, w := range o.bits {
+		f.bits[i] &= w
+	}
+	return nil
+}
+
+// Equals 比较两个过滤器是否相同
+func (f *BloomFilter) Equals(o *BloomFilter) bool {
+	if f.compatible(o) != nil {
+		return false
+	}
+	for i, w := range o.bits {
+		if f.bits[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 1+16+8*len(f.bits))
+	data[0] = bloomMagic
+	binary.BigEndian.PutUint64(data[1:], f.m)
+	binary.BigEndian.PutUint64(data[9:], f.k)
+	for i, w := range f.bits {
+		binary.BigEndian.PutUint64(data[17+8*i:], w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 17 || data[0] != bloomMagic {
+		return InvalidDataErr
+	}
+	m := binary.BigEndian.Uint64(data[1:])
+	k := binary.BigEndian.Uint64(data[9:])
+	payload := uint64(len(data) - 17)
+	// 先用数据长度限制 m,避免计算 n 时溢出
+	if m == 0 || m > payload*8 || k == 0 {
+		return InvalidDataErr
+	}
+	n := (m + 63) / 64
+	if payload != 8*n {
+		return InvalidDataErr
+	}
+	b := make([]uint64, n)
+	for i := range b {
+		b[i] = binary.BigEndian.Uint64(data[17+8*i:])
+	}
+	f.m, f.k, f.bits = m, k, b
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateParameters(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	assert.Equal(t, uint(9586), m)
+	assert.Equal(t, uint(7), k)
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := NewBloomFilterWithEstimates(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.TestString(strconv.Itoa(i)))
+	}
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if f.TestString(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, float64(falsePositives)/10000, 0.02)
+	assert.InDelta(t, 1000, f.EstimatedCount(), 50)
+
+	assert.False(t, f.TestAndAdd([]byte("new")))
+	assert.True(t, f.TestAndAdd([]byte("new")))
+
+	f.Clear()
+	assert.False(t, f.TestString("1"))
+}
+
+func TestBloomFilter_UnionIntersect(t *testing.T) {
+	f1 := NewBloomFilter(1024, 3)
+	f2 := NewBloomFilter(1024, 3)
+	f1.AddString("a")
+	f1.AddString("b")
+	f2.AddString("b")
+	f2.AddString("c")
+
+	u := f1.Copy()
+	assert.Nil(t, u.Union(f2))
+	assert.True(t, u.TestString("a"))
+	assert.True(t, u.TestString("b"))
+	assert.True(t, u.TestString("c"))
+
+	i := f1.Copy()
+	assert.Nil(t, i.Intersect(f2))
+	assert.True(t, i.TestString("b"))
+	assert.False(t, i.TestString("a"))
+	assert.False(t, i.TestString("c"))
+
+	assert.Equal(t, IncompatibleErr, f1.Union(NewBloomFilter(1024, 4)))
+	assert.Equal(t, IncompatibleErr, f1.Intersect(NewBloomFilter(512, 3)))
+}
+
+func TestBloomFilter_MarshalBinary(t *testing.T) {
+	f := NewBloomFilterWithEstimates(100, 0.001)
+	f.AddString("hello")
+	data, err := f.MarshalBinary()
+	assert.Nil(t, err)
+
+	g := &BloomFilter{}
+	assert.Nil(t, g.UnmarshalBinary(data))
+	assert.True(t, f.Equals(g))
+	assert.True(t, g.TestString("hello"))
+
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(data[:len(data)-1]))
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary([]byte("x")))
+}
+
+func TestBloomFilter_UnmarshalBinaryMalformed(t *testing.T) {
+	header := func(m, k uint64, payload int) []byte {
+		data := make([]byte, 17+payload)
+		data[0] = bloomMagic
+		binary.BigEndian.PutUint64(data[1:], m)
+		binary.BigEndian.PutUint64(data[9:], k)
+		return data
+	}
+	g := &BloomFilter{}
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(header(math.MaxUint64, 3, 0)))
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(header(math.MaxUint64-62, 3, 0)))
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(header(65, 3, 8)))
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(header(64, 0, 8)))
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(header(0, 3, 0)))
+
+	assert.Nil(t, g.UnmarshalBinary(header(64, 3, 8)))
+	g.AddString("hello")
+	assert.True(t, g.TestString("hello"))
+}
@@ -0,0 +1,283 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+const (
+	cuckooMagic = 'C'
+	// 每个桶的槽位数
+	bucketSize = 4
+	// 插入时最大踢出次数
+	maxKicks = 500
+	// 创建时预留的装载因子
+	loadFactor = 0.95
+)
+
+// fingerprint 指纹,0表示空槽位
+type fingerprint uint16
+
+// bucket 桶
+type bucket [bucketSize]fingerprint
+
+// CuckooFilter 布谷鸟过滤器
+//
+// 与 BloomFilter 相比支持删除元素.
+// 注意 CuckooFilter 协程不安全.
+type CuckooFilter struct {
+	buckets []bucket // 桶
+	count   uint     // 元素个数
+}
+
+// NewCuckooFilter 创建可容纳约 capacity 个元素的布谷鸟过滤器
+//
+// 桶数量按装载因子 0.95 计算并多预留一个桶,再向上取整到2的幂,
+// 较小的过滤器也能容纳 capacity 个元素.
+func NewCuckooFilter(capacity uint) *CuckooFilter {
+	n := nextPowerOfTwo(uint64(math.Ceil(float64(capacity)/loadFactor/bucketSize)) + 1)
+	if n == 0 {
+		n = 1
+	}
+	return &CuckooFilter{buckets: make([]bucket, n)}
+}
+
+// nextPowerOfTwo 返回不小于 n 的最小的2的幂
+func nextPowerOfTwo(n uint64) uint64 {
+	n--
+	n |= n >> 1
+	n |= n >> 2
+	n |= n >> 4
+	n |= n >> 8
+	n |= n >> 16
+	n |= n >> 32
+	n++
+	return n
+}
+
+// Count 返回过滤器中元素个数
+func (f *CuckooFilter) Count() uint {
+	return f.count
+}
+
+// Cap 返回过滤器的槽位总数
+func (f *CuckooFilter) Cap() uint {
+	return uint(len(f.buckets)) * bucketSize
+}
+
+// mask 桶下标掩码
+func (f *CuckooFilter) mask() uint64 {
+	return uint64(len(f.buckets)) - 1
+}
+
+// indexAndFingerprint 返回数据对应的第一个桶下标与指纹
+func (f *CuckooFilter) indexAndFingerprint(data []byte) (uint64, fingerprint) {
+	h1, h2 := baseHashes(data)
+	fp := fingerprint(h2%0xffff + 1)
+	return h1 & f.mask(), fp
+}
+
+// altIndex 返回指纹对应的另一个桶下标
+func (f *CuckooFilter) altIndex(i uint64, fp fingerprint) uint64 {
+	h := uint64(fp) * 0x5bd1e995
+	return (i ^ h) & f.mask()
+}
+
+// Lookup 如果数据可能存在则返回 true,如果一定不存在则返回 false
+func (f *CuckooFilter) Lookup(data []byte) bool {
+	i1, fp := f.indexAndFingerprint(data)
+	i2 := f.altIndex(i1, fp)
+	return f.buckets[i1].index(fp) >= 0 || f.buckets[i2].index(fp) >= 0
+}
+
+// LookupString 如果字符串可能存在则返回 true,如果一定不存在则返回 false
+func (f *CuckooFilter) LookupString(s string) bool {
+	return f.Lookup([]byte(s))
+}
+
+// Insert 插入数据
+//
+// 如果过滤器已满则返回 false,此时过滤器保持不变.
+func (f *CuckooFilter) Insert(data []byte) bool {
+	i1, fp := f.indexAndFingerprint(data)
+	return f.insert(i1, fp)
+}
+
+// InsertString 插入字符串
+func (f *CuckooFilter) InsertString(s string) bool {
+	return f.Insert([]byte(s))
+}
+
+// InsertUnique 仅当数据不存在时插入数据
+//
+// 如果数据已存在或过滤器已满则返回 false.
+func (f *CuckooFilter) InsertUnique(data []byte) bool {
+	if f.Lookup(data) {
+		return false
+	}
+	return f.Insert(data)
+}
+
+// insert 将指纹插入到桶 i 或其备选桶中
+func (f *CuckooFilter) insert(i uint64, fp fingerprint) bool {
+	if f.buckets[i].insert(fp) {
+		f.count++
+		return true
+	}
+	i = f.altIndex(i, fp)
+	if f.buckets[i].insert(fp) {
+		f.count++
+		return true
+	}
+	// 记录踢出路径,失败时回滚以保证不丢失已有元素
+	type kick struct {
+		index uint64
+		slot  int
+	}
+	path := make([]kick, 0, maxKicks)
+	for k := 0; k < maxKicks; k++ {
+		slot := rand.Intn(bucketSize)
+		path = append(path, kick{index: i, slot: slot})
+		fp, f.buckets[i][slot] = f.buckets[i][slot], fp
+		i = f.altIndex(i, fp)
+		if f.buckets[i].insert(fp) {
+			f.count++
+			return true
+		}
+	}
+	for k := len(path) - 1; k >= 0; k-- {
+		p := path[k]
+		fp, f.buckets[p.index][p.slot] = f.buckets[p.index][p.slot], fp
+	}
+	return false
+}
+
+// Delete 删除数据
+//
+// 如果数据存在并被删除则返回 true,否则返回 false.
+func (f *CuckooFilter) Delete(data []byte) bool {
+	i1, fp := f.indexAndFingerprint(data)
+	i2 := f.altIndex(i1, fp)
+	if f.buckets[i1].delete(fp) || f.buckets[i2].delete(fp) {
+		f.count--
+		return true
+	}
+	return false
+}
+
+// DeleteString 删除字符串
+func (f *CuckooFilter) DeleteString(s string) bool {
+	return f.Delete([]byte(s))
+}
+
+// Reset 清空过滤器
+func (f *CuckooFilter) Reset() {
+	for i := range f.buckets {
+		f.buckets[i] = bucket{}
+	}
+	f.count = 0
+}
+
+// Merge 将指定过滤器中的所有元素合并到当前过滤器中
+//
+// 两个过滤器的桶数必须相同,否则返回 IncompatibleErr.
+// 如果合并过程中当前过滤器已满则返回 FullErr,已合并的元素不会回滚.
+func (f *CuckooFilter) Merge(o *CuckooFilter) error {
+	if o == nil || len(f.buckets) != len(o.buckets) {
+		return IncompatibleErr
+	}
+	for i, b := range o.buckets {
+		for _, fp := range b {
+			if fp != 0 && !f.insert(uint64(i), fp) {
+				return FullErr
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (f *CuckooFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 1+16+2*bucketSize*len(f.buckets))
+	data[0] = cuckooMagic
+	binary.BigEndian.PutUint64(data[1:], uint64(len(f.buckets)))
+	binary.BigEndian.PutUint64(data[9:], uint64(f.count))
+	p := 17
+	for _, b := range f.buckets {
+		for _, fp := range b {
+			binary.BigEndian.PutUint16(data[p:], uint16(fp))
+			p += 2
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口
+func (f *CuckooFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 17 || data[0] != cuckooMagic {
+		return InvalidDataErr
+	}
+	n := binary.BigEndian.Uint64(data[1:])
+	count := binary.BigEndian.Uint64(data[9:])
+	payload := uint64(len(data) - 17)
+	// 先用数据长度限制 n,避免计算数据长度时溢出
+	if n == 0 || n > payload/(2*bucketSize) || n&(n-1) != 0 || payload != 2*bucketSize*n || count > n*bucketSize {
+		return InvalidDataErr
+	}
+	buckets := make([]bucket, n)
+	p := 17
+	for i := range buckets {
+		for j := range buckets[i] {
+			buckets[i][j] = fingerprint(binary.BigEndian.Uint16(data[p:]))
+			p += 2
+		}
+	}
+	f.buckets, f.count = buckets, uint(count)
+	return nil
+}
+
+// index 返回指纹在桶中的槽位,不存在则返回-1
+func (b *bucket) index(fp fingerprint) int {
+	for i, x := range b {
+		if x == fp {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert 将指纹放入空槽位,没有空槽位则返回 false
+func (b *bucket) insert(fp fingerprint) bool {
+	if i := b.index(0); i >= 0 {
+		b[i] = fp
+		return true
+	}
+	return false
+}
+
+// delete 删除一个指纹,不存在则返回 false
+func (b *bucket) delete(fp fingerprint) bool {
+	if i := b.index(fp); i >= 0 {
+		b[i] = 0
+		return true
+	}
+	return false
+}
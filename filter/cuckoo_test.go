@@ -0,0 +1,117 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package filter
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	f := NewCuckooFilter(1000)
+	assert.Equal(t, uint(2048), f.Cap())
+	for i := 0; i < 900; i++ {
+		assert.True(t, f.InsertString(strconv.Itoa(i)))
+	}
+	assert.Equal(t, uint(900), f.Count())
+	for i := 0; i < 900; i++ {
+		assert.True(t, f.LookupString(strconv.Itoa(i)))
+	}
+	for i := 0; i < 450; i++ {
+		assert.True(t, f.DeleteString(strconv.Itoa(i)))
+	}
+	assert.Equal(t, uint(450), f.Count())
+	for i := 450; i < 900; i++ {
+		assert.True(t, f.LookupString(strconv.Itoa(i)))
+	}
+	assert.False(t, f.InsertUnique([]byte("500")))
+
+	f.Reset()
+	assert.Equal(t, uint(0), f.Count())
+	assert.False(t, f.DeleteString("500"))
+}
+
+func TestCuckooFilter_Full(t *testing.T) {
+	f := NewCuckooFilter(8)
+	inserted := 0
+	for i := 0; i < 100; i++ {
+		if f.InsertString(strconv.Itoa(i)) {
+			inserted++
+		}
+	}
+	assert.Equal(t, uint(inserted), f.Count())
+	assert.LessOrEqual(t, inserted, int(f.Cap()))
+	for i := 0; i < 100; i++ {
+		if f.Count() == 0 {
+			break
+		}
+		f.DeleteString(strconv.Itoa(i))
+	}
+	assert.Equal(t, uint(0), f.Count())
+}
+
+func TestNewCuckooFilter_Capacity(t *testing.T) {
+	for _, capacity := range []uint{1, 6, 100, 1000, 3890} {
+		f := NewCuckooFilter(capacity)
+		assert.True(t, f.Cap() >= capacity, capacity)
+		failed := 0
+		for i := uint(0); i < capacity; i++ {
+			if !f.InsertString(strconv.Itoa(int(i))) {
+				failed++
+			}
+		}
+		assert.Equal(t, 0, failed, capacity)
+		assert.Equal(t, capacity, f.Count())
+	}
+}
+
+func TestCuckooFilter_MergeAndMarshal(t *testing.T) {
+	f1 := NewCuckooFilter(100)
+	f2 := NewCuckooFilter(100)
+	f1.InsertString("a")
+	f2.InsertString("b")
+	assert.Nil(t, f1.Merge(f2))
+	assert.True(t, f1.LookupString("a"))
+	assert.True(t, f1.LookupString("b"))
+	assert.Equal(t, uint(2), f1.Count())
+	assert.Equal(t, IncompatibleErr, f1.Merge(NewCuckooFilter(1000)))
+	assert.True(t, errors.Is(IncompatibleErr, errs.IllegalArgument))
+	assert.True(t, errors.Is(InvalidDataErr, errs.IllegalArgument))
+	assert.True(t, errors.Is(FullErr, errs.Full))
+
+	data, err := f1.MarshalBinary()
+	assert.Nil(t, err)
+	g := &CuckooFilter{}
+	assert.Nil(t, g.UnmarshalBinary(data))
+	assert.Equal(t, f1, g)
+	assert.True(t, g.DeleteString("a"))
+	assert.False(t, g.LookupString("a"))
+
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(data[:10]))
+
+	// 桶数量过大时 2*bucketSize*n 会溢出为 0
+	bad := make([]byte, 17)
+	bad[0] = cuckooMagic
+	binary.BigEndian.PutUint64(bad[1:], 1<<62)
+	assert.Equal(t, InvalidDataErr, g.UnmarshalBinary(bad))
+}
@@ -0,0 +1,54 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package filter 概率型成员判断过滤器
+//
+// 提供布隆过滤器(BloomFilter)与布谷鸟过滤器(CuckooFilter),
+// 二者均支持二进制序列化,可持久化并在不同进程间合并.
+package filter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/chenquan/go-util/errs"
+)
+
+var (
+	IncompatibleErr = fmt.Errorf("incompatible filter: %w", errs.IllegalArgument)
+	InvalidDataErr  = fmt.Errorf("invalid filter data: %w", errs.IllegalArgument)
+	FullErr         = fmt.Errorf("filter is full: %w", errs.Full)
+)
+
+// baseHashes 计算数据的两个64位基础哈希值
+func baseHashes(data []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	_, _ = h.Write(data)
+	sum := h.Sum(nil)
+	return mix(binary.BigEndian.Uint64(sum[0:8])), mix(binary.BigEndian.Uint64(sum[8:16]))
+}
+
+// mix 对哈希值做雪崩处理,改善短数据的哈希分布
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -17,8 +17,106 @@
 
 package function
 
+import (
+	"fmt"
+	"time"
+)
+
 // Comparator 比较器函数
-type Comparator func(o1, o2 interface{})
+//
+// 当 o1 小于、等于或大于 o2 时,分别返回负整数、零或正整数.
+type Comparator func(o1, o2 interface{}) int
+
+// NaturalOrder 按自然顺序比较两个相同类型的值
+//
+// 支持所有整数、浮点数、字符串以及 time.Time 类型,
+// 如果两个值类型不同或类型不受支持则引发 panic.
+func NaturalOrder(o1, o2 interface{}) int {
+	switch v1 := o1.(type) {
+	case int:
+		return compareInt64(int64(v1), int64(o2.(int)))
+	case int8:
+		return compareInt64(int64(v1), int64(o2.(int8)))
+	case int16:
+		return compareInt64(int64(v1), int64(o2.(int16)))
+	case int32:
+		return compareInt64(int64(v1), int64(o2.(int32)))
+	case int64:
+		return compareInt64(v1, o2.(int64))
+	case uint:
+		return compareUint64(uint64(v1), uint64(o2.(uint)))
+	case uint8:
+		return compareUint64(uint64(v1), uint64(o2.(uint8)))
+	case uint16:
+		return compareUint64(uint64(v1), uint64(o2.(uint16)))
+	case uint32:
+		return compareUint64(uint64(v1), uint64(o2.(uint32)))
+	case uint64:
+		return compareUint64(v1, o2.(uint64))
+	case uintptr:
+		return compareUint64(uint64(v1), uint64(o2.(uintptr)))
+	case float32:
+		return compareFloat64(float64(v1), float64(o2.(float32)))
+	case float64:
+		return compareFloat64(v1, o2.(float64))
+	case string:
+		v2 := o2.(string)
+		if v1 < v2 {
+			return -1
+		}
+		if v1 > v2 {
+			return 1
+		}
+		return 0
+	case time.Time:
+		v2 := o2.(time.Time)
+		if v1.Before(v2) {
+			return -1
+		}
+		if v1.After(v2) {
+			return 1
+		}
+		return 0
+	}
+	panic(fmt.Sprintf("function: type %T is not naturally ordered", o1))
+}
+
+// Reversed 返回与 c 顺序相反的比较器
+func (c Comparator) Reversed() Comparator {
+	return func(o1, o2 interface{}) int {
+		return c(o2, o1)
+	}
+}
+
+func compareInt64(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
 
 // BiConsumer
 type BiConsumer func(first interface{}, second interface{})
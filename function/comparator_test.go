@@ -20,8 +20,24 @@ package function
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
+func TestNaturalOrder(t *testing.T) {
+	assert.Equal(t, -1, NaturalOrder(1, 2))
+	assert.Equal(t, 0, NaturalOrder(int8(2), int8(2)))
+	assert.Equal(t, 1, NaturalOrder(uint64(3), uint64(2)))
+	assert.Equal(t, -1, NaturalOrder(1.5, 2.5))
+	assert.Equal(t, 1, NaturalOrder("b", "a"))
+	now := time.Now()
+	assert.Equal(t, -1, NaturalOrder(now, now.Add(time.Second)))
+	assert.Panics(t, func() { NaturalOrder(1, "1") })
+	assert.Panics(t, func() { NaturalOrder(struct{}{}, struct{}{}) })
+
+	var c Comparator = NaturalOrder
+	assert.Equal(t, 1, c.Reversed()(1, 2))
+}
+
 func TestBiConsumerFunc_Accept(t *testing.T) {
 	var i = 0
 	var f BiConsumer = func(first interface{}, second interface{}) {
@@ -0,0 +1,149 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package function
+
+import "sync"
+
+// Consumer 接受一个参数且没有返回值的操作
+type Consumer func(t interface{})
+
+// AndThen 返回先执行 c 再执行 after 的操作
+func (c Consumer) AndThen(after Consumer) Consumer {
+	return func(t interface{}) {
+		c(t)
+		after(t)
+	}
+}
+
+// AndThen 返回先执行 c 再执行 after 的操作
+func (c BiConsumer) AndThen(after BiConsumer) BiConsumer {
+	return func(first interface{}, second interface{}) {
+		c(first, second)
+		after(first, second)
+	}
+}
+
+// Predicate 接受一个参数并返回布尔值的断言
+type Predicate func(t interface{}) bool
+
+// And 返回 p 与 other 的短路逻辑与
+func (p Predicate) And(other Predicate) Predicate {
+	return func(t interface{}) bool {
+		return p(t) && other(t)
+	}
+}
+
+// Or 返回 p 与 other 的短路逻辑或
+func (p Predicate) Or(other Predicate) Predicate {
+	return func(t interface{}) bool {
+		return p(t) || other(t)
+	}
+}
+
+// Negate 返回 p 的逻辑非
+func (p Predicate) Negate() Predicate {
+	return func(t interface{}) bool {
+		return !p(t)
+	}
+}
+
+// IsEqual 返回判断参数是否等于 target 的断言
+func IsEqual(target interface{}) Predicate {
+	return func(t interface{}) bool {
+		return t == target
+	}
+}
+
+// Function 接受一个参数并返回结果的函数
+type Function func(t interface{}) interface{}
+
+// Compose 返回先执行 before 再将其结果交给 f 的函数
+func (f Function) Compose(before Function) Function {
+	return func(t interface{}) interface{} {
+		return f(before(t))
+	}
+}
+
+// AndThen 返回先执行 f 再将其结果交给 after 的函数
+func (f Function) AndThen(after Function) Function {
+	return func(t interface{}) interface{} {
+		return after(f(t))
+	}
+}
+
+// Identity 返回总是返回参数本身的函数
+func Identity() Function {
+	return func(t interface{}) interface{} {
+		return t
+	}
+}
+
+// BiFunction 接受两个参数并返回结果的函数
+type BiFunction func(t, u interface{}) interface{}
+
+// AndThen 返回先执行 f 再将其结果交给 after 的函数
+func (f BiFunction) AndThen(after Function) BiFunction {
+	return func(t, u interface{}) interface{} {
+		return after(f(t, u))
+	}
+}
+
+// UnaryOperator 接受一个参数并返回与参数类型相同的结果的操作
+type UnaryOperator func(t interface{}) interface{}
+
+// BinaryOperator 接受两个相同类型的参数并返回与参数类型相同的结果的操作
+type BinaryOperator func(t1, t2 interface{}) interface{}
+
+// MinBy 返回按 c 比较并返回较小参数的操作,相等时返回第一个参数
+func MinBy(c Comparator) BinaryOperator {
+	return func(t1, t2 interface{}) interface{} {
+		if c(t1, t2) <= 0 {
+			return t1
+		}
+		return t2
+	}
+}
+
+// MaxBy 返回按 c 比较并返回较大参数的操作,相等时返回第一个参数
+func MaxBy(c Comparator) BinaryOperator {
+	return func(t1, t2 interface{}) interface{} {
+		if c(t1, t2) >= 0 {
+			return t1
+		}
+		return t2
+	}
+}
+
+// Supplier 提供结果的函数
+type Supplier func() interface{}
+
+// Memoize 返回只在第一次调用时执行 s 并缓存其结果的 Supplier
+//
+// 返回的 Supplier 协程安全,s 只会被执行一次.
+func (s Supplier) Memoize() Supplier {
+	var (
+		once  sync.Once
+		value interface{}
+	)
+	return func() interface{} {
+		once.Do(func() {
+			value = s()
+		})
+		return value
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package function
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumer_AndThen(t *testing.T) {
+	var got []interface{}
+	var c Consumer = func(t interface{}) { got = append(got, t) }
+	c.AndThen(func(t interface{}) { got = append(got, t.(int)*10) })(1)
+	assert.Equal(t, []interface{}{1, 10}, got)
+
+	var pairs []interface{}
+	var b BiConsumer = func(first, second interface{}) { pairs = append(pairs, first) }
+	b.AndThen(func(first, second interface{}) { pairs = append(pairs, second) })("a", "b")
+	assert.Equal(t, []interface{}{"a", "b"}, pairs)
+}
+
+func TestPredicate(t *testing.T) {
+	var positive Predicate = func(t interface{}) bool { return t.(int) > 0 }
+	var even Predicate = func(t interface{}) bool { return t.(int)%2 == 0 }
+
+	assert.True(t, positive.And(even)(2))
+	assert.False(t, positive.And(even)(1))
+	assert.True(t, positive.Or(even)(-2))
+	assert.False(t, positive.Or(even)(-1))
+	assert.True(t, positive.Negate()(-1))
+	assert.False(t, positive.Negate()(1))
+
+	calls := 0
+	var counted Predicate = func(t interface{}) bool { calls++; return true }
+	positive.And(counted)(-1)
+	positive.Or(counted)(1)
+	assert.Equal(t, 0, calls)
+
+	assert.True(t, IsEqual("a")("a"))
+	assert.False(t, IsEqual("a")("b"))
+}
+
+func TestFunction(t *testing.T) {
+	var inc Function = func(t interface{}) interface{} { return t.(int) + 1 }
+	var double Function = func(t interface{}) interface{} { return t.(int) * 2 }
+
+	assert.Equal(t, 4, inc.AndThen(double)(1))
+	assert.Equal(t, 3, inc.Compose(double)(1))
+	assert.Equal(t, "x", Identity()("x"))
+	assert.Equal(t, 2, Identity().AndThen(inc)(1))
+}
+
+func TestBiFunction_AndThen(t *testing.T) {
+	var add BiFunction = func(t, u interface{}) interface{} { return t.(int) + u.(int) }
+	var double Function = func(t interface{}) interface{} { return t.(int) * 2 }
+	assert.Equal(t, 10, add.AndThen(double)(2, 3))
+}
+
+func TestMinByMaxBy(t *testing.T) {
+	assert.Equal(t, 1, MinBy(NaturalOrder)(1, 2))
+	assert.Equal(t, 1, MinBy(NaturalOrder)(2, 1))
+	assert.Equal(t, 2, MaxBy(NaturalOrder)(1, 2))
+	assert.Equal(t, 2, MaxBy(NaturalOrder)(2, 1))
+	assert.Equal(t, 2, MinBy(Comparator(NaturalOrder).Reversed())(1, 2))
+
+	type item struct {
+		key  int
+		name string
+	}
+	byKey := func(o1, o2 interface{}) int { return NaturalOrder(o1.(item).key, o2.(item).key) }
+	a, b := item{1, "a"}, item{1, "b"}
+	assert.Equal(t, a, MinBy(byKey)(a, b))
+	assert.Equal(t, a, MaxBy(byKey)(a, b))
+}
+
+func TestSupplier_Memoize(t *testing.T) {
+	calls := 0
+	var s Supplier = func() interface{} { calls++; return calls }
+	m := s.Memoize()
+	assert.Equal(t, 1, m())
+	assert.Equal(t, 1, m())
+	assert.Equal(t, 1, calls)
+
+	var mu sync.Mutex
+	n := 0
+	var slow Supplier = func() interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return n
+	}
+	m = slow.Memoize()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 1, m())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,135 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package function
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/errs"
+)
+
+// Optional 可能包含也可能不包含非 nil 值的容器
+//
+// 零值为空的 Optional.
+type Optional struct {
+	value interface{}
+}
+
+// EmptyOptional 返回空的 Optional
+func EmptyOptional() Optional {
+	return Optional{}
+}
+
+// OptionalOf 返回包含 value 的 Optional,如果 value 为 nil 则返回空的 Optional
+func OptionalOf(value interface{}) Optional {
+	return Optional{value: value}
+}
+
+// IsPresent 如果包含值则返回 true,否则返回 false
+func (o Optional) IsPresent() bool {
+	return o.value != nil
+}
+
+// IsEmpty 如果不包含值则返回 true,否则返回 false
+func (o Optional) IsEmpty() bool {
+	return o.value == nil
+}
+
+// Get 返回包含的值
+//
+// 如果不包含值则返回 errs.NoSuchElement.
+func (o Optional) Get() (interface{}, error) {
+	if o.IsEmpty() {
+		return nil, errs.NewError(errs.NoSuchElement, "Optional.Get")
+	}
+	return o.value, nil
+}
+
+// IfPresent 如果包含值则对其执行 action
+func (o Optional) IfPresent(action Consumer) {
+	if o.IsPresent() {
+		action(o.value)
+	}
+}
+
+// IfPresentOrElse 如果包含值则对其执行 action,否则执行 emptyAction
+func (o Optional) IfPresentOrElse(action Consumer, emptyAction func()) {
+	if o.IsPresent() {
+		action(o.value)
+	} else {
+		emptyAction()
+	}
+}
+
+// Filter 如果包含值且满足 p 则返回 o 本身,否则返回空的 Optional
+func (o Optional) Filter(p Predicate) Optional {
+	if o.IsPresent() && p(o.value) {
+		return o
+	}
+	return Optional{}
+}
+
+// Map 如果包含值则返回包含 f 的结果的 Optional,否则返回空的 Optional
+//
+// f 返回 nil 时结果为空的 Optional.
+func (o Optional) Map(f Function) Optional {
+	if o.IsEmpty() {
+		return o
+	}
+	return OptionalOf(f(o.value))
+}
+
+// FlatMap 如果包含值则返回 f 的结果,否则返回空的 Optional
+func (o Optional) FlatMap(f func(t interface{}) Optional) Optional {
+	if o.IsEmpty() {
+		return o
+	}
+	return f(o.value)
+}
+
+// Or 如果包含值则返回 o 本身,否则返回 supplier 提供的 Optional
+func (o Optional) Or(supplier func() Optional) Optional {
+	if o.IsPresent() {
+		return o
+	}
+	return supplier()
+}
+
+// OrElse 如果包含值则返回该值,否则返回 other
+func (o Optional) OrElse(other interface{}) interface{} {
+	if o.IsPresent() {
+		return o.value
+	}
+	return other
+}
+
+// OrElseGet 如果包含值则返回该值,否则返回 supplier 提供的值
+func (o Optional) OrElseGet(supplier Supplier) interface{} {
+	if o.IsPresent() {
+		return o.value
+	}
+	return supplier()
+}
+
+// String 返回 Optional 的字符串表示,形如 Optional[1] 或 Optional.empty
+func (o Optional) String() string {
+	if o.IsEmpty() {
+		return "Optional.empty"
+	}
+	return fmt.Sprintf("Optional[%v]", o.value)
+}
@@ -0,0 +1,100 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package function
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptional(t *testing.T) {
+	o := OptionalOf(1)
+	assert.True(t, o.IsPresent())
+	assert.False(t, o.IsEmpty())
+	v, err := o.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, "Optional[1]", o.String())
+
+	for _, empty := range []Optional{EmptyOptional(), OptionalOf(nil), {}} {
+		assert.False(t, empty.IsPresent())
+		assert.True(t, empty.IsEmpty())
+		_, err = empty.Get()
+		assert.True(t, errors.Is(err, errs.NoSuchElement))
+		assert.Equal(t, "Optional.empty", empty.String())
+	}
+}
+
+func TestOptional_IfPresent(t *testing.T) {
+	var got interface{}
+	OptionalOf("a").IfPresent(func(t interface{}) { got = t })
+	assert.Equal(t, "a", got)
+
+	got = nil
+	EmptyOptional().IfPresent(func(t interface{}) { got = t })
+	assert.Nil(t, got)
+
+	emptyCalled := false
+	OptionalOf("b").IfPresentOrElse(func(t interface{}) { got = t }, func() { emptyCalled = true })
+	assert.Equal(t, "b", got)
+	assert.False(t, emptyCalled)
+	EmptyOptional().IfPresentOrElse(func(t interface{}) { got = t }, func() { emptyCalled = true })
+	assert.True(t, emptyCalled)
+}
+
+func TestOptional_MapFilter(t *testing.T) {
+	var double Function = func(t interface{}) interface{} { return t.(int) * 2 }
+	var even Predicate = func(t interface{}) bool { return t.(int)%2 == 0 }
+
+	assert.Equal(t, 4, OptionalOf(2).Map(double).OrElse(0))
+	assert.True(t, EmptyOptional().Map(double).IsEmpty())
+	assert.True(t, OptionalOf(2).Map(func(t interface{}) interface{} { return nil }).IsEmpty())
+
+	assert.Equal(t, 2, OptionalOf(2).Filter(even).OrElse(0))
+	assert.True(t, OptionalOf(1).Filter(even).IsEmpty())
+	assert.True(t, EmptyOptional().Filter(even).IsEmpty())
+
+	half := func(t interface{}) Optional {
+		if t.(int)%2 != 0 {
+			return EmptyOptional()
+		}
+		return OptionalOf(t.(int) / 2)
+	}
+	assert.Equal(t, 2, OptionalOf(4).FlatMap(half).OrElse(0))
+	assert.True(t, OptionalOf(3).FlatMap(half).IsEmpty())
+	assert.True(t, EmptyOptional().FlatMap(half).IsEmpty())
+}
+
+func TestOptional_OrElse(t *testing.T) {
+	calls := 0
+	var supplier Supplier = func() interface{} { calls++; return "default" }
+
+	assert.Equal(t, "a", OptionalOf("a").OrElse("default"))
+	assert.Equal(t, "default", EmptyOptional().OrElse("default"))
+	assert.Equal(t, "a", OptionalOf("a").OrElseGet(supplier))
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "default", EmptyOptional().OrElseGet(supplier))
+	assert.Equal(t, 1, calls)
+
+	other := func() Optional { return OptionalOf("b") }
+	assert.Equal(t, "a", OptionalOf("a").Or(other).OrElse(nil))
+	assert.Equal(t, "b", EmptyOptional().Or(other).OrElse(nil))
+}
@@ -0,0 +1,353 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/queue"
+	"github.com/chenquan/go-util/set"
+)
+
+var (
+	CycleErr          = fmt.Errorf("graph contains a cycle: %w", errs.IllegalState)
+	NegativeWeightErr = fmt.Errorf("graph contains a negative weight edge: %w", errs.IllegalState)
+)
+
+// CycleError 图中存在环
+//
+// errors.Is(err, CycleErr) 对 CycleError 返回 true.
+type CycleError struct {
+	// Cycle 环上的顶点,从最后一个顶点有边回到第一个顶点
+	Cycle []collection.Element
+}
+
+// Error 实现 error 接口,形如 graph contains a cycle: a -> b -> a
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	b.WriteString("graph contains a cycle: ")
+	for _, v := range e.Cycle {
+		_, _ = fmt.Fprintf(&b, "%v -> ", v)
+	}
+	if len(e.Cycle) > 0 {
+		_, _ = fmt.Fprintf(&b, "%v", e.Cycle[0])
+	}
+	return b.String()
+}
+
+// Unwrap 返回 CycleErr
+func (e *CycleError) Unwrap() error {
+	return CycleErr
+}
+
+// TopologicalSort 返回有向图顶点的拓扑排序
+//
+// 入度为0的顶点按加入图的顺序输出.
+// 如果图中存在环则返回 *CycleError,如果是无向图则返回 UndirectedErr.
+func (g *Graph) TopologicalSort() ([]collection.Element, error) {
+	if !g.directed {
+		return nil, UndirectedErr
+	}
+	inDegree := make(map[interface{}]int, len(g.order))
+	ready := queue.NewSliceDeQueue()
+	for _, v := range g.order {
+		inDegree[v] = len(g.in[v])
+		if inDegree[v] == 0 {
+			_, _ = ready.Offer(v)
+		}
+	}
+	sorted := make([]collection.Element, 0, len(g.order))
+	for !ready.IsEmpty() {
+		v := ready.Poll()
+		sorted = append(sorted, v)
+		for _, w := range g.out[v] {
+			inDegree[w]--
+			if inDegree[w] == 0 {
+				_, _ = ready.Offer(w)
+			}
+		}
+	}
+	if len(sorted) == len(g.order) {
+		return sorted, nil
+	}
+	return nil, &CycleError{Cycle: g.findCycle(inDegree)}
+}
+
+// findCycle 在拓扑排序剩余的顶点中查找一个环
+//
+// 剩余的每个顶点都至少有一个剩余的前驱,沿前驱回溯必然会重复访问某个顶点.
+func (g *Graph) findCycle(inDegree map[interface{}]int) []collection.Element {
+	var v interface{}
+	for _, u := range g.order {
+		if inDegree[u] > 0 {
+			v = u
+			break
+		}
+	}
+	position := make(map[interface{}]int)
+	var path []collection.Element
+	for {
+		if i, ok := position[v]; ok {
+			path = path[i:]
+			break
+		}
+		position[v] = len(path)
+		path = append(path, v)
+		for _, u := range g.in[v] {
+			if inDegree[u] > 0 {
+				v = u
+				break
+			}
+		}
+	}
+	// 回溯得到的是逆序的环
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Paths 单源最短路径
+type Paths struct {
+	source    interface{}
+	distances map[interface{}]float64
+	previous  map[interface{}]interface{}
+}
+
+// Source 返回源顶点
+func (p *Paths) Source() interface{} {
+	return p.source
+}
+
+// HasPathTo 如果源顶点可到达顶点 v 则返回 true,否则返回 false
+func (p *Paths) HasPathTo(v interface{}) bool {
+	_, ok := p.distances[v]
+	return ok
+}
+
+// DistanceTo 返回源顶点到顶点 v 的最短距离
+//
+// 如果不可到达则第二个返回值为 false.
+func (p *Paths) DistanceTo(v interface{}) (float64, bool) {
+	d, ok := p.distances[v]
+	return d, ok
+}
+
+// PathTo 返回从源顶点到顶点 v 的最短路径上的顶点,不可到达时返回 nil
+func (p *Paths) PathTo(v interface{}) []collection.Element {
+	if !p.HasPathTo(v) {
+		return nil
+	}
+	var path []collection.Element
+	for ; v != p.source; v = p.previous[v] {
+		path = append(path, v)
+	}
+	path = append(path, p.source)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// candidate 优先级队列中的候选顶点或边
+type candidate struct {
+	from     interface{}
+	vertex   interface{}
+	priority float64
+}
+
+// candidateQueue 按优先级从小到大出队的候选队列,实现 heap.Interface
+type candidateQueue []*candidate
+
+// Len 返回候选个数
+func (q candidateQueue) Len() int {
+	return len(q)
+}
+
+// Less 如果下标 i 的候选优先级小于下标 j 的候选则返回 true
+func (q candidateQueue) Less(i, j int) bool {
+	return q[i].priority < q[j].priority
+}
+
+// Swap 交换下标 i 与下标 j 的候选
+func (q candidateQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+// Push 在末尾追加候选,由 heap.Push 调用
+func (q *candidateQueue) Push(x interface{}) {
+	*q = append(*q, x.(*candidate))
+}
+
+// Pop 删除并返回末尾的候选,由 heap.Pop 调用
+func (q *candidateQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return c
+}
+
+// ShortestPaths 使用 Dijkstra 算法计算从顶点 source 出发的单源最短路径
+//
+// 如果顶点不存在则返回 VertexNotFoundErr,如果存在负权边则返回 NegativeWeightErr.
+func (g *Graph) ShortestPaths(source interface{}) (*Paths, error) {
+	if !g.ContainsVertex(source) {
+		return nil, VertexNotFoundErr
+	}
+	for _, weight := range g.weights {
+		if weight < 0 {
+			return nil, NegativeWeightErr
+		}
+	}
+	paths := &Paths{
+		source:    source,
+		distances: make(map[interface{}]float64),
+		previous:  make(map[interface{}]interface{}),
+	}
+	settled := make(map[interface{}]bool)
+	pq := &candidateQueue{}
+	paths.distances[source] = 0
+	heap.Push(pq, &candidate{vertex: source})
+	for pq.Len() > 0 {
+		c := heap.Pop(pq).(*candidate)
+		if settled[c.vertex] {
+			continue
+		}
+		settled[c.vertex] = true
+		for _, w := range g.out[c.vertex] {
+			d := c.priority + g.weights[arc{c.vertex, w}]
+			if old, ok := paths.distances[w]; !settled[w] && (!ok || d < old) {
+				paths.distances[w] = d
+				paths.previous[w] = c.vertex
+				heap.Push(pq, &candidate{vertex: w, priority: d})
+			}
+		}
+	}
+	return paths, nil
+}
+
+// StronglyConnectedComponents 使用 Tarjan 算法返回图的强连通分量
+//
+// 有向图的分量按逆拓扑顺序返回,即不存在从前面的分量到后面的分量的边.
+// 无向图的强连通分量即连通分量.
+func (g *Graph) StronglyConnectedComponents() []collection.Set {
+	t := &tarjan{
+		graph:   g,
+		index:   make(map[interface{}]int),
+		lowLink: make(map[interface{}]int),
+		onStack: make(map[interface{}]bool),
+	}
+	for _, v := range g.order {
+		if _, ok := t.index[v]; !ok {
+			t.strongConnect(v)
+		}
+	}
+	return t.components
+}
+
+// tarjan Tarjan 强连通分量算法的状态
+type tarjan struct {
+	graph      *Graph
+	counter    int
+	index      map[interface{}]int
+	lowLink    map[interface{}]int
+	stack      []collection.Element
+	onStack    map[interface{}]bool
+	components []collection.Set
+}
+
+// strongConnect 从顶点 v 开始深度优先搜索
+func (t *tarjan) strongConnect(v interface{}) {
+	t.index[v] = t.counter
+	t.lowLink[v] = t.counter
+	t.counter++
+	t.stack = append(t.stack, v)
+	t.onStack[v] = true
+	for _, w := range t.graph.out[v] {
+		if _, ok := t.index[w]; !ok {
+			t.strongConnect(w)
+			t.lowLink[v] = min(t.lowLink[v], t.lowLink[w])
+		} else if t.onStack[w] {
+			t.lowLink[v] = min(t.lowLink[v], t.index[w])
+		}
+	}
+	if t.lowLink[v] != t.index[v] {
+		return
+	}
+	component := set.NewHashSet()
+	for {
+		w := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[w] = false
+		_, _ = component.Add(w)
+		if w == v {
+			break
+		}
+	}
+	t.components = append(t.components, component)
+}
+
+// MinimumSpanningTree 使用 Prim 算法返回无向图的最小生成树
+//
+// 返回包含所有顶点的新图,图不连通时返回最小生成森林.
+// 如果是有向图则返回 DirectedErr.
+func (g *Graph) MinimumSpanningTree() (*Graph, error) {
+	if g.directed {
+		return nil, DirectedErr
+	}
+	tree := NewUndirected()
+	pq := &candidateQueue{}
+	for _, root := range g.order {
+		if tree.ContainsVertex(root) {
+			continue
+		}
+		_, _ = tree.AddVertex(root)
+		g.offerEdges(pq, tree, root)
+		for pq.Len() > 0 {
+			c := heap.Pop(pq).(*candidate)
+			if tree.ContainsVertex(c.vertex) {
+				continue
+			}
+			e := g.edge(c.from, c.vertex, c.priority)
+			_ = tree.AddEdge(e.From, e.To, e.Weight)
+			g.offerEdges(pq, tree, c.vertex)
+		}
+	}
+	return tree, nil
+}
+
+// offerEdges 将顶点 v 到生成树以外顶点的边加入优先级队列
+func (g *Graph) offerEdges(pq *candidateQueue, tree *Graph, v interface{}) {
+	for _, w := range g.out[v] {
+		if !tree.ContainsVertex(w) {
+			heap.Push(pq, &candidate{from: v, vertex: w, priority: g.weights[arc{v, w}]})
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,127 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_TopologicalSort(t *testing.T) {
+	g := NewDirected()
+	_ = g.AddEdge("app", "lib", 1)
+	_ = g.AddEdge("app", "log", 1)
+	_ = g.AddEdge("lib", "core", 1)
+	_ = g.AddEdge("log", "core", 1)
+	_, _ = g.AddVertex("tool")
+	sorted, err := g.TopologicalSort()
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{"app", "tool", "lib", "log", "core"}, sorted)
+
+	_ = g.AddEdge("core", "x", 1)
+	_ = g.AddEdge("x", "lib", 1)
+	_, err = g.TopologicalSort()
+	assert.True(t, errors.Is(err, CycleErr))
+	assert.True(t, errors.Is(err, errs.IllegalState))
+	var cycleErr *CycleError
+	assert.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, []collection.Element{"core", "x", "lib"}, cycleErr.Cycle)
+	assert.Equal(t, "graph contains a cycle: core -> x -> lib -> core", err.Error())
+
+	self := NewDirected()
+	_ = self.AddEdge(1, 1, 1)
+	_, err = self.TopologicalSort()
+	assert.Equal(t, []collection.Element{1}, err.(*CycleError).Cycle)
+
+	_, err = NewUndirected().TopologicalSort()
+	assert.Equal(t, UndirectedErr, err)
+}
+
+func TestGraph_ShortestPaths(t *testing.T) {
+	g := NewDirected()
+	_ = g.AddEdge("s", "a", 7)
+	_ = g.AddEdge("s", "b", 2)
+	_ = g.AddEdge("b", "a", 3)
+	_ = g.AddEdge("a", "t", 1)
+	_ = g.AddEdge("b", "t", 8)
+	_, _ = g.AddVertex("x")
+	paths, err := g.ShortestPaths("s")
+	assert.Nil(t, err)
+	assert.Equal(t, "s", paths.Source())
+	d, ok := paths.DistanceTo("t")
+	assert.True(t, ok)
+	assert.Equal(t, 6.0, d)
+	assert.Equal(t, []collection.Element{"s", "b", "a", "t"}, paths.PathTo("t"))
+	assert.Equal(t, []collection.Element{"s"}, paths.PathTo("s"))
+	assert.False(t, paths.HasPathTo("x"))
+	assert.Nil(t, paths.PathTo("x"))
+
+	_, err = g.ShortestPaths("y")
+	assert.Equal(t, VertexNotFoundErr, err)
+	_ = g.AddEdge("x", "s", -1)
+	_, err = g.ShortestPaths("s")
+	assert.Equal(t, NegativeWeightErr, err)
+
+	u := tree()
+	paths, _ = u.ShortestPaths(4)
+	assert.Equal(t, []collection.Element{4, 2, 5, 6}, paths.PathTo(6))
+}
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	g := NewDirected()
+	_ = g.AddEdge(1, 2, 1)
+	_ = g.AddEdge(2, 3, 1)
+	_ = g.AddEdge(3, 1, 1)
+	_ = g.AddEdge(3, 4, 1)
+	_ = g.AddEdge(4, 5, 1)
+	_ = g.AddEdge(5, 4, 1)
+	_, _ = g.AddVertex(6)
+	components := g.StronglyConnectedComponents()
+	assert.Len(t, components, 3)
+	assert.True(t, set.NewHashSetWithElements(4, 5).Equals(components[0]))
+	assert.True(t, set.NewHashSetWithElements(1, 2, 3).Equals(components[1]))
+	assert.True(t, set.NewHashSetWithElements(6).Equals(components[2]))
+
+	components = tree().StronglyConnectedComponents()
+	assert.Len(t, components, 2)
+	assert.True(t, set.NewHashSetWithElements(1, 2, 3, 4, 5, 6).Equals(components[0]))
+}
+
+func TestGraph_MinimumSpanningTree(t *testing.T) {
+	g := NewUndirected()
+	_ = g.AddEdge("a", "b", 4)
+	_ = g.AddEdge("a", "c", 1)
+	_ = g.AddEdge("c", "b", 2)
+	_ = g.AddEdge("b", "d", 5)
+	_ = g.AddEdge("c", "d", 8)
+	_ = g.AddEdge("x", "y", 3)
+	mst, err := g.MinimumSpanningTree()
+	assert.Nil(t, err)
+	assert.Equal(t, g.Order(), mst.Order())
+	assert.True(t, set.NewHashSetWithElements(
+		Edge{"a", "c", 1}, Edge{"c", "b", 2}, Edge{"b", "d", 5}, Edge{"x", "y", 3},
+	).Equals(mst.Edges()))
+
+	_, err = NewDirected().MinimumSpanningTree()
+	assert.Equal(t, DirectedErr, err)
+}
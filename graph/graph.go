@@ -0,0 +1,301 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package graph 带权图及其算法
+//
+// 提供有向图与无向图,顶点集与边集均为 collection.Set,
+// 并提供广度优先与深度优先遍历、拓扑排序、Dijkstra 最短路径、
+// 强连通分量与最小生成树等算法.
+package graph
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/set"
+)
+
+var (
+	VertexNotFoundErr = fmt.Errorf("vertex not found: %w", errs.NoSuchElement)
+	UndirectedErr     = fmt.Errorf("graph is undirected: %w", errs.UnsupportedOperation)
+	DirectedErr       = fmt.Errorf("graph is directed: %w", errs.UnsupportedOperation)
+	UnsupportedErr    = errs.UnsupportedOperation
+)
+
+// Edge 带权边
+//
+// 无向图中的边只保存一次,From 与 To 为边加入时的顺序.
+type Edge struct {
+	From   interface{}
+	To     interface{}
+	Weight float64
+}
+
+// String 返回边的字符串表示,形如 a -(1)-> b
+func (e Edge) String() string {
+	return fmt.Sprintf("%v -(%v)-> %v", e.From, e.Weight, e.To)
+}
+
+// arc 有向的顶点对
+type arc struct {
+	from interface{}
+	to   interface{}
+}
+
+// Graph 带权图
+//
+// 顶点必须是可比较的(可作为 map 的键)且不能为 nil.
+// 两个顶点之间最多存在一条边(无向图中不区分方向),允许自环.
+// 邻接顶点按边加入的顺序遍历,因此遍历与算法的结果是确定的.
+// 注意 Graph 协程不安全,不能用于高并发.
+type Graph struct {
+	directed bool
+	vertices *set.HashSet                         // 顶点集
+	edges    *set.HashSet                         // 边集,元素为 Edge
+	order    []collection.Element                 // 按加入顺序排列的顶点
+	out      map[interface{}][]collection.Element // 顶点的后继,无向图中为邻接顶点
+	in       map[interface{}][]collection.Element // 有向图中顶点的前驱
+	weights  map[arc]float64                      // 边的权重,无向图中两个方向均保存
+}
+
+// NewDirected 创建有向图
+func NewDirected() *Graph {
+	return newGraph(true)
+}
+
+// NewUndirected 创建无向图
+func NewUndirected() *Graph {
+	return newGraph(false)
+}
+
+// newGraph 创建图
+func newGraph(directed bool) *Graph {
+	return &Graph{
+		directed: directed,
+		vertices: set.NewHashSet(),
+		edges:    set.NewHashSet(),
+		out:      make(map[interface{}][]collection.Element),
+		in:       make(map[interface{}][]collection.Element),
+		weights:  make(map[arc]float64),
+	}
+}
+
+// IsDirected 如果是有向图则返回 true,否则返回 false
+func (g *Graph) IsDirected() bool {
+	return g.directed
+}
+
+// Order 返回顶点数
+func (g *Graph) Order() int {
+	return len(g.order)
+}
+
+// Size 返回边数
+func (g *Graph) Size() int {
+	return g.edges.Size()
+}
+
+// Vertices 返回顶点集的副本
+func (g *Graph) Vertices() collection.Set {
+	return set.NewHashSetWithCollection(g.vertices)
+}
+
+// Edges 返回边集的副本,元素为 Edge
+func (g *Graph) Edges() collection.Set {
+	return set.NewHashSetWithCollection(g.edges)
+}
+
+// ContainsVertex 如果图包含顶点 v 则返回 true,否则返回 false
+func (g *Graph) ContainsVertex(v interface{}) bool {
+	_, ok := g.out[v]
+	return ok
+}
+
+// AddVertex 添加顶点 v
+//
+// 如果顶点不存在则返回 true,否则返回 false.
+// 如果 v 为 nil 则返回 errs.NilPointer.
+func (g *Graph) AddVertex(v interface{}) (bool, error) {
+	if v == nil {
+		return false, errs.NilPointer
+	}
+	if g.ContainsVertex(v) {
+		return false, nil
+	}
+	_, _ = g.vertices.Add(v)
+	g.order = append(g.order, v)
+	g.out[v] = nil
+	if g.directed {
+		g.in[v] = nil
+	}
+	return true, nil
+}
+
+// RemoveVertex 删除顶点 v 及与其关联的所有边
+//
+// 如果顶点存在则返回 true,否则返回 false.
+func (g *Graph) RemoveVertex(v interface{}) bool {
+	if !g.ContainsVertex(v) {
+		return false
+	}
+	for _, w := range append([]collection.Element(nil), g.out[v]...) {
+		g.RemoveEdge(v, w)
+	}
+	if g.directed {
+		for _, u := range append([]collection.Element(nil), g.in[v]...) {
+			g.RemoveEdge(u, v)
+		}
+		delete(g.in, v)
+	}
+	delete(g.out, v)
+	_, _ = g.vertices.Remove(v)
+	g.order = without(g.order, v)
+	return true
+}
+
+// AddEdge 添加从 from 到 to 权重为 weight 的边,不存在的顶点会被自动添加
+//
+// 如果边已存在则替换其权重.
+// 如果 from 或 to 为 nil 则返回 errs.NilPointer.
+func (g *Graph) AddEdge(from, to interface{}, weight float64) error {
+	if from == nil || to == nil {
+		return errs.NilPointer
+	}
+	_, _ = g.AddVertex(from)
+	_, _ = g.AddVertex(to)
+	if old, ok := g.weights[arc{from, to}]; ok {
+		e := g.edge(from, to, old)
+		_, _ = g.edges.Remove(e)
+		e.Weight = weight
+		_, _ = g.edges.Add(e)
+		g.setWeight(from, to, weight)
+		return nil
+	}
+	_, _ = g.edges.Add(Edge{From: from, To: to, Weight: weight})
+	g.setWeight(from, to, weight)
+	g.out[from] = append(g.out[from], to)
+	if g.directed {
+		g.in[to] = append(g.in[to], from)
+	} else if from != to {
+		g.out[to] = append(g.out[to], from)
+	}
+	return nil
+}
+
+// RemoveEdge 删除从 from 到 to 的边
+//
+// 如果边存在则返回 true,否则返回 false.
+func (g *Graph) RemoveEdge(from, to interface{}) bool {
+	weight, ok := g.weights[arc{from, to}]
+	if !ok {
+		return false
+	}
+	_, _ = g.edges.Remove(g.edge(from, to, weight))
+	delete(g.weights, arc{from, to})
+	g.out[from] = without(g.out[from], to)
+	if g.directed {
+		g.in[to] = without(g.in[to], from)
+	} else if from != to {
+		delete(g.weights, arc{to, from})
+		g.out[to] = without(g.out[to], from)
+	}
+	return true
+}
+
+// ContainsEdge 如果图包含从 from 到 to 的边则返回 true,否则返回 false
+func (g *Graph) ContainsEdge(from, to interface{}) bool {
+	_, ok := g.weights[arc{from, to}]
+	return ok
+}
+
+// Weight 返回从 from 到 to 的边的权重
+//
+// 如果边存在则第二个返回值为 true,否则为 false.
+func (g *Graph) Weight(from, to interface{}) (float64, bool) {
+	weight, ok := g.weights[arc{from, to}]
+	return weight, ok
+}
+
+// Successors 返回从顶点 v 出发的边所到达的顶点集
+//
+// 无向图中为顶点 v 的邻接顶点集.
+// 如果顶点不存在则返回 VertexNotFoundErr.
+func (g *Graph) Successors(v interface{}) (collection.Set, error) {
+	if !g.ContainsVertex(v) {
+		return nil, VertexNotFoundErr
+	}
+	return set.NewHashSetWithElements(g.out[v]...), nil
+}
+
+// Predecessors 返回到达顶点 v 的边的起点集
+//
+// 无向图中为顶点 v 的邻接顶点集.
+// 如果顶点不存在则返回 VertexNotFoundErr.
+func (g *Graph) Predecessors(v interface{}) (collection.Set, error) {
+	if !g.ContainsVertex(v) {
+		return nil, VertexNotFoundErr
+	}
+	if !g.directed {
+		return set.NewHashSetWithElements(g.out[v]...), nil
+	}
+	return set.NewHashSetWithElements(g.in[v]...), nil
+}
+
+// OutDegree 返回顶点 v 的出度,无向图中为度,顶点不存在时返回0
+func (g *Graph) OutDegree(v interface{}) int {
+	return len(g.out[v])
+}
+
+// InDegree 返回顶点 v 的入度,无向图中为度,顶点不存在时返回0
+func (g *Graph) InDegree(v interface{}) int {
+	if !g.directed {
+		return len(g.out[v])
+	}
+	return len(g.in[v])
+}
+
+// edge 返回边集中从 from 到 to 的边
+//
+// 无向图中边只按加入时的方向保存一次.
+func (g *Graph) edge(from, to interface{}, weight float64) Edge {
+	e := Edge{From: from, To: to, Weight: weight}
+	if ok, _ := g.edges.Contains(e); !ok && !g.directed {
+		e.From, e.To = to, from
+	}
+	return e
+}
+
+// setWeight 设置从 from 到 to 的边的权重,无向图中同时设置反方向
+func (g *Graph) setWeight(from, to interface{}, weight float64) {
+	g.weights[arc{from, to}] = weight
+	if !g.directed {
+		g.weights[arc{to, from}] = weight
+	}
+}
+
+// without 返回删除第一个等于 v 的元素后的切片
+func without(vs []collection.Element, v interface{}) []collection.Element {
+	for i, w := range vs {
+		if w == v {
+			copy(vs[i:], vs[i+1:])
+			vs[len(vs)-1] = nil
+			return vs[:len(vs)-1]
+		}
+	}
+	return vs
+}
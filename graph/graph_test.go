@@ -0,0 +1,116 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_Directed(t *testing.T) {
+	g := NewDirected()
+	assert.True(t, g.IsDirected())
+	added, err := g.AddVertex("a")
+	assert.True(t, added)
+	assert.Nil(t, err)
+	added, _ = g.AddVertex("a")
+	assert.False(t, added)
+	_, err = g.AddVertex(nil)
+	assert.Equal(t, errs.NilPointer, err)
+
+	assert.Nil(t, g.AddEdge("a", "b", 1))
+	assert.Nil(t, g.AddEdge("b", "c", 2))
+	assert.Nil(t, g.AddEdge("a", "c", 5))
+	assert.Equal(t, errs.NilPointer, g.AddEdge("a", nil, 1))
+	assert.Equal(t, 3, g.Order())
+	assert.Equal(t, 3, g.Size())
+	assert.True(t, g.ContainsEdge("a", "b"))
+	assert.False(t, g.ContainsEdge("b", "a"))
+	assert.Equal(t, 2, g.OutDegree("a"))
+	assert.Equal(t, 2, g.InDegree("c"))
+
+	assert.Nil(t, g.AddEdge("a", "c", 3))
+	w, ok := g.Weight("a", "c")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, w)
+	assert.True(t, set.NewHashSetWithElements(
+		Edge{"a", "b", 1}, Edge{"b", "c", 2}, Edge{"a", "c", 3},
+	).Equals(g.Edges()))
+
+	successors, _ := g.Successors("a")
+	assert.True(t, set.NewHashSetWithElements("b", "c").Equals(successors))
+	predecessors, _ := g.Predecessors("c")
+	assert.True(t, set.NewHashSetWithElements("a", "b").Equals(predecessors))
+	_, err = g.Successors("x")
+	assert.Equal(t, VertexNotFoundErr, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
+
+	assert.True(t, g.RemoveVertex("b"))
+	assert.False(t, g.RemoveVertex("b"))
+	assert.True(t, set.NewHashSetWithElements("a", "c").Equals(g.Vertices()))
+	assert.True(t, set.NewHashSetWithElements(Edge{"a", "c", 3}).Equals(g.Edges()))
+	assert.Equal(t, 1, g.InDegree("c"))
+
+	assert.True(t, g.RemoveEdge("a", "c"))
+	assert.False(t, g.RemoveEdge("a", "c"))
+	assert.Equal(t, 0, g.Size())
+}
+
+func TestGraph_Undirected(t *testing.T) {
+	g := NewUndirected()
+	assert.False(t, g.IsDirected())
+	assert.Nil(t, g.AddEdge(1, 2, 4))
+	assert.Nil(t, g.AddEdge(2, 3, 1))
+	assert.Nil(t, g.AddEdge(3, 3, 0))
+	assert.True(t, g.ContainsEdge(2, 1))
+	assert.Equal(t, 3, g.Size())
+	assert.Equal(t, 2, g.OutDegree(2))
+	assert.Equal(t, 2, g.InDegree(2))
+
+	// 反方向替换权重时保留边加入时的方向
+	assert.Nil(t, g.AddEdge(2, 1, 7))
+	w, _ := g.Weight(1, 2)
+	assert.Equal(t, 7.0, w)
+	assert.True(t, set.NewHashSetWithElements(
+		Edge{1, 2, 7}, Edge{2, 3, 1}, Edge{3, 3, 0},
+	).Equals(g.Edges()))
+
+	neighbours, _ := g.Predecessors(2)
+	assert.True(t, set.NewHashSetWithElements(1, 3).Equals(neighbours))
+
+	assert.True(t, g.RemoveEdge(2, 1))
+	assert.False(t, g.ContainsEdge(1, 2))
+	assert.True(t, g.RemoveVertex(3))
+	assert.Equal(t, 0, g.Size())
+	assert.Equal(t, 0, g.OutDegree(2))
+
+	// 返回的顶点集是副本
+	vertices := g.Vertices()
+	_, _ = vertices.Add(42)
+	assert.False(t, g.ContainsVertex(42))
+	var _ collection.Set = vertices
+}
+
+func TestEdge_String(t *testing.T) {
+	assert.Equal(t, "a -(1.5)-> b", Edge{"a", "b", 1.5}.String())
+}
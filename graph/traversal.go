@@ -0,0 +1,134 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package graph
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/queue"
+	"github.com/chenquan/go-util/stack"
+)
+
+// BFS 返回从顶点 start 开始广度优先遍历可到达顶点的迭代器
+//
+// 遍历过程中不能修改图.迭代器不支持 Remove.
+// 如果顶点不存在则返回 VertexNotFoundErr.
+func (g *Graph) BFS(start interface{}) (collection.Iterator, error) {
+	if !g.ContainsVertex(start) {
+		return nil, VertexNotFoundErr
+	}
+	itr := &bfsIterator{graph: g, queue: queue.NewSliceDeQueue(), visited: map[interface{}]bool{start: true}}
+	_, _ = itr.queue.Offer(start)
+	return itr, nil
+}
+
+// DFS 返回从顶点 start 开始深度优先遍历可到达顶点的迭代器,顶点按先序返回
+//
+// 遍历过程中不能修改图.迭代器不支持 Remove.
+// 如果顶点不存在则返回 VertexNotFoundErr.
+func (g *Graph) DFS(start interface{}) (collection.Iterator, error) {
+	if !g.ContainsVertex(start) {
+		return nil, VertexNotFoundErr
+	}
+	itr := &dfsIterator{graph: g, stack: stack.NewStack(), visited: make(map[interface{}]bool)}
+	itr.stack.Push(start)
+	return itr, nil
+}
+
+// bfsIterator 广度优先遍历的迭代器
+type bfsIterator struct {
+	graph   *Graph
+	queue   *queue.SliceDeQueue // 已发现但未返回的顶点
+	visited map[interface{}]bool
+}
+
+// HasNext 如果还有未遍历的顶点则返回 true,否则返回 false
+func (itr *bfsIterator) HasNext() bool {
+	return !itr.queue.IsEmpty()
+}
+
+// Next 返回下一个顶点
+//
+// 如果没有更多的顶点则返回 errs.NoSuchElement.
+func (itr *bfsIterator) Next() (collection.Element, error) {
+	v := itr.queue.Poll()
+	if v == nil {
+		return nil, errs.NoSuchElement
+	}
+	for _, w := range itr.graph.out[v] {
+		if !itr.visited[w] {
+			itr.visited[w] = true
+			_, _ = itr.queue.Offer(w)
+		}
+	}
+	return v, nil
+}
+
+// Remove 不支持,总是返回 UnsupportedErr
+func (itr *bfsIterator) Remove() error {
+	return UnsupportedErr
+}
+
+// dfsIterator 深度优先遍历的迭代器
+type dfsIterator struct {
+	graph   *Graph
+	stack   *stack.Stack // 待访问的顶点,可能包含已访问的顶点
+	visited map[interface{}]bool
+}
+
+// skipVisited 弹出栈顶已访问的顶点
+func (itr *dfsIterator) skipVisited() {
+	for !itr.stack.IsEmpty() {
+		v, _ := itr.stack.Peek()
+		if !itr.visited[v] {
+			return
+		}
+		_, _ = itr.stack.Pop()
+	}
+}
+
+// HasNext 如果还有未遍历的顶点则返回 true,否则返回 false
+func (itr *dfsIterator) HasNext() bool {
+	itr.skipVisited()
+	return !itr.stack.IsEmpty()
+}
+
+// Next 返回下一个顶点
+//
+// 如果没有更多的顶点则返回 errs.NoSuchElement.
+func (itr *dfsIterator) Next() (collection.Element, error) {
+	itr.skipVisited()
+	v, err := itr.stack.Pop()
+	if err != nil {
+		return nil, errs.NoSuchElement
+	}
+	itr.visited[v] = true
+	// 逆序入栈,使先加入的邻接顶点先被访问
+	out := itr.graph.out[v]
+	for i := len(out) - 1; i >= 0; i-- {
+		if !itr.visited[out[i]] {
+			itr.stack.Push(out[i])
+		}
+	}
+	return v, nil
+}
+
+// Remove 不支持,总是返回 UnsupportedErr
+func (itr *dfsIterator) Remove() error {
+	return UnsupportedErr
+}
@@ -0,0 +1,84 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(t *testing.T, itr collection.Iterator, err error) []collection.Element {
+	assert.Nil(t, err)
+	var vs []collection.Element
+	for itr.HasNext() {
+		v, err := itr.Next()
+		assert.Nil(t, err)
+		vs = append(vs, v)
+	}
+	_, err = itr.Next()
+	assert.Equal(t, errs.NoSuchElement, err)
+	assert.Equal(t, UnsupportedErr, itr.Remove())
+	return vs
+}
+
+// tree 返回如下的无向图,另有孤立顶点7
+//
+//	    1
+//	   / \
+//	  2   3
+//	 / \   \
+//	4   5 - 6
+func tree() *Graph {
+	g := NewUndirected()
+	_ = g.AddEdge(1, 2, 1)
+	_ = g.AddEdge(1, 3, 1)
+	_ = g.AddEdge(2, 4, 1)
+	_ = g.AddEdge(2, 5, 1)
+	_ = g.AddEdge(3, 6, 1)
+	_ = g.AddEdge(5, 6, 1)
+	_, _ = g.AddVertex(7)
+	return g
+}
+
+func TestGraph_BFS(t *testing.T) {
+	g := tree()
+	itr, err := g.BFS(1)
+	assert.Equal(t, []collection.Element{1, 2, 3, 4, 5, 6}, collect(t, itr, err))
+	itr, err = g.BFS(7)
+	assert.Equal(t, []collection.Element{7}, collect(t, itr, err))
+	_, err = g.BFS(8)
+	assert.Equal(t, VertexNotFoundErr, err)
+}
+
+func TestGraph_DFS(t *testing.T) {
+	g := tree()
+	itr, err := g.DFS(1)
+	assert.Equal(t, []collection.Element{1, 2, 4, 5, 6, 3}, collect(t, itr, err))
+	_, err = g.DFS(8)
+	assert.Equal(t, VertexNotFoundErr, err)
+
+	d := NewDirected()
+	_ = d.AddEdge("a", "b", 1)
+	_ = d.AddEdge("b", "a", 1)
+	_ = d.AddEdge("c", "a", 1)
+	itr, err = d.DFS("a")
+	assert.Equal(t, []collection.Element{"a", "b"}, collect(t, itr, err))
+}
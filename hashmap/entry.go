@@ -0,0 +1,268 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package hashmap
+
+import (
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/hashcode"
+)
+
+var _ _map.Entry = (*entry)(nil)
+
+// entry 实现 _map.Entry 接口
+type entry struct {
+	owner _map.Map   // 所属映射
+	key   _map.Key   // 键
+	value _map.Value // 值
+}
+
+// newEntry 创建键值对
+func newEntry(owner _map.Map, k _map.Key, v _map.Value) *entry {
+	return &entry{owner: owner, key: k, value: v}
+}
+
+// Key 返回键
+func (e *entry) Key() (_map.Key, error) {
+	return e.key, nil
+}
+
+// Value 返回值
+func (e *entry) Value() (_map.Value, error) {
+	return e.value, nil
+}
+
+// SetValue 替换值,并写入所属映射
+func (e *entry) SetValue(value _map.Value) (_map.Value, error) {
+	if _, err := e.owner.Put(e.key, value); err != nil {
+		return nil, err
+	}
+	old := e.value
+	e.value = value
+	return old, nil
+}
+
+// Equals 如果 o 也是 _map.Entry 且键与值均相等则返回 true,否则返回 false
+func (e *entry) Equals(o interface{}) bool {
+	other, ok := o.(_map.Entry)
+	if !ok {
+		return false
+	}
+	k, err := other.Key()
+	if err != nil || k != e.key {
+		return false
+	}
+	v, err := other.Value()
+	return err == nil && v == e.value
+}
+
+// HashCode 返回键值对的哈希码
+func (e *entry) HashCode() int {
+	return hashcode.Of(e.key) ^ hashcode.Of(e.value)
+}
+
+// ComparingByKey 返回按键的自然顺序比较键值对的比较器
+func (e *entry) ComparingByKey() function.Comparator {
+	return func(o1, o2 interface{}) int {
+		k1, _ := o1.(_map.Entry).Key()
+		k2, _ := o2.(_map.Entry).Key()
+		return function.NaturalOrder(k1, k2)
+	}
+}
+
+// ComparingByValue 返回按值的自然顺序比较键值对的比较器
+func (e *entry) ComparingByValue() function.Comparator {
+	return func(o1, o2 interface{}) int {
+		v1, _ := o1.(_map.Entry).Value()
+		v2, _ := o2.(_map.Entry).Value()
+		return function.NaturalOrder(v1, v2)
+	}
+}
+
+// putAll 将映射 o 中的所有键值对添加到映射 m 中
+func putAll(m _map.Map, o _map.Map) error {
+	itr := o.EntrySet().Iterator()
+	for itr.HasNext() {
+		next, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		e := next.(_map.Entry)
+		k, err := e.Key()
+		if err != nil {
+			return err
+		}
+		v, err := e.Value()
+		if err != nil {
+			return err
+		}
+		if _, err = m.Put(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equals 如果 o 也是 _map.Map 且与 m 包含相同的键值对则返回 true,否则返回 false
+func equals(m _map.Map, o interface{}) bool {
+	other, ok := o.(_map.Map)
+	if !ok || m.Size() != other.Size() {
+		return false
+	}
+	itr := m.EntrySet().Iterator()
+	for itr.HasNext() {
+		next, _ := itr.Next()
+		e := next.(_map.Entry)
+		k, _ := e.Key()
+		v, _ := e.Value()
+		if contains, err := other.ContainsKey(k); err != nil || !contains {
+			return false
+		}
+		if ov, err := other.Get(k); err != nil || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// hashCode 返回键值对的哈希码之和
+func hashCode(data map[interface{}]interface{}) int {
+	h := 0
+	for k, v := range data {
+		h += hashcode.Of(k) ^ hashcode.Of(v)
+	}
+	return h
+}
+
+// putIfAbsent 如果映射 m 中不存在键 k 或其映射到 nil 则将其映射到值 v,否则返回键 k 当前映射的值
+func putIfAbsent(m _map.Map, k _map.Key, v _map.Value) (_map.Value, error) {
+	old, err := m.Get(k)
+	if err != nil || old != nil {
+		return old, err
+	}
+	_, err = m.Put(k, v)
+	return nil, err
+}
+
+// computeIfAbsent 如果映射 m 中不存在键 k 或其映射到 nil 则将其映射到 mapping(k) 的结果
+//
+// op 为调用方的操作名称,用于构造错误.
+func computeIfAbsent(m _map.Map, op string, k _map.Key, mapping function.Function) (_map.Value, error) {
+	if mapping == nil {
+		return nil, errs.NewError(errs.NilPointer, op)
+	}
+	old, err := m.Get(k)
+	if err != nil || old != nil {
+		return old, err
+	}
+	v := mapping(k)
+	if v == nil {
+		return nil, nil
+	}
+	if _, err = m.Put(k, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// computeIfPresent 如果映射 m 中的键 k 映射到非 nil 的值则将其映射到 remapping(k, 旧值) 的结果
+//
+// op 为调用方的操作名称,用于构造错误.
+func computeIfPresent(m _map.Map, op string, k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	if remapping == nil {
+		return nil, errs.NewError(errs.NilPointer, op)
+	}
+	old, err := m.Get(k)
+	if err != nil || old == nil {
+		return nil, err
+	}
+	return replace(m, k, true, remapping(k, old))
+}
+
+// compute 将映射 m 中的键 k 映射到 remapping(k, 旧值) 的结果
+//
+// op 为调用方的操作名称,用于构造错误.
+func compute(m _map.Map, op string, k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	if remapping == nil {
+		return nil, errs.NewError(errs.NilPointer, op)
+	}
+	contains, err := m.ContainsKey(k)
+	if err != nil {
+		return nil, err
+	}
+	old, err := m.Get(k)
+	if err != nil {
+		return nil, err
+	}
+	return replace(m, k, contains, remapping(k, old))
+}
+
+// merge 将映射 m 中的键 k 映射到值 v,或者在键 k 映射到非 nil 的值时映射到 remapping(旧值, v) 的结果
+//
+// op 为调用方的操作名称,用于构造错误.
+func merge(m _map.Map, op string, k _map.Key, v _map.Value, remapping function.BiFunction) (_map.Value, error) {
+	if v == nil || remapping == nil {
+		return nil, errs.NewError(errs.NilPointer, op)
+	}
+	old, err := m.Get(k)
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		if _, err = m.Put(k, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return replace(m, k, true, remapping(old, v))
+}
+
+// replace 将映射 m 中的键 k 映射到值 v,v 为 nil 时删除键 k 的映射
+//
+// contains 表示键 k 当前是否存在.
+func replace(m _map.Map, k _map.Key, contains bool, v _map.Value) (_map.Value, error) {
+	if v == nil {
+		if contains {
+			if _, err := m.Remove(k); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	if _, err := m.Put(k, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// removeIfEquals 仅当映射 m 中的键 k 映射到值 v 时删除该映射
+func removeIfEquals(m _map.Map, k _map.Key, v _map.Value) (bool, error) {
+	contains, err := m.ContainsKey(k)
+	if err != nil || !contains {
+		return false, err
+	}
+	old, err := m.Get(k)
+	if err != nil || old != v {
+		return false, err
+	}
+	if _, err = m.Remove(k); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,295 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package hashmap
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/set"
+)
+
+var _ _map.BiMap = (*HashBiMap)(nil)
+
+var (
+	ValueAlreadyPresentErr = fmt.Errorf("value already present: %w", errs.IllegalArgument)
+)
+
+// HashBiMap 基于两个哈希表实现 _map.BiMap 接口
+//
+// 键和值都必须是可比较的(可作为 map 的键).
+// 注意 HashBiMap 协程不安全,不能用于高并发.
+type HashBiMap struct {
+	forward  map[interface{}]interface{} // 键到值
+	backward map[interface{}]interface{} // 值到键
+	inverse  *HashBiMap                  // 逆视图
+}
+
+// NewHashBiMap 创建空的双向映射
+func NewHashBiMap() *HashBiMap {
+	m := &HashBiMap{
+		forward:  make(map[interface{}]interface{}),
+		backward: make(map[interface{}]interface{}),
+	}
+	m.inverse = &HashBiMap{forward: m.backward, backward: m.forward, inverse: m}
+	return m
+}
+
+// Size 返回键值对的个数
+func (m *HashBiMap) Size() int {
+	return len(m.forward)
+}
+
+// IsEmpty 如果不存在键值对则返回 true,否则返回 false
+func (m *HashBiMap) IsEmpty() bool {
+	return len(m.forward) == 0
+}
+
+// ContainsKey 如果存在键 k 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) ContainsKey(k _map.Key) (bool, error) {
+	_, ok := m.forward[k]
+	return ok, nil
+}
+
+// ContainsValue 如果存在值 v 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) ContainsValue(v _map.Value) (bool, error) {
+	_, ok := m.backward[v]
+	return ok, nil
+}
+
+// Get 返回键 k 映射的值,如果不存在则返回 nil
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) Get(k _map.Key) (_map.Value, error) {
+	return m.forward[k], nil
+}
+
+// GetOrDefault 返回键 k 映射的值,如果不存在则返回 defaultValue
+func (m *HashBiMap) GetOrDefault(k _map.Key, defaultValue _map.Value) _map.Value {
+	if v, ok := m.forward[k]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// ForEach 对每个键值对执行 action,遍历顺序是不确定的
+func (m *HashBiMap) ForEach(action function.BiConsumer) {
+	for k, v := range m.forward {
+		action(k, v)
+	}
+}
+
+// PutIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到值 v 并返回 nil,否则返回键 k 当前映射的值
+//
+// 如果值 v 已经映射到其他键,则返回 ValueAlreadyPresentErr.
+func (m *HashBiMap) PutIfAbsent(k _map.Key, v _map.Value) (_map.Value, error) {
+	return putIfAbsent(m, k, v)
+}
+
+// ComputeIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到 mapping(k) 的结果,并返回键 k 当前映射的值
+//
+// mapping 返回 nil 时不添加映射,mapping 为 nil 时返回 errs.NilPointer.
+// 如果新值已经映射到其他键,则返回 ValueAlreadyPresentErr,当前映射保持不变.
+func (m *HashBiMap) ComputeIfAbsent(k _map.Key, mapping function.Function) (_map.Value, error) {
+	return computeIfAbsent(m, "HashBiMap.ComputeIfAbsent", k, mapping)
+}
+
+// ComputeIfPresent 如果键 k 映射到非 nil 的值则将其映射到 remapping(k, 旧值) 的结果,并返回新值
+//
+// remapping 返回 nil 时删除键 k 的映射,remapping 为 nil 时返回 errs.NilPointer.
+// 如果新值已经映射到其他键,则返回 ValueAlreadyPresentErr,当前映射保持不变.
+func (m *HashBiMap) ComputeIfPresent(k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	return computeIfPresent(m, "HashBiMap.ComputeIfPresent", k, remapping)
+}
+
+// Compute 将键 k 映射到 remapping(k, 旧值) 的结果,并返回新值,键 k 不存在时旧值为 nil
+//
+// remapping 返回 nil 时删除键 k 的映射,remapping 为 nil 时返回 errs.NilPointer.
+// 如果新值已经映射到其他键,则返回 ValueAlreadyPresentErr,当前映射保持不变.
+func (m *HashBiMap) Compute(k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	return compute(m, "HashBiMap.Compute", k, remapping)
+}
+
+// Merge 如果键 k 不存在或映射到 nil 则将其映射到值 v,否则映射到 remapping(旧值, v) 的结果,并返回新值
+//
+// remapping 返回 nil 时删除键 k 的映射,v 或 remapping 为 nil 时返回 errs.NilPointer.
+// 如果新值已经映射到其他键,则返回 ValueAlreadyPresentErr,当前映射保持不变.
+func (m *HashBiMap) Merge(k _map.Key, v _map.Value, remapping function.BiFunction) (_map.Value, error) {
+	return merge(m, "HashBiMap.Merge", k, v, remapping)
+}
+
+// Put 将键 k 映射到值 v,并返回键 k 之前映射的值
+//
+// 如果值 v 已经映射到其他键,则返回 ValueAlreadyPresentErr,当前映射保持不变.
+func (m *HashBiMap) Put(k _map.Key, v _map.Value) (_map.Value, error) {
+	if owner, ok := m.backward[v]; ok && owner != k {
+		return nil, ValueAlreadyPresentErr
+	}
+	return m.put(k, v), nil
+}
+
+// ForcePut 将键 k 映射到值 v,并返回键 k 之前映射的值
+//
+// 如果值 v 已经映射到其他键,则先删除该键值对.
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) ForcePut(k _map.Key, v _map.Value) (_map.Value, error) {
+	if owner, ok := m.backward[v]; ok && owner != k {
+		delete(m.forward, owner)
+		delete(m.backward, v)
+	}
+	return m.put(k, v), nil
+}
+
+// put 将键 k 映射到值 v,调用方需保证值 v 没有映射到其他键
+func (m *HashBiMap) put(k _map.Key, v _map.Value) _map.Value {
+	old, ok := m.forward[k]
+	if ok {
+		delete(m.backward, old)
+	}
+	m.forward[k] = v
+	m.backward[v] = k
+	return old
+}
+
+// Remove 删除键 k 的映射,并返回键 k 之前映射的值
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) Remove(k _map.Key) (_map.Value, error) {
+	old, ok := m.forward[k]
+	if ok {
+		delete(m.forward, k)
+		delete(m.backward, old)
+	}
+	return old, nil
+}
+
+// RemoveIfEquals 仅当键 k 映射到值 v 时删除该映射,删除成功则返回 true
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) RemoveIfEquals(k _map.Key, v _map.Value) (bool, error) {
+	return removeIfEquals(m, k, v)
+}
+
+// PutAll 将指定映射中的所有键值对添加到当前映射中
+//
+// 如果某个值已经映射到其他键,则返回 ValueAlreadyPresentErr,此前已添加的键值对不会回滚.
+func (m *HashBiMap) PutAll(o _map.Map) error {
+	if o == nil {
+		return errs.NewError(errs.NilPointer, "HashBiMap.PutAll")
+	}
+	return putAll(m, o)
+}
+
+// ReplaceAll 将每个键映射的值替换为 f(键, 值) 的结果
+//
+// 如果替换后的值不唯一则返回 ValueAlreadyPresentErr,当前映射保持不变.
+// f 为 nil 时返回 errs.NilPointer.
+func (m *HashBiMap) ReplaceAll(f function.BiFunction) error {
+	if f == nil {
+		return errs.NewError(errs.NilPointer, "HashBiMap.ReplaceAll")
+	}
+	replaced := make(map[interface{}]interface{}, len(m.forward))
+	for k, v := range m.forward {
+		nv := f(k, v)
+		if _, ok := replaced[nv]; ok {
+			return ValueAlreadyPresentErr
+		}
+		replaced[nv] = k
+	}
+	for v := range m.backward {
+		delete(m.backward, v)
+	}
+	for v, k := range replaced {
+		m.forward[k] = v
+		m.backward[v] = k
+	}
+	return nil
+}
+
+// Clear 清空所有键值对
+//
+// 当前返回的error接口总为 nil
+func (m *HashBiMap) Clear() error {
+	for k := range m.forward {
+		delete(m.forward, k)
+	}
+	for v := range m.backward {
+		delete(m.backward, v)
+	}
+	return nil
+}
+
+// KeySet 返回所有键的集
+//
+// 返回的集是当前映射的快照,修改它不会影响当前映射.
+func (m *HashBiMap) KeySet() collection.Set {
+	keys := set.NewHashSet()
+	for k := range m.forward {
+		_, _ = keys.Add(k)
+	}
+	return keys
+}
+
+// Values 返回所有值的集
+//
+// 返回的集是当前映射的快照,修改它不会影响当前映射.
+func (m *HashBiMap) Values() collection.Collection {
+	values := set.NewHashSet()
+	for v := range m.backward {
+		_, _ = values.Add(v)
+	}
+	return values
+}
+
+// EntrySet 返回所有键值对的集
+//
+// 返回的集是当前映射的快照,但调用键值对的 SetValue 方法会修改当前映射.
+func (m *HashBiMap) EntrySet() collection.Set {
+	entries := set.NewHashSet()
+	for k, v := range m.forward {
+		_, _ = entries.Add(newEntry(m, k, v))
+	}
+	return entries
+}
+
+// Equals 如果 o 也是 _map.Map 且包含相同的键值对则返回 true,否则返回 false
+func (m *HashBiMap) Equals(o interface{}) bool {
+	if m == o {
+		return true
+	}
+	return equals(m, o)
+}
+
+// HashCode 返回当前映射的哈希码,即所有键值对哈希码之和
+func (m *HashBiMap) HashCode() int {
+	return hashCode(m.forward)
+}
+
+// Inverse 返回当前双向映射的逆视图,即值到键的映射
+//
+// 逆视图与当前双向映射共享数据,对任意一方的修改都会反映到另一方.
+func (m *HashBiMap) Inverse() _map.BiMap {
+	return m.inverse
+}
@@ -0,0 +1,119 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package hashmap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashBiMap(t *testing.T) {
+	m := NewHashBiMap()
+	_, _ = m.Put("a", 1)
+	_, _ = m.Put("b", 2)
+
+	inverse := m.Inverse()
+	k, _ := inverse.Get(1)
+	assert.Equal(t, "a", k)
+	assert.Equal(t, m, inverse.Inverse())
+
+	_, err := m.Put("c", 1)
+	assert.Equal(t, ValueAlreadyPresentErr, err)
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+	assert.Equal(t, 2, m.Size())
+
+	old, err := m.Put("a", 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, old)
+	contains, _ := inverse.ContainsKey(1)
+	assert.False(t, contains)
+
+	old, err = m.ForcePut("c", 2)
+	assert.Nil(t, err)
+	assert.Nil(t, old)
+	contains, _ = m.ContainsKey("b")
+	assert.False(t, contains)
+	k, _ = inverse.Get(2)
+	assert.Equal(t, "c", k)
+
+	_, _ = inverse.Put(4, "d")
+	v, _ := m.Get("d")
+	assert.Equal(t, 4, v)
+	assert.Equal(t, 3, m.Size())
+	assert.Equal(t, 3, inverse.Size())
+
+	_, _ = inverse.Remove(4)
+	contains, _ = m.ContainsKey("d")
+	assert.False(t, contains)
+
+	assert.Nil(t, m.Clear())
+	assert.True(t, inverse.IsEmpty())
+}
+
+func TestHashBiMap_Compute(t *testing.T) {
+	m := NewHashBiMap()
+	_, _ = m.Put("a", 1)
+	_, _ = m.Put("b", 2)
+
+	_, err := m.PutIfAbsent("c", 1)
+	assert.Equal(t, ValueAlreadyPresentErr, err)
+	_, err = m.Merge("a", 1, func(old, v interface{}) interface{} { return 2 })
+	assert.Equal(t, ValueAlreadyPresentErr, err)
+	_, err = m.Compute("b", func(k, v interface{}) interface{} { return 1 })
+	assert.Equal(t, ValueAlreadyPresentErr, err)
+	v, _ := m.Get("a")
+	assert.Equal(t, 1, v)
+
+	swap := func(k, v interface{}) interface{} { return 3 - v.(int) }
+	assert.Nil(t, m.ReplaceAll(swap))
+	k, _ := m.Inverse().Get(1)
+	assert.Equal(t, "b", k)
+	k, _ = m.Inverse().Get(2)
+	assert.Equal(t, "a", k)
+
+	assert.Equal(t, ValueAlreadyPresentErr, m.ReplaceAll(func(k, v interface{}) interface{} { return 0 }))
+	v, _ = m.Get("a")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 2, m.Inverse().Size())
+
+	removed, _ := m.Inverse().RemoveIfEquals(2, "a")
+	assert.True(t, removed)
+	assert.Equal(t, 1, m.Size())
+}
+
+func TestHashBiMap_Conformance(t *testing.T) {
+	newMap := func() _map.Map {
+		return NewHashBiMap()
+	}
+	t.Run("Map", func(t *testing.T) {
+		collectiontest.TestMap(t, newMap)
+	})
+	t.Run("MapModel", func(t *testing.T) {
+		collectiontest.TestMapModel(t, newMap)
+	})
+	t.Run("Inverse", func(t *testing.T) {
+		collectiontest.TestMap(t, func() _map.Map {
+			return NewHashBiMap().Inverse()
+		})
+	})
+}
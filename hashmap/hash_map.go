@@ -0,0 +1,239 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package hashmap 映射的实现
+package hashmap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/list"
+	"github.com/chenquan/go-util/set"
+)
+
+var _ _map.Map = (*HashMap)(nil)
+
+// HashMap 基于哈希表实现 _map.Map 接口
+//
+// 键必须是可比较的(可作为 map 的键),迭代顺序是不确定的.
+// 注意 HashMap 协程不安全,不能用于高并发.
+type HashMap struct {
+	data map[interface{}]interface{} // 数据
+}
+
+// NewHashMap 创建空的哈希映射
+func NewHashMap() *HashMap {
+	return &HashMap{data: make(map[interface{}]interface{})}
+}
+
+// NewHashMapWithMap 由指定映射创建哈希映射
+func NewHashMapWithMap(m _map.Map) *HashMap {
+	h := &HashMap{data: make(map[interface{}]interface{}, m.Size())}
+	_ = h.PutAll(m)
+	return h
+}
+
+// Size 返回键值对的个数
+func (m *HashMap) Size() int {
+	return len(m.data)
+}
+
+// IsEmpty 如果不存在键值对则返回 true,否则返回 false
+func (m *HashMap) IsEmpty() bool {
+	return len(m.data) == 0
+}
+
+// ContainsKey 如果存在键 k 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) ContainsKey(k _map.Key) (bool, error) {
+	_, ok := m.data[k]
+	return ok, nil
+}
+
+// ContainsValue 如果存在至少一个键映射到值 v 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) ContainsValue(v _map.Value) (bool, error) {
+	for _, value := range m.data {
+		if value == v {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Get 返回键 k 映射的值,如果不存在则返回 nil
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) Get(k _map.Key) (_map.Value, error) {
+	return m.data[k], nil
+}
+
+// GetOrDefault 返回键 k 映射的值,如果不存在则返回 defaultValue
+func (m *HashMap) GetOrDefault(k _map.Key, defaultValue _map.Value) _map.Value {
+	if v, ok := m.data[k]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// ForEach 对每个键值对执行 action,遍历顺序是不确定的
+func (m *HashMap) ForEach(action function.BiConsumer) {
+	for k, v := range m.data {
+		action(k, v)
+	}
+}
+
+// PutIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到值 v 并返回 nil,否则返回键 k 当前映射的值
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) PutIfAbsent(k _map.Key, v _map.Value) (_map.Value, error) {
+	return putIfAbsent(m, k, v)
+}
+
+// ComputeIfAbsent 如果键 k 不存在或映射到 nil 则将其映射到 mapping(k) 的结果,并返回键 k 当前映射的值
+//
+// mapping 返回 nil 时不添加映射,mapping 为 nil 时返回 errs.NilPointer.
+func (m *HashMap) ComputeIfAbsent(k _map.Key, mapping function.Function) (_map.Value, error) {
+	return computeIfAbsent(m, "HashMap.ComputeIfAbsent", k, mapping)
+}
+
+// ComputeIfPresent 如果键 k 映射到非 nil 的值则将其映射到 remapping(k, 旧值) 的结果,并返回新值
+//
+// remapping 返回 nil 时删除键 k 的映射,remapping 为 nil 时返回 errs.NilPointer.
+func (m *HashMap) ComputeIfPresent(k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	return computeIfPresent(m, "HashMap.ComputeIfPresent", k, remapping)
+}
+
+// Compute 将键 k 映射到 remapping(k, 旧值) 的结果,并返回新值,键 k 不存在时旧值为 nil
+//
+// remapping 返回 nil 时删除键 k 的映射,remapping 为 nil 时返回 errs.NilPointer.
+func (m *HashMap) Compute(k _map.Key, remapping function.BiFunction) (_map.Value, error) {
+	return compute(m, "HashMap.Compute", k, remapping)
+}
+
+// Merge 如果键 k 不存在或映射到 nil 则将其映射到值 v,否则映射到 remapping(旧值, v) 的结果,并返回新值
+//
+// remapping 返回 nil 时删除键 k 的映射,v 或 remapping 为 nil 时返回 errs.NilPointer.
+func (m *HashMap) Merge(k _map.Key, v _map.Value, remapping function.BiFunction) (_map.Value, error) {
+	return merge(m, "HashMap.Merge", k, v, remapping)
+}
+
+// Put 将键 k 映射到值 v,并返回键 k 之前映射的值
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) Put(k _map.Key, v _map.Value) (_map.Value, error) {
+	old := m.data[k]
+	m.data[k] = v
+	return old, nil
+}
+
+// Remove 删除键 k 的映射,并返回键 k 之前映射的值
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) Remove(k _map.Key) (_map.Value, error) {
+	old, ok := m.data[k]
+	if ok {
+		delete(m.data, k)
+	}
+	return old, nil
+}
+
+// RemoveIfEquals 仅当键 k 映射到值 v 时删除该映射,删除成功则返回 true
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) RemoveIfEquals(k _map.Key, v _map.Value) (bool, error) {
+	return removeIfEquals(m, k, v)
+}
+
+// PutAll 将指定映射中的所有键值对添加到当前映射中
+func (m *HashMap) PutAll(o _map.Map) error {
+	if o == nil {
+		return errs.NewError(errs.NilPointer, "HashMap.PutAll")
+	}
+	return putAll(m, o)
+}
+
+// ReplaceAll 将每个键映射的值替换为 f(键, 值) 的结果
+//
+// f 为 nil 时返回 errs.NilPointer.
+func (m *HashMap) ReplaceAll(f function.BiFunction) error {
+	if f == nil {
+		return errs.NewError(errs.NilPointer, "HashMap.ReplaceAll")
+	}
+	for k, v := range m.data {
+		m.data[k] = f(k, v)
+	}
+	return nil
+}
+
+// Clear 清空所有键值对
+//
+// 当前返回的error接口总为 nil
+func (m *HashMap) Clear() error {
+	m.data = make(map[interface{}]interface{})
+	return nil
+}
+
+// KeySet 返回所有键的集
+//
+// 返回的集是当前映射的快照,修改它不会影响当前映射.
+func (m *HashMap) KeySet() collection.Set {
+	keys := set.NewHashSet()
+	for k := range m.data {
+		_, _ = keys.Add(k)
+	}
+	return keys
+}
+
+// Values 返回所有值的集合
+//
+// 返回的集合是当前映射的快照,修改它不会影响当前映射.
+func (m *HashMap) Values() collection.Collection {
+	values := list.NewSliceList(len(m.data))
+	for _, v := range m.data {
+		_, _ = values.Add(v)
+	}
+	return values
+}
+
+// EntrySet 返回所有键值对的集
+//
+// 返回的集是当前映射的快照,但调用键值对的 SetValue 方法会修改当前映射.
+func (m *HashMap) EntrySet() collection.Set {
+	entries := set.NewHashSet()
+	for k, v := range m.data {
+		_, _ = entries.Add(newEntry(m, k, v))
+	}
+	return entries
+}
+
+// Equals 如果 o 也是 _map.Map 且包含相同的键值对则返回 true,否则返回 false
+func (m *HashMap) Equals(o interface{}) bool {
+	if m == o {
+		return true
+	}
+	return equals(m, o)
+}
+
+// HashCode 返回当前映射的哈希码,即所有键值对哈希码之和
+func (m *HashMap) HashCode() int {
+	return hashCode(m.data)
+}
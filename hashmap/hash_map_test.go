@@ -0,0 +1,142 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package hashmap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashMap(t *testing.T) {
+	m := NewHashMap()
+	assert.True(t, m.IsEmpty())
+
+	old, err := m.Put("a", 1)
+	assert.Nil(t, err)
+	assert.Nil(t, old)
+	old, _ = m.Put("a", 2)
+	assert.Equal(t, 1, old)
+	_, _ = m.Put("b", 3)
+	assert.Equal(t, 2, m.Size())
+
+	v, _ := m.Get("a")
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 0, m.GetOrDefault("c", 0))
+	assert.Equal(t, 3, m.GetOrDefault("b", 0))
+
+	contains, _ := m.ContainsKey("b")
+	assert.True(t, contains)
+	contains, _ = m.ContainsValue(3)
+	assert.True(t, contains)
+	contains, _ = m.ContainsValue(4)
+	assert.False(t, contains)
+
+	assert.True(t, m.KeySet().Equals(set.NewHashSetWithElements("a", "b")))
+	assert.Equal(t, 2, m.Values().Size())
+
+	old, _ = m.Remove("a")
+	assert.Equal(t, 2, old)
+	assert.Equal(t, 1, m.Size())
+
+	assert.Nil(t, m.Clear())
+	assert.True(t, m.IsEmpty())
+}
+
+func TestHashMap_EntrySet(t *testing.T) {
+	m := NewHashMap()
+	_, _ = m.Put(1, "x")
+	_, _ = m.Put(2, "y")
+
+	itr := m.EntrySet().Iterator()
+	for itr.HasNext() {
+		next, _ := itr.Next()
+		e := next.(_map.Entry)
+		if k, _ := e.Key(); k == 1 {
+			old, err := e.SetValue("z")
+			assert.Nil(t, err)
+			assert.Equal(t, "x", old)
+		}
+	}
+	v, _ := m.Get(1)
+	assert.Equal(t, "z", v)
+
+	e1 := newEntry(m, 1, "b")
+	e2 := newEntry(m, 2, "a")
+	assert.Equal(t, -1, e1.ComparingByKey()(e1, e2))
+	assert.Equal(t, 1, e1.ComparingByValue()(e1, e2))
+	assert.True(t, e1.Equals(newEntry(nil, 1, "b")))
+	assert.False(t, e1.Equals(e2))
+}
+
+func TestHashMap_Equals(t *testing.T) {
+	m1 := NewHashMap()
+	_, _ = m1.Put(1, "x")
+	_, _ = m1.Put(2, "y")
+	m2 := NewHashMapWithMap(m1)
+
+	assert.True(t, m1.Equals(m2))
+	assert.Equal(t, m1.HashCode(), m2.HashCode())
+	_, _ = m2.Put(2, "z")
+	assert.False(t, m1.Equals(m2))
+	assert.False(t, m1.Equals("x"))
+}
+
+func TestHashMap_Conformance(t *testing.T) {
+	newMap := func() _map.Map {
+		return NewHashMap()
+	}
+	t.Run("Map", func(t *testing.T) {
+		collectiontest.TestMap(t, newMap)
+	})
+	t.Run("MapModel", func(t *testing.T) {
+		collectiontest.TestMapModel(t, newMap)
+	})
+}
+
+func TestHashMap_NilPointerOp(t *testing.T) {
+	assertOp := func(op string, err error) {
+		var e *errs.Error
+		if assert.True(t, errors.As(err, &e), op) {
+			assert.True(t, errors.Is(err, errs.NilPointer), op)
+			assert.Equal(t, op, e.Op)
+		}
+	}
+	m := NewHashMap()
+	_, err := m.ComputeIfAbsent("a", nil)
+	assertOp("HashMap.ComputeIfAbsent", err)
+	_, err = m.ComputeIfPresent("a", nil)
+	assertOp("HashMap.ComputeIfPresent", err)
+	_, err = m.Compute("a", nil)
+	assertOp("HashMap.Compute", err)
+	_, err = m.Merge("a", nil, nil)
+	assertOp("HashMap.Merge", err)
+	assertOp("HashMap.PutAll", m.PutAll(nil))
+	assertOp("HashMap.ReplaceAll", m.ReplaceAll(nil))
+
+	b := NewHashBiMap()
+	_, err = b.Merge("a", 1, nil)
+	assertOp("HashBiMap.Merge", err)
+	assertOp("HashBiMap.PutAll", b.PutAll(nil))
+	assertOp("HashBiMap.ReplaceAll", b.ReplaceAll(nil))
+}
@@ -0,0 +1,294 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package heap 堆及基于堆的工具
+//
+// 提供支持修改优先级的索引堆 IndexedHeap,以及基于堆的 TopK 与 MergeK.
+package heap
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+)
+
+var (
+	InvalidHandleErr = fmt.Errorf("invalid handle: %w", errs.IllegalArgument)
+	InvalidKeyErr    = fmt.Errorf("invalid key: %w", errs.IllegalArgument)
+)
+
+// Handle 索引堆中元素的句柄
+//
+// 通过句柄可以在 O(log n) 时间内修改或删除元素.
+// 元素被弹出或删除后句柄失效.
+type Handle struct {
+	heap  *IndexedHeap
+	index int // 元素在堆中的下标,-1表示已失效
+	value collection.Element
+}
+
+// Value 返回句柄对应的元素
+func (h *Handle) Value() collection.Element {
+	return h.value
+}
+
+// Valid 如果元素仍在堆中则返回 true,否则返回 false
+func (h *Handle) Valid() bool {
+	return h.index >= 0
+}
+
+// IndexedHeap 支持修改优先级的索引堆
+//
+// 堆顶总是按比较器排序最小的元素,每个节点最多有 d 个子节点.
+// 不允许 nil 元素.
+// 零值的 IndexedHeap 是按 function.NaturalOrder 排序的空二叉索引堆,可以直接使用.
+// 注意 IndexedHeap 协程不安全,不能用于高并发.
+type IndexedHeap struct {
+	handles    []*Handle
+	arity      int
+	comparator function.Comparator
+}
+
+// NewIndexedHeap 创建按 c 排序的二叉索引堆
+//
+// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+func NewIndexedHeap(c function.Comparator) *IndexedHeap {
+	return NewDaryIndexedHeap(2, c)
+}
+
+// NewDaryIndexedHeap 创建按 c 排序、每个节点最多有 d 个子节点的索引堆
+//
+// d 越大,堆越矮,插入与减小优先级越快,而弹出越慢.
+// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+// 如果 d 小于2则引发 panic.
+func NewDaryIndexedHeap(d int, c function.Comparator) *IndexedHeap {
+	if d < 2 {
+		panic(errs.NewError(errs.IllegalArgument, "NewDaryIndexedHeap").WithDetail("arity must be at least 2"))
+	}
+	if c == nil {
+		c = function.NaturalOrder
+	}
+	return &IndexedHeap{arity: d, comparator: c}
+}
+
+// Size 返回元素个数
+func (h *IndexedHeap) Size() int {
+	return len(h.handles)
+}
+
+// IsEmpty 如果堆中不存在元素则返回 true,否则返回 false
+func (h *IndexedHeap) IsEmpty() bool {
+	return len(h.handles) == 0
+}
+
+// Push 添加元素 e,并返回其句柄
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
+func (h *IndexedHeap) Push(e collection.Element) (*Handle, error) {
+	if e == nil {
+		return nil, errs.NilPointer
+	}
+	handle := &Handle{heap: h, index: len(h.handles), value: e}
+	h.handles = append(h.handles, handle)
+	h.siftUp(handle.index)
+	return handle, nil
+}
+
+// Peek 返回但不删除堆顶元素,如果堆为空则返回 nil
+func (h *IndexedHeap) Peek() collection.Element {
+	if h.IsEmpty() {
+		return nil
+	}
+	return h.handles[0].value
+}
+
+// PeekHandle 返回堆顶元素的句柄,如果堆为空则返回 nil
+func (h *IndexedHeap) PeekHandle() *Handle {
+	if h.IsEmpty() {
+		return nil
+	}
+	return h.handles[0]
+}
+
+// Pop 删除并返回堆顶元素
+//
+// 如果堆为空则返回 errs.NoSuchElement.
+func (h *IndexedHeap) Pop() (collection.Element, error) {
+	if h.IsEmpty() {
+		return nil, errs.NoSuchElement
+	}
+	return h.removeAt(0), nil
+}
+
+// DecreaseKey 将句柄对应的元素替换为不大于原元素的 e
+//
+// 如果句柄无效则返回 InvalidHandleErr,如果 e 大于原元素则返回 InvalidKeyErr,
+// 如果 e 为 nil 则返回 errs.NilPointer.
+func (h *IndexedHeap) DecreaseKey(handle *Handle, e collection.Element) error {
+	if err := h.check(handle, e); err != nil {
+		return err
+	}
+	if h.compare(e, handle.value) > 0 {
+		return InvalidKeyErr
+	}
+	handle.value = e
+	h.siftUp(handle.index)
+	return nil
+}
+
+// IncreaseKey 将句柄对应的元素替换为不小于原元素的 e
+//
+// 如果句柄无效则返回 InvalidHandleErr,如果 e 小于原元素则返回 InvalidKeyErr,
+// 如果 e 为 nil 则返回 errs.NilPointer.
+func (h *IndexedHeap) IncreaseKey(handle *Handle, e collection.Element) error {
+	if err := h.check(handle, e); err != nil {
+		return err
+	}
+	if h.compare(e, handle.value) < 0 {
+		return InvalidKeyErr
+	}
+	handle.value = e
+	h.siftDown(handle.index)
+	return nil
+}
+
+// Update 将句柄对应的元素替换为 e
+//
+// 如果句柄无效则返回 InvalidHandleErr,如果 e 为 nil 则返回 errs.NilPointer.
+func (h *IndexedHeap) Update(handle *Handle, e collection.Element) error {
+	if err := h.check(handle, e); err != nil {
+		return err
+	}
+	handle.value = e
+	h.siftUp(handle.index)
+	h.siftDown(handle.index)
+	return nil
+}
+
+// Delete 删除句柄对应的元素并返回该元素
+//
+// 如果句柄无效则返回 InvalidHandleErr.
+func (h *IndexedHeap) Delete(handle *Handle) (collection.Element, error) {
+	if handle == nil || handle.heap != h || !handle.Valid() {
+		return nil, InvalidHandleErr
+	}
+	return h.removeAt(handle.index), nil
+}
+
+// Clear 删除所有元素,所有句柄失效
+func (h *IndexedHeap) Clear() {
+	for _, handle := range h.handles {
+		handle.index = -1
+	}
+	h.handles = nil
+}
+
+// Slice 返回堆中所有元素的切片,顺序不确定
+func (h *IndexedHeap) Slice() []collection.Element {
+	elements := make([]collection.Element, len(h.handles))
+	for i, handle := range h.handles {
+		elements[i] = handle.value
+	}
+	return elements
+}
+
+// check 检查句柄与新元素
+func (h *IndexedHeap) check(handle *Handle, e collection.Element) error {
+	if handle == nil || handle.heap != h || !handle.Valid() {
+		return InvalidHandleErr
+	}
+	if e == nil {
+		return errs.NilPointer
+	}
+	return nil
+}
+
+// removeAt 删除下标为 i 的元素并返回该元素
+func (h *IndexedHeap) removeAt(i int) collection.Element {
+	removed := h.handles[i]
+	last := len(h.handles) - 1
+	h.swap(i, last)
+	h.handles[last] = nil
+	h.handles = h.handles[:last]
+	if i < last {
+		h.siftUp(i)
+		h.siftDown(i)
+	}
+	removed.index = -1
+	return removed.value
+}
+
+// less 如果下标 i 的元素小于下标 j 的元素则返回 true
+func (h *IndexedHeap) less(i, j int) bool {
+	return h.compare(h.handles[i].value, h.handles[j].value) < 0
+}
+
+// d 返回每个节点最多的子节点数,零值的堆为二叉堆
+func (h *IndexedHeap) d() int {
+	if h.arity == 0 {
+		return 2
+	}
+	return h.arity
+}
+
+// compare 按比较器比较 e1 与 e2,零值的堆按 function.NaturalOrder 比较
+func (h *IndexedHeap) compare(e1, e2 collection.Element) int {
+	if h.comparator == nil {
+		return function.NaturalOrder(e1, e2)
+	}
+	return h.comparator(e1, e2)
+}
+
+// swap 交换下标 i 与下标 j 的元素
+func (h *IndexedHeap) swap(i, j int) {
+	h.handles[i], h.handles[j] = h.handles[j], h.handles[i]
+	h.handles[i].index = i
+	h.handles[j].index = j
+}
+
+// siftUp 将下标 i 的元素上移到合适的位置
+func (h *IndexedHeap) siftUp(i int) {
+	for i > 0 {
+		parent := (i - 1) / h.d()
+		if !h.less(i, parent) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+// siftDown 将下标 i 的元素下移到合适的位置
+func (h *IndexedHeap) siftDown(i int) {
+	n := len(h.handles)
+	for {
+		smallest := i
+		first := h.d()*i + 1
+		for child := first; child < first+h.d() && child < n; child++ {
+			if h.less(child, smallest) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package heap
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexedHeap(t *testing.T) {
+	h := NewIndexedHeap(nil)
+	assert.Nil(t, h.Peek())
+	assert.Nil(t, h.PeekHandle())
+	_, err := h.Pop()
+	assert.Equal(t, errs.NoSuchElement, err)
+	_, err = h.Push(nil)
+	assert.Equal(t, errs.NilPointer, err)
+
+	handles := make(map[int]*Handle)
+	for _, e := range []int{50, 20, 40, 10, 30} {
+		handles[e], _ = h.Push(e)
+	}
+	assert.Equal(t, 10, h.Peek())
+	assert.Equal(t, 10, h.PeekHandle().Value())
+
+	assert.Nil(t, h.DecreaseKey(handles[40], 5))
+	assert.Equal(t, 5, h.Peek())
+	assert.Equal(t, InvalidKeyErr, h.DecreaseKey(handles[20], 25))
+	assert.Nil(t, h.IncreaseKey(handles[10], 60))
+	assert.Equal(t, InvalidKeyErr, h.IncreaseKey(handles[50], 45))
+	assert.Equal(t, errs.NilPointer, h.Update(handles[50], nil))
+	assert.Nil(t, h.Update(handles[50], 15))
+
+	e, err := h.Delete(handles[30])
+	assert.Nil(t, err)
+	assert.Equal(t, 30, e)
+	assert.False(t, handles[30].Valid())
+	_, err = h.Delete(handles[30])
+	assert.Equal(t, InvalidHandleErr, err)
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+	assert.Equal(t, InvalidHandleErr, h.DecreaseKey(handles[30], 1))
+
+	other := NewIndexedHeap(nil)
+	_, err = other.Delete(handles[20])
+	assert.Equal(t, InvalidHandleErr, err)
+
+	var popped []collection.Element
+	for !h.IsEmpty() {
+		e, _ := h.Pop()
+		popped = append(popped, e)
+	}
+	assert.Equal(t, []collection.Element{5, 15, 20, 60}, popped)
+	assert.False(t, handles[20].Valid())
+
+	_, _ = h.Push(1)
+	handle, _ := h.Push(2)
+	assert.Len(t, h.Slice(), 2)
+	h.Clear()
+	assert.True(t, h.IsEmpty())
+	assert.False(t, handle.Valid())
+
+	assert.Panics(t, func() { NewDaryIndexedHeap(1, nil) })
+}
+
+func TestIndexedHeap_ZeroValue(t *testing.T) {
+	var h IndexedHeap
+	for _, e := range []int{5, 3, 8, 1, 4} {
+		_, err := h.Push(e)
+		assert.Nil(t, err)
+	}
+	var got []collection.Element
+	for !h.IsEmpty() {
+		e, err := h.Pop()
+		assert.Nil(t, err)
+		got = append(got, e)
+	}
+	assert.Equal(t, []collection.Element{1, 3, 4, 5, 8}, got)
+}
+
+func TestIndexedHeap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(2021))
+	for _, d := range []int{2, 3, 4, 8} {
+		h := NewDaryIndexedHeap(d, nil)
+		var live []*Handle
+		for step := 0; step < 3000; step++ {
+			switch op := r.Intn(5); {
+			case op < 2 || len(live) == 0:
+				handle, _ := h.Push(r.Intn(1000))
+				live = append(live, handle)
+			case op == 2:
+				i := r.Intn(len(live))
+				assert.Nil(t, h.Update(live[i], r.Intn(1000)))
+			case op == 3:
+				i := r.Intn(len(live))
+				_, err := h.Delete(live[i])
+				assert.Nil(t, err)
+				live = append(live[:i], live[i+1:]...)
+			default:
+				var want []int
+				for _, handle := range live {
+					want = append(want, handle.Value().(int))
+				}
+				sort.Ints(want)
+				e, _ := h.Pop()
+				assert.Equal(t, want[0], e)
+				for i, handle := range live {
+					if !handle.Valid() {
+						live = append(live[:i], live[i+1:]...)
+						break
+					}
+				}
+			}
+			assert.Equal(t, len(live), h.Size())
+		}
+		for i, handle := range h.handles {
+			assert.Equal(t, i, handle.index)
+			if i > 0 {
+				assert.False(t, h.less(i, (i-1)/d))
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package heap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+)
+
+var (
+	// UnsupportedErr 不支持的操作,与 errs.UnsupportedOperation 相同
+	UnsupportedErr = errs.UnsupportedOperation
+)
+
+// TopK 返回迭代器中按 c 排序最大的 k 个元素,按从大到小排列
+//
+// 使用大小为 k 的堆,时间复杂度为 O(n log k).
+// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+// 迭代器返回错误时返回该错误,存在 nil 元素时返回 errs.NilPointer.
+func TopK(itr collection.Iterator, k int, c function.Comparator) ([]collection.Element, error) {
+	if itr == nil {
+		return nil, errs.NilPointer
+	}
+	h := NewIndexedHeap(c)
+	for k > 0 && itr.HasNext() {
+		e, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if h.Size() < k {
+			if _, err = h.Push(e); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if e == nil {
+			return nil, errs.NilPointer
+		}
+		if h.compare(e, h.Peek()) > 0 {
+			_ = h.IncreaseKey(h.PeekHandle(), e)
+		}
+	}
+	top := make([]collection.Element, h.Size())
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i], _ = h.Pop()
+	}
+	return top, nil
+}
+
+// MergeK 返回合并多个有序迭代器的迭代器
+//
+// 每个迭代器必须按 c 从小到大返回元素,合并后的迭代器同样按 c 从小到大返回元素,
+// 相等的元素按迭代器的参数顺序返回.元素在调用 HasNext 或 Next 时按需读取.
+// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+// 合并后的迭代器不支持 Remove,底层迭代器返回的错误由 Next 返回.
+func MergeK(c function.Comparator, iterators ...collection.Iterator) collection.Iterator {
+	if c == nil {
+		c = function.NaturalOrder
+	}
+	return &mergeIterator{
+		heap: NewIndexedHeap(func(o1, o2 interface{}) int {
+			c1, c2 := o1.(*cursor), o2.(*cursor)
+			if r := c(c1.value, c2.value); r != 0 {
+				return r
+			}
+			return c1.source - c2.source
+		}),
+		pending: iterators,
+	}
+}
+
+// cursor 迭代器的当前元素
+type cursor struct {
+	value  collection.Element
+	source int // 迭代器的下标
+	itr    collection.Iterator
+}
+
+// mergeIterator 合并多个有序迭代器的迭代器
+type mergeIterator struct {
+	heap    *IndexedHeap          // 各迭代器的当前元素
+	pending []collection.Iterator // 尚未读取第一个元素的迭代器
+	err     error                 // 待返回的错误
+}
+
+// prime 读取每个迭代器的第一个元素
+func (itr *mergeIterator) prime() {
+	for i, it := range itr.pending {
+		if it != nil {
+			itr.advance(&cursor{source: i, itr: it})
+		}
+	}
+	itr.pending = nil
+}
+
+// advance 读取迭代器的下一个元素并加入堆
+func (itr *mergeIterator) advance(c *cursor) {
+	if !c.itr.HasNext() {
+		return
+	}
+	value, err := c.itr.Next()
+	if err != nil {
+		if itr.err == nil {
+			itr.err = err
+		}
+		return
+	}
+	c.value = value
+	_, _ = itr.heap.Push(c)
+}
+
+// HasNext 如果还有更多的元素或待返回的错误则返回 true,否则返回 false
+func (itr *mergeIterator) HasNext() bool {
+	itr.prime()
+	return itr.err != nil || !itr.heap.IsEmpty()
+}
+
+// Next 返回下一个最小的元素
+//
+// 如果没有更多的元素则返回 errs.NoSuchElement.
+func (itr *mergeIterator) Next() (collection.Element, error) {
+	itr.prime()
+	if itr.err != nil {
+		err := itr.err
+		itr.err = nil
+		return nil, err
+	}
+	top, err := itr.heap.Pop()
+	if err != nil {
+		return nil, errs.NoSuchElement
+	}
+	c := top.(*cursor)
+	value := c.value
+	itr.advance(c)
+	return value, nil
+}
+
+// Remove 不支持,总是返回 UnsupportedErr
+func (itr *mergeIterator) Remove() error {
+	return UnsupportedErr
+}
@@ -0,0 +1,122 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package heap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func iteratorOf(elements ...collection.Element) collection.Iterator {
+	l := list.NewSliceListDefault()
+	for _, e := range elements {
+		_, _ = l.Add(e)
+	}
+	return l.Iterator()
+}
+
+// failingIterator 在返回指定元素后返回错误
+type failingIterator struct {
+	collection.Iterator
+	err error
+}
+
+func (itr *failingIterator) HasNext() bool {
+	return true
+}
+
+func (itr *failingIterator) Next() (collection.Element, error) {
+	if itr.Iterator.HasNext() {
+		return itr.Iterator.Next()
+	}
+	return nil, itr.err
+}
+
+func TestTopK(t *testing.T) {
+	top, err := TopK(iteratorOf(5, 1, 9, 3, 7, 9, 2), 3, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{9, 9, 7}, top)
+
+	top, _ = TopK(iteratorOf(5, 1, 9), 3, function.Comparator(function.NaturalOrder).Reversed())
+	assert.Equal(t, []collection.Element{1, 5, 9}, top)
+	top, _ = TopK(iteratorOf(5, 1), 3, nil)
+	assert.Equal(t, []collection.Element{5, 1}, top)
+	top, _ = TopK(iteratorOf(5, 1), 0, nil)
+	assert.Empty(t, top)
+
+	_, err = TopK(iteratorOf(1, nil), 1, nil)
+	assert.Equal(t, errs.NilPointer, err)
+	_, err = TopK(nil, 1, nil)
+	assert.Equal(t, errs.NilPointer, err)
+	failure := errors.New("failure")
+	_, err = TopK(&failingIterator{iteratorOf(1), failure}, 2, nil)
+	assert.Equal(t, failure, err)
+}
+
+func TestMergeK(t *testing.T) {
+	itr := MergeK(nil,
+		iteratorOf(1, 4, 7),
+		iteratorOf(),
+		iteratorOf(2, 5, 8, 9),
+		iteratorOf(3, 6),
+	)
+	var merged []collection.Element
+	for itr.HasNext() {
+		e, err := itr.Next()
+		assert.Nil(t, err)
+		merged = append(merged, e)
+	}
+	assert.Equal(t, []collection.Element{1, 2, 3, 4, 5, 6, 7, 8, 9}, merged)
+	_, err := itr.Next()
+	assert.Equal(t, errs.NoSuchElement, err)
+	assert.Equal(t, UnsupportedErr, itr.Remove())
+
+	// 相等的元素按迭代器的参数顺序返回
+	type tagged struct {
+		key int
+		tag string
+	}
+	byKey := func(o1, o2 interface{}) int {
+		return o1.(tagged).key - o2.(tagged).key
+	}
+	itr = MergeK(byKey, iteratorOf(tagged{1, "a"}, tagged{2, "a"}), iteratorOf(tagged{1, "b"}))
+	var tags []string
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		tags = append(tags, e.(tagged).tag)
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, tags)
+
+	failure := errors.New("failure")
+	itr = MergeK(nil, iteratorOf(1, 3), &failingIterator{iteratorOf(2), failure})
+	e, _ := itr.Next()
+	assert.Equal(t, 1, e)
+	e, _ = itr.Next()
+	assert.Equal(t, 2, e)
+	_, err = itr.Next()
+	assert.Equal(t, failure, err)
+	e, _ = itr.Next()
+	assert.Equal(t, 3, e)
+	assert.False(t, itr.HasNext())
+}
@@ -0,0 +1,108 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package codec 集合元素的编解码
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/chenquan/go-util/backend/collection"
+)
+
+// MarshalJSON 将元素编码为 JSON 数组
+func MarshalJSON(elements []collection.Element) ([]byte, error) {
+	return json.Marshal(elements)
+}
+
+// UnmarshalJSON 将 JSON 数组解码为元素
+//
+// 如果 elementType 不为 nil,则每个元素都解码为该类型,
+// 否则按 encoding/json 的默认规则解码(例如数字解码为 float64).
+func UnmarshalJSON(data []byte, elementType reflect.Type) ([]collection.Element, error) {
+	if elementType == nil {
+		var elements []collection.Element
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return nil, err
+		}
+		return elements, nil
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	elements := make([]collection.Element, 0, len(raws))
+	for _, raw := range raws {
+		p := reflect.New(elementType)
+		if err := json.Unmarshal(raw, p.Interface()); err != nil {
+			return nil, err
+		}
+		elements = append(elements, p.Elem().Interface())
+	}
+	return elements, nil
+}
+
+// register 向 encoding/gob 注册元素类型
+func register(elementType reflect.Type) {
+	if elementType != nil && elementType.Kind() != reflect.Interface {
+		gob.Register(reflect.Zero(elementType).Interface())
+	}
+}
+
+// MarshalBinary 使用 encoding/gob 编码元素
+//
+// 如果 elementType 不为 nil,则自动向 encoding/gob 注册该类型,
+// 否则非内置类型的元素需要事先调用 gob.Register 注册.
+func MarshalBinary(elements []collection.Element, elementType reflect.Type) ([]byte, error) {
+	register(elementType)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(elements); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 使用 encoding/gob 解码元素
+//
+// elementType 的含义与 MarshalBinary 相同.
+func UnmarshalBinary(data []byte, elementType reflect.Type) ([]collection.Element, error) {
+	register(elementType)
+	var elements []collection.Element
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&elements); err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// String 返回元素的字符串表示,形如 [1, 2, 3]
+func String(elements []collection.Element) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, e := range elements {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		_, _ = fmt.Fprint(&b, e)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
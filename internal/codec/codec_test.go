@@ -0,0 +1,71 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package codec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X, Y int
+}
+
+func TestJSON(t *testing.T) {
+	data, err := MarshalJSON([]collection.Element{1, "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, `[1,"a"]`, string(data))
+
+	elements, err := UnmarshalJSON(data, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{float64(1), "a"}, elements)
+
+	elements, err = UnmarshalJSON([]byte(`[{"X":1,"Y":2}]`), reflect.TypeOf(point{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{point{1, 2}}, elements)
+
+	_, err = UnmarshalJSON([]byte(`["a"]`), reflect.TypeOf(0))
+	assert.NotNil(t, err)
+	_, err = UnmarshalJSON([]byte(`{`), nil)
+	assert.NotNil(t, err)
+}
+
+func TestBinary(t *testing.T) {
+	data, err := MarshalBinary([]collection.Element{1, "a"}, nil)
+	assert.Nil(t, err)
+	elements, err := UnmarshalBinary(data, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{1, "a"}, elements)
+
+	data, err = MarshalBinary([]collection.Element{point{1, 2}}, reflect.TypeOf(point{}))
+	assert.Nil(t, err)
+	elements, err = UnmarshalBinary(data, reflect.TypeOf(point{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{point{1, 2}}, elements)
+
+	_, err = UnmarshalBinary([]byte("x"), nil)
+	assert.NotNil(t, err)
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "[]", String(nil))
+	assert.Equal(t, "[1, a, {1 2}]", String([]collection.Element{1, "a", point{1, 2}}))
+}
@@ -0,0 +1,98 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package hashcode 计算任意值的哈希码
+package hashcode
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Hasher 自定义哈希码的类型
+type Hasher interface {
+	HashCode() int
+}
+
+// Of 返回 v 的哈希码
+//
+// 使用 == 判断相等的两个值具有相同的哈希码.
+// 如果 v 实现了 Hasher 接口,则返回其 HashCode 方法的结果.
+func Of(v interface{}) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case Hasher:
+		return x.HashCode()
+	case bool:
+		if x {
+			return 1231
+		}
+		return 1237
+	case int:
+		return mix(uint64(x))
+	case int8:
+		return mix(uint64(x))
+	case int16:
+		return mix(uint64(x))
+	case int32:
+		return mix(uint64(x))
+	case int64:
+		return mix(uint64(x))
+	case uint:
+		return mix(uint64(x))
+	case uint8:
+		return mix(uint64(x))
+	case uint16:
+		return mix(uint64(x))
+	case uint32:
+		return mix(uint64(x))
+	case uint64:
+		return mix(x)
+	case uintptr:
+		return mix(uint64(x))
+	case float32:
+		return Of(float64(x))
+	case float64:
+		if x == 0 {
+			// -0 == +0
+			x = 0
+		}
+		return mix(math.Float64bits(x))
+	case string:
+		return String(x)
+	}
+	return String(fmt.Sprintf("%T:%#v", v, v))
+}
+
+// String 返回字符串的哈希码
+func String(s string) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return int(h.Sum64())
+}
+
+// mix 对整数做雪崩处理
+func mix(h uint64) int {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return int(h)
+}
@@ -0,0 +1,69 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package interval 区间树与区间集
+//
+// 所有区间均为左闭右开区间 [Start,End),区间端点通过 function.Comparator 比较,
+// 因此可用于整数、time.Time 等任意有序类型.
+package interval
+
+import (
+	"errors"
+
+	"github.com/chenquan/go-util/function"
+)
+
+var (
+	InvalidIntervalErr = errors.New("interval start must be less than end")
+)
+
+// Range 左闭右开区间 [Start,End)
+type Range struct {
+	Start interface{} // 起点(包含)
+	End   interface{} // 终点(不包含)
+}
+
+// Interval 关联了值的左闭右开区间 [Start,End)
+type Interval struct {
+	Start interface{} // 起点(包含)
+	End   interface{} // 终点(不包含)
+	Value interface{} // 关联的值
+}
+
+// checkRange 检查区间是否合法
+func checkRange(c function.Comparator, start, end interface{}) error {
+	if c(start, end) >= 0 {
+		return InvalidIntervalErr
+	}
+	return nil
+}
+
+// max 返回 a 与 b 中较大的值
+func max(c function.Comparator, a, b interface{}) interface{} {
+	if c(a, b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// min 返回 a 与 b 中较小的值
+func min(c function.Comparator, a, b interface{}) interface{} {
+	if c(a, b) <= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,252 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package interval
+
+import (
+	"math/rand"
+
+	"github.com/chenquan/go-util/function"
+)
+
+// treeNode 区间树节点
+type treeNode struct {
+	interval Interval    // 区间
+	maxEnd   interface{} // 以当前节点为根的子树中最大的终点
+	priority int         // 堆优先级
+	left     *treeNode   // 左子树
+	right    *treeNode   // 右子树
+}
+
+// IntervalTree 区间树
+//
+// 基于按起点排序的 Treap 实现,每个节点记录子树中最大的终点,
+// 可在 O(log n + k) 时间内找出与指定区间重叠或包含指定点的 k 个区间.
+// 允许存在完全相同的区间.
+// 注意 IntervalTree 协程不安全.
+type IntervalTree struct {
+	root       *treeNode           // 根节点
+	size       int                 // 区间个数
+	comparator function.Comparator // 端点比较器
+}
+
+// NewIntervalTree 创建使用指定比较器比较端点的区间树
+func NewIntervalTree(c function.Comparator) *IntervalTree {
+	return &IntervalTree{comparator: c}
+}
+
+// NewIntervalTreeDefault 创建按自然顺序比较端点的区间树
+//
+// 端点可以是整数、浮点数、字符串或 time.Time.
+func NewIntervalTreeDefault() *IntervalTree {
+	return NewIntervalTree(function.NaturalOrder)
+}
+
+// Size 返回区间个数
+func (t *IntervalTree) Size() int {
+	return t.size
+}
+
+// IsEmpty 如果不存在区间则返回 true,否则返回 false
+func (t *IntervalTree) IsEmpty() bool {
+	return t.size == 0
+}
+
+// Clear 清空所有区间
+func (t *IntervalTree) Clear() {
+	t.root = nil
+	t.size = 0
+}
+
+// Insert 插入关联了值 v 的区间 [start,end)
+//
+// 如果 start 不小于 end 则返回 InvalidIntervalErr.
+func (t *IntervalTree) Insert(start, end, v interface{}) error {
+	if err := checkRange(t.comparator, start, end); err != nil {
+		return err
+	}
+	n := &treeNode{
+		interval: Interval{Start: start, End: end, Value: v},
+		maxEnd:   end,
+		priority: rand.Int(),
+	}
+	t.root = t.insert(t.root, n)
+	t.size++
+	return nil
+}
+
+// insert 将节点 n 插入到以 root 为根的子树中,并返回新的根
+func (t *IntervalTree) insert(root, n *treeNode) *treeNode {
+	if root == nil {
+		return n
+	}
+	if t.comparator(n.interval.Start, root.interval.Start) < 0 {
+		root.left = t.insert(root.left, n)
+		if root.left.priority > root.priority {
+			root = t.rotateRight(root)
+		}
+	} else {
+		root.right = t.insert(root.right, n)
+		if root.right.priority > root.priority {
+			root = t.rotateLeft(root)
+		}
+	}
+	t.update(root)
+	return root
+}
+
+// Delete 删除一个与 [start,end) 及值 v 均相等的区间
+//
+// 如果存在该区间并被删除则返回 true,否则返回 false.
+func (t *IntervalTree) Delete(start, end, v interface{}) bool {
+	var deleted bool
+	t.root, deleted = t.delete(t.root, Interval{Start: start, End: end, Value: v})
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// delete 从以 root 为根的子树中删除区间 i,并返回新的根
+func (t *IntervalTree) delete(root *treeNode, i Interval) (*treeNode, bool) {
+	if root == nil {
+		return nil, false
+	}
+	var deleted bool
+	c := t.comparator(i.Start, root.interval.Start)
+	switch {
+	case c < 0:
+		root.left, deleted = t.delete(root.left, i)
+	case c > 0:
+		root.right, deleted = t.delete(root.right, i)
+	case t.comparator(i.End, root.interval.End) == 0 && i.Value == root.interval.Value:
+		return t.merge(root.left, root.right), true
+	default:
+		// 起点相同的区间可能位于任意一侧
+		root.left, deleted = t.delete(root.left, i)
+		if !deleted {
+			root.right, deleted = t.delete(root.right, i)
+		}
+	}
+	if deleted {
+		t.update(root)
+	}
+	return root, deleted
+}
+
+// merge 合并两棵子树,左子树中所有区间的起点均不大于右子树
+func (t *IntervalTree) merge(left, right *treeNode) *treeNode {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		left.right = t.merge(left.right, right)
+		t.update(left)
+		return left
+	}
+	right.left = t.merge(left, right.left)
+	t.update(right)
+	return right
+}
+
+// rotateRight 右旋
+func (t *IntervalTree) rotateRight(n *treeNode) *treeNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	t.update(n)
+	t.update(l)
+	return l
+}
+
+// rotateLeft 左旋
+func (t *IntervalTree) rotateLeft(n *treeNode) *treeNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	t.update(n)
+	t.update(r)
+	return r
+}
+
+// update 重新计算节点的 maxEnd
+func (t *IntervalTree) update(n *treeNode) {
+	n.maxEnd = n.interval.End
+	if n.left != nil {
+		n.maxEnd = max(t.comparator, n.maxEnd, n.left.maxEnd)
+	}
+	if n.right != nil {
+		n.maxEnd = max(t.comparator, n.maxEnd, n.right.maxEnd)
+	}
+}
+
+// Overlaps 返回所有与 [start,end) 重叠的区间,按起点升序排列
+//
+// 区间 [a,b) 与 [start,end) 重叠当且仅当 a < end 且 start < b.
+func (t *IntervalTree) Overlaps(start, end interface{}) []Interval {
+	result := make([]Interval, 0)
+	if t.comparator(start, end) >= 0 {
+		return result
+	}
+	t.search(t.root, start, end, false, &result)
+	return result
+}
+
+// Stab 返回所有包含点 p 的区间,按起点升序排列
+//
+// 区间 [a,b) 包含点 p 当且仅当 a <= p < b.
+func (t *IntervalTree) Stab(p interface{}) []Interval {
+	result := make([]Interval, 0)
+	t.search(t.root, p, p, true, &result)
+	return result
+}
+
+// search 中序遍历查找与 [start,end) 重叠的区间,stab 为 true 时 end 是包含的
+func (t *IntervalTree) search(n *treeNode, start, end interface{}, stab bool, result *[]Interval) {
+	if n == nil || t.comparator(n.maxEnd, start) <= 0 {
+		return
+	}
+	t.search(n.left, start, end, stab, result)
+	c := t.comparator(n.interval.Start, end)
+	if c > 0 || (c == 0 && !stab) {
+		// 右子树中的区间起点均不小于当前节点
+		return
+	}
+	if t.comparator(start, n.interval.End) < 0 {
+		*result = append(*result, n.interval)
+	}
+	t.search(n.right, start, end, stab, result)
+}
+
+// Intervals 返回所有区间,按起点升序排列
+func (t *IntervalTree) Intervals() []Interval {
+	result := make([]Interval, 0, t.size)
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n == nil {
+			return
+		}
+		walk(n.left)
+		result = append(result, n.interval)
+		walk(n.right)
+	}
+	walk(t.root)
+	return result
+}
@@ -0,0 +1,100 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package interval
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func values(intervals []Interval) []interface{} {
+	vs := make([]interface{}, 0, len(intervals))
+	for _, i := range intervals {
+		vs = append(vs, i.Value)
+	}
+	return vs
+}
+
+func TestIntervalTree(t *testing.T) {
+	tree := NewIntervalTreeDefault()
+	assert.Nil(t, tree.Insert(1, 5, "a"))
+	assert.Nil(t, tree.Insert(3, 8, "b"))
+	assert.Nil(t, tree.Insert(10, 12, "c"))
+	assert.Nil(t, tree.Insert(3, 8, "d"))
+	assert.Equal(t, InvalidIntervalErr, tree.Insert(5, 5, "e"))
+	assert.Equal(t, 4, tree.Size())
+
+	assert.ElementsMatch(t, []interface{}{"a", "b", "d"}, values(tree.Overlaps(4, 6)))
+	assert.ElementsMatch(t, []interface{}{"b", "d"}, values(tree.Overlaps(5, 10)))
+	assert.Empty(t, tree.Overlaps(8, 10))
+	assert.Empty(t, tree.Overlaps(6, 6))
+
+	assert.ElementsMatch(t, []interface{}{"a"}, values(tree.Stab(1)))
+	assert.Empty(t, tree.Stab(12))
+	assert.ElementsMatch(t, []interface{}{"c"}, values(tree.Stab(10)))
+
+	assert.True(t, tree.Delete(3, 8, "d"))
+	assert.False(t, tree.Delete(3, 8, "d"))
+	assert.Equal(t, 3, tree.Size())
+	assert.Equal(t, []interface{}{"a", "b", "c"}, values(tree.Intervals()))
+
+	tree.Clear()
+	assert.True(t, tree.IsEmpty())
+}
+
+func TestIntervalTree_Time(t *testing.T) {
+	base := time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)
+	tree := NewIntervalTreeDefault()
+	_ = tree.Insert(base, base.Add(time.Hour), "meeting")
+	_ = tree.Insert(base.Add(2*time.Hour), base.Add(3*time.Hour), "lunch")
+
+	overlaps := tree.Overlaps(base.Add(30*time.Minute), base.Add(150*time.Minute))
+	assert.Equal(t, []interface{}{"meeting", "lunch"}, values(overlaps))
+	assert.Empty(t, tree.Overlaps(base.Add(time.Hour), base.Add(2*time.Hour)))
+}
+
+func TestIntervalTree_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(2021))
+	tree := NewIntervalTreeDefault()
+	var all []Interval
+	for i := 0; i < 500; i++ {
+		start := r.Intn(1000)
+		end := start + 1 + r.Intn(50)
+		_ = tree.Insert(start, end, i)
+		all = append(all, Interval{Start: start, End: end, Value: i})
+	}
+	for i := 0; i < 100; i++ {
+		d := all[i]
+		assert.True(t, tree.Delete(d.Start, d.End, d.Value))
+	}
+	all = all[100:]
+	for q := 0; q < 200; q++ {
+		start := r.Intn(1000)
+		end := start + 1 + r.Intn(100)
+		var want []interface{}
+		for _, i := range all {
+			if i.Start.(int) < end && start < i.End.(int) {
+				want = append(want, i.Value)
+			}
+		}
+		assert.ElementsMatch(t, want, values(tree.Overlaps(start, end)))
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package interval
+
+import (
+	"sort"
+
+	"github.com/chenquan/go-util/function"
+)
+
+// RangeSet 区间集
+//
+// 添加的区间会与已有的重叠或相邻区间合并,
+// 因此区间集中的区间总是互不相交、互不相邻且按起点升序排列的.
+// 注意 RangeSet 协程不安全.
+type RangeSet struct {
+	ranges     []Range             // 按起点升序排列的区间
+	comparator function.Comparator // 端点比较器
+}
+
+// NewRangeSet 创建使用指定比较器比较端点的区间集
+func NewRangeSet(c function.Comparator) *RangeSet {
+	return &RangeSet{comparator: c}
+}
+
+// NewRangeSetDefault 创建按自然顺序比较端点的区间集
+//
+// 端点可以是整数、浮点数、字符串或 time.Time.
+func NewRangeSetDefault() *RangeSet {
+	return NewRangeSet(function.NaturalOrder)
+}
+
+// Size 返回区间个数
+func (s *RangeSet) Size() int {
+	return len(s.ranges)
+}
+
+// IsEmpty 如果不存在区间则返回 true,否则返回 false
+func (s *RangeSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Clear 清空所有区间
+func (s *RangeSet) Clear() {
+	s.ranges = nil
+}
+
+// Ranges 返回所有区间,按起点升序排列
+//
+// 返回的切片是安全的,可任意修改不会影响当前区间集.
+func (s *RangeSet) Ranges() []Range {
+	ranges := make([]Range, len(s.ranges))
+	copy(ranges, s.ranges)
+	return ranges
+}
+
+// Span 返回包含所有区间的最小区间
+//
+// 如果区间集为空则返回 false.
+func (s *RangeSet) Span() (Range, bool) {
+	if len(s.ranges) == 0 {
+		return Range{}, false
+	}
+	return Range{Start: s.ranges[0].Start, End: s.ranges[len(s.ranges)-1].End}, true
+}
+
+// Add 添加区间 [start,end),并与重叠或相邻的区间合并
+//
+// 如果 start 不小于 end 则返回 InvalidIntervalErr.
+func (s *RangeSet) Add(start, end interface{}) error {
+	c := s.comparator
+	if err := checkRange(c, start, end); err != nil {
+		return err
+	}
+	// 第一个终点不小于 start 的区间,即可能与新区间重叠或相邻的第一个区间
+	lo := sort.Search(len(s.ranges), func(i int) bool {
+		return c(s.ranges[i].End, start) >= 0
+	})
+	// 第一个起点大于 end 的区间
+	hi := sort.Search(len(s.ranges), func(i int) bool {
+		return c(s.ranges[i].Start, end) > 0
+	})
+	merged := Range{Start: start, End: end}
+	if lo < hi {
+		merged.Start = min(c, start, s.ranges[lo].Start)
+		merged.End = max(c, end, s.ranges[hi-1].End)
+	}
+	ranges := make([]Range, 0, len(s.ranges)-(hi-lo)+1)
+	ranges = append(ranges, s.ranges[:lo]...)
+	ranges = append(ranges, merged)
+	ranges = append(ranges, s.ranges[hi:]...)
+	s.ranges = ranges
+	return nil
+}
+
+// Remove 从区间集中移除区间 [start,end) 覆盖的部分
+//
+// 如果 start 不小于 end 则返回 InvalidIntervalErr.
+func (s *RangeSet) Remove(start, end interface{}) error {
+	c := s.comparator
+	if err := checkRange(c, start, end); err != nil {
+		return err
+	}
+	ranges := make([]Range, 0, len(s.ranges)+1)
+	for _, r := range s.ranges {
+		if c(r.End, start) <= 0 || c(r.Start, end) >= 0 {
+			ranges = append(ranges, r)
+			continue
+		}
+		if c(r.Start, start) < 0 {
+			ranges = append(ranges, Range{Start: r.Start, End: start})
+		}
+		if c(end, r.End) < 0 {
+			ranges = append(ranges, Range{Start: end, End: r.End})
+		}
+	}
+	s.ranges = ranges
+	return nil
+}
+
+// rangeContaining 返回包含点 p 的区间下标,不存在则返回-1
+func (s *RangeSet) rangeContaining(p interface{}) int {
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return s.comparator(s.ranges[i].End, p) > 0
+	})
+	if i < len(s.ranges) && s.comparator(s.ranges[i].Start, p) <= 0 {
+		return i
+	}
+	return -1
+}
+
+// Contains 如果某个区间包含点 p 则返回 true,否则返回 false
+func (s *RangeSet) Contains(p interface{}) bool {
+	return s.rangeContaining(p) >= 0
+}
+
+// RangeContaining 返回包含点 p 的区间
+//
+// 如果不存在则返回 false.
+func (s *RangeSet) RangeContaining(p interface{}) (Range, bool) {
+	if i := s.rangeContaining(p); i >= 0 {
+		return s.ranges[i], true
+	}
+	return Range{}, false
+}
+
+// Encloses 如果某个区间完全包含 [start,end) 则返回 true,否则返回 false
+func (s *RangeSet) Encloses(start, end interface{}) bool {
+	i := s.rangeContaining(start)
+	return i >= 0 && s.comparator(end, s.ranges[i].End) <= 0
+}
+
+// Intersects 如果某个区间与 [start,end) 重叠则返回 true,否则返回 false
+func (s *RangeSet) Intersects(start, end interface{}) bool {
+	c := s.comparator
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return c(s.ranges[i].End, start) > 0
+	})
+	return i < len(s.ranges) && c(s.ranges[i].Start, end) < 0 && c(start, end) < 0
+}
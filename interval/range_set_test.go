@@ -0,0 +1,75 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package interval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeSet(t *testing.T) {
+	s := NewRangeSetDefault()
+	assert.Nil(t, s.Add(1, 3))
+	assert.Nil(t, s.Add(5, 7))
+	assert.Nil(t, s.Add(3, 4))
+	assert.Equal(t, []Range{{1, 4}, {5, 7}}, s.Ranges())
+
+	assert.Nil(t, s.Add(4, 5))
+	assert.Equal(t, []Range{{1, 7}}, s.Ranges())
+
+	assert.Nil(t, s.Add(10, 12))
+	assert.Nil(t, s.Add(0, 11))
+	assert.Equal(t, []Range{{0, 12}}, s.Ranges())
+
+	assert.Nil(t, s.Remove(3, 5))
+	assert.Equal(t, []Range{{0, 3}, {5, 12}}, s.Ranges())
+	assert.Nil(t, s.Remove(-1, 1))
+	assert.Nil(t, s.Remove(11, 20))
+	assert.Equal(t, []Range{{1, 3}, {5, 11}}, s.Ranges())
+	assert.Equal(t, InvalidIntervalErr, s.Add(2, 1))
+
+	assert.True(t, s.Contains(1))
+	assert.False(t, s.Contains(3))
+	assert.True(t, s.Encloses(5, 11))
+	assert.False(t, s.Encloses(2, 6))
+	assert.True(t, s.Intersects(2, 6))
+	assert.False(t, s.Intersects(3, 5))
+
+	r, ok := s.RangeContaining(7)
+	assert.True(t, ok)
+	assert.Equal(t, Range{5, 11}, r)
+	span, _ := s.Span()
+	assert.Equal(t, Range{1, 11}, span)
+
+	s.Clear()
+	assert.True(t, s.IsEmpty())
+	_, ok = s.Span()
+	assert.False(t, ok)
+}
+
+func TestRangeSet_Time(t *testing.T) {
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewRangeSetDefault()
+	_ = s.Add(base, base.Add(time.Hour))
+	_ = s.Add(base.Add(time.Hour), base.Add(2*time.Hour))
+	assert.Equal(t, 1, s.Size())
+	assert.True(t, s.Contains(base.Add(90*time.Minute)))
+	assert.False(t, s.Contains(base.Add(2*time.Hour)))
+}
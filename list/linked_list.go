@@ -18,8 +18,13 @@
 package list
 
 import (
+	"reflect"
+	"sort"
+
 	"github.com/chenquan/go-util/backend/collection"
 	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
 )
 
 // linkedNode List of Nodes
@@ -33,9 +38,10 @@ type linkedNode struct {
 //
 // Implements all optional list operations, and permits all types (including nil).
 type LinkedList struct {
-	size  int         // LinkedList of size
-	first *linkedNode // Pointer to first node
-	last  *linkedNode // Pointer to last node
+	size        int          // LinkedList of size
+	first       *linkedNode  // Pointer to first node
+	last        *linkedNode  // Pointer to last node
+	elementType reflect.Type // Type of elements when decoding
 }
 
 // NewLinkedList Create a empty linked list.
@@ -73,7 +79,7 @@ func (l *LinkedList) AddLast(e collection.Element) error {
 func (l *LinkedList) RemoveFirst() (collection.Element, error) {
 	first := l.first
 	if first == nil {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.RemoveFirst")
 	}
 	next := first.next
 	l.first = next
@@ -92,22 +98,26 @@ func (l *LinkedList) RemoveFirst() (collection.Element, error) {
 func (l *LinkedList) RemoveLast() (collection.Element, error) {
 	last := l.last
 	if last == nil {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.RemoveLast")
 	}
 	prev := last.prev
 	l.last = prev
 	if prev == nil {
+		// empty linked list
 		l.first = nil
 	} else {
+		// set the next node of the tail node to nil
 		l.last.next = nil
 	}
+	last.prev = nil
+	l.size--
 	return last.elem, nil
 }
 
 // GetFirst Retrieves, but does not remove, the head (first element) of this list.
 func (l *LinkedList) GetFirst() (collection.Element, error) {
 	if l.first == nil {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.GetFirst")
 	}
 	return l.first.elem, nil
 }
@@ -115,7 +125,7 @@ func (l *LinkedList) GetFirst() (collection.Element, error) {
 // GetLast Returns the last element in this list.
 func (l *LinkedList) GetLast() (collection.Element, error) {
 	if l.last == nil {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.GetLast")
 	}
 	return l.last.elem, nil
 }
@@ -174,12 +184,17 @@ func (l *LinkedList) Push(e collection.Element) error {
 //
 // In other words, removes and returns the first element of this list.
 func (l *LinkedList) Pop() (collection.Element, error) {
-	return l.RemoveLast()
+	return l.RemoveFirst()
 }
 
-// DescendingIterator Returns a iterator of descending.
+// DescendingIterator Returns an iterator over the elements in this list in reverse sequential order.
+//
+// The elements will be returned in order from last (tail) to first (head).
 func (l *LinkedList) DescendingIterator() collection.Iterator {
-	panic("implement me")
+	return &itrDescendingLinkedList{
+		data: l,
+		next: l.last,
+	}
 }
 
 // AddAllIndex Inserts all of the elements in the specified collection into this list, starting at the specified position.
@@ -188,7 +203,7 @@ func (l *LinkedList) DescendingIterator() collection.Iterator {
 //The new elements will appear in the list in the order that they are returned by the specified collection's iterator.
 func (l *LinkedList) AddAllIndex(index int, c collection.Collection) (bool, error) {
 	if !l.isPositionIndex(index) {
-		return false, errs.IndexOutOfBound
+		return false, errs.NewIndexError("LinkedList.AddAllIndex", index, l.size)
 	}
 	slice := c.Slice()
 	numNew := len(slice)
@@ -237,25 +252,25 @@ func (l *LinkedList) isPositionIndex(index int) bool {
 	return index >= 0 && index <= l.size
 }
 
-// checkElementIndex Checks if the argument is the index of a error position.
-func (l *LinkedList) checkElementIndex(index int) error {
+// checkElementIndex Checks if the argument is the index of an existing element for the operation op.
+func (l *LinkedList) checkElementIndex(op string, index int) error {
 	if index >= 0 && index < l.size {
 		return nil
 	}
-	return errs.IndexOutOfBound
+	return errs.NewIndexError(op, index, l.size)
 }
 
-// checkPositionIndex Tells if the argument is the index of a error position for an iterator or an add operation.
-func (l *LinkedList) checkPositionIndex(index int) error {
+// checkPositionIndex Checks if the argument is the index of a valid position for an iterator or an add operation op.
+func (l *LinkedList) checkPositionIndex(op string, index int) error {
 	if index >= 0 && index <= l.size {
 		return nil
 	}
-	return errs.IndexOutOfBound
+	return errs.NewIndexError(op, index, l.size)
 }
 
 // Get Returns the element at the specified position in this list.
 func (l *LinkedList) Get(index int) (collection.Element, error) {
-	err := l.checkElementIndex(index)
+	err := l.checkElementIndex("LinkedList.Get", index)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +298,7 @@ func (l *LinkedList) getNode(index int) *linkedNode {
 
 // Set Replaces the element at the specified position in this list with the specified element.
 func (l *LinkedList) Set(index int, e collection.Element) (collection.Element, error) {
-	err := l.checkElementIndex(index)
+	err := l.checkElementIndex("LinkedList.Set", index)
 	if err != nil {
 		return nil, err
 	}
@@ -296,7 +311,7 @@ func (l *LinkedList) Set(index int, e collection.Element) (collection.Element, e
 
 // AddIndex Inserts the element at the specified position in this list.
 func (l *LinkedList) AddIndex(index int, e collection.Element) error {
-	err := l.checkPositionIndex(index)
+	err := l.checkPositionIndex("LinkedList.AddIndex", index)
 	if err != nil {
 		return err
 	}
@@ -346,7 +361,7 @@ func (l *LinkedList) linkLast(e collection.Element) {
 // Shifts any subsequent elements to the left (subtracts one from their indices).
 // Returns the element that was removed from the list.
 func (l *LinkedList) RemoveIndex(index int) (collection.Element, error) {
-	if err := l.checkElementIndex(index); err != nil {
+	if err := l.checkElementIndex("LinkedList.RemoveIndex", index); err != nil {
 		return nil, err
 	}
 	return l.unLink(l.getNode(index)), nil
@@ -474,7 +489,7 @@ func (l *LinkedList) RetainAll(c collection.Collection) (bool, error) {
 // Clear Removes all of the elements from this list.
 // The list will be empty after this call returns.
 func (l *LinkedList) Clear() error {
-	for n := l.last; n != nil; {
+	for n := l.first; n != nil; {
 		next := n.next
 		n.elem = nil
 		n.prev = nil
@@ -517,6 +532,58 @@ func (l *LinkedList) Slice() []collection.Element {
 	return elements
 }
 
+// ForEach Performs the given action for each element of this list in sequential order.
+func (l *LinkedList) ForEach(action function.Consumer) {
+	for x := l.first; x != nil; x = x.next {
+		action(x.elem)
+	}
+}
+
+// RemoveIf Removes all of the elements of this list that satisfy the given predicate.
+//
+// Returns true if any elements were removed.
+// Returns errs.NilPointer if the predicate is nil.
+func (l *LinkedList) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NewError(errs.NilPointer, "LinkedList.RemoveIf")
+	}
+	modified := false
+	for x := l.first; x != nil; {
+		next := x.next
+		if filter(x.elem) {
+			l.unLink(x)
+			modified = true
+		}
+		x = next
+	}
+	return modified, nil
+}
+
+// ReplaceAll Replaces each element of this list with the result of applying the operator to that element.
+func (l *LinkedList) ReplaceAll(operator function.UnaryOperator) {
+	for x := l.first; x != nil; x = x.next {
+		x.elem = operator(x.elem)
+	}
+}
+
+// Sort Sorts this list according to the order induced by the specified comparator.
+//
+// The sort is stable. If the comparator is nil, function.NaturalOrder is used.
+func (l *LinkedList) Sort(c function.Comparator) {
+	if c == nil {
+		c = function.NaturalOrder
+	}
+	elements := l.Slice()
+	sort.SliceStable(elements, func(i, j int) bool {
+		return c(elements[i], elements[j]) < 0
+	})
+	i := 0
+	for x := l.first; x != nil; x = x.next {
+		x.elem = elements[i]
+		i++
+	}
+}
+
 // Iterator Returns a iterator of list.
 func (l *LinkedList) Iterator() collection.Iterator {
 	return &itrLinkedList{
@@ -576,7 +643,7 @@ func (itr *itrLinkedList) HasNext() bool {
 // Next Returns the next element in the list and advances the cursor position.
 func (itr *itrLinkedList) Next() (collection.Element, error) {
 	if !itr.HasNext() {
-		return nil, errs.IndexOutOfBound
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.Iterator.Next")
 	}
 	itr.lastReturn = itr.next
 	itr.next = itr.next.next
@@ -590,7 +657,7 @@ func (itr *itrLinkedList) Next() (collection.Element, error) {
 // It can be made only if add has not been called after the last call to next or previous.
 func (itr *itrLinkedList) Remove() error {
 	if itr.lastReturn == nil {
-		return errs.IllegalState
+		return errs.NewError(errs.IllegalState, "LinkedList.Iterator.Remove")
 	}
 	lastNext := itr.lastReturn.next
 	itr.data.unLink(itr.lastReturn)
@@ -602,3 +669,98 @@ func (itr *itrLinkedList) Remove() error {
 	itr.lastReturn = nil
 	return nil
 }
+
+// itrDescendingLinkedList 实现链表逆序迭代器
+type itrDescendingLinkedList struct {
+	data       *LinkedList
+	next       *linkedNode
+	lastReturn *linkedNode
+}
+
+// HasNext Returns true if this iterator has more elements when traversing the list in the reverse direction.
+func (itr *itrDescendingLinkedList) HasNext() bool {
+	return itr.next != nil
+}
+
+// Next Returns the previous element in the list and moves the cursor position backwards.
+func (itr *itrDescendingLinkedList) Next() (collection.Element, error) {
+	if !itr.HasNext() {
+		return nil, errs.NewError(errs.NoSuchElement, "LinkedList.DescendingIterator.Next")
+	}
+	itr.lastReturn = itr.next
+	itr.next = itr.next.prev
+	return itr.lastReturn.elem, nil
+}
+
+// Remove Removes from the list the last element that was returned by next.
+//
+// This call can only be made once per call to next.
+func (itr *itrDescendingLinkedList) Remove() error {
+	if itr.lastReturn == nil {
+		return errs.NewError(errs.IllegalState, "LinkedList.DescendingIterator.Remove")
+	}
+	itr.data.unLink(itr.lastReturn)
+	itr.lastReturn = nil
+	return nil
+}
+
+// SetElementType Sets the type of elements when decoding.
+//
+// UnmarshalJSON decodes every element into this type,
+// MarshalBinary and UnmarshalBinary register this type with encoding/gob automatically.
+func (l *LinkedList) SetElementType(t reflect.Type) {
+	l.elementType = t
+}
+
+// MarshalJSON Implements json.Marshaler, encoding the list as a JSON array.
+func (l *LinkedList) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(l.Slice())
+}
+
+// UnmarshalJSON Implements json.Unmarshaler, replacing all of the elements with a JSON array.
+func (l *LinkedList) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, l.elementType)
+	if err != nil {
+		return err
+	}
+	l.setElements(elements)
+	return nil
+}
+
+// MarshalBinary Implements encoding.BinaryMarshaler.
+func (l *LinkedList) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(l.Slice(), l.elementType)
+}
+
+// UnmarshalBinary Implements encoding.BinaryUnmarshaler, replacing all of the elements.
+func (l *LinkedList) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, l.elementType)
+	if err != nil {
+		return err
+	}
+	l.setElements(elements)
+	return nil
+}
+
+// GobEncode Implements gob.GobEncoder.
+func (l *LinkedList) GobEncode() ([]byte, error) {
+	return l.MarshalBinary()
+}
+
+// GobDecode Implements gob.GobDecoder.
+func (l *LinkedList) GobDecode(data []byte) error {
+	return l.UnmarshalBinary(data)
+}
+
+// String Returns a string representation of this list, such as [1, 2, 3].
+func (l *LinkedList) String() string {
+	return codec.String(l.Slice())
+}
+
+// setElements Replaces all of the elements with the specified elements.
+func (l *LinkedList) setElements(elements []collection.Element) {
+	_ = l.Clear()
+	for _, e := range elements {
+		l.linkLast(e)
+	}
+}
@@ -18,7 +18,10 @@
 package list
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
 	"github.com/chenquan/go-util/errs"
 	"github.com/stretchr/testify/assert"
 	"reflect"
@@ -147,7 +150,7 @@ func TestLinkedList_AddAllIndex(t *testing.T) {
 	}
 	isAdd, err = list.AddAllIndex(-1, sliceList)
 	assert.Equal(t, false, isAdd)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{"1", "a", "b", "b", "c", 2, "3"}, linkedToSlice(list))
 
 }
@@ -190,7 +193,7 @@ func TestLinkedList_AddIndex(t *testing.T) {
 	assert.Equal(t, []collection.Element{2, 1}, linkedToSlice(list))
 
 	err = list.AddIndex(3, 2)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{2, 1}, linkedToSlice(list))
 
 	err = list.AddIndex(1, 3)
@@ -308,7 +311,7 @@ func TestLinkedList_Element(t *testing.T) {
 		element collection.Element
 	)
 	element, err = list.Element()
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	assert.Equal(t, nil, element)
 
 	list = genLinkedList([]collection.Element{"1", 2, 3}...)
@@ -353,7 +356,7 @@ func TestLinkedList_Get(t *testing.T) {
 	)
 
 	element, err = list.Get(0)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, nil, element)
 
 	list = genLinkedList([]collection.Element{"1", 2, 3}...)
@@ -374,7 +377,7 @@ func TestLinkedList_GetFirst(t *testing.T) {
 		element collection.Element
 	)
 	element, err = list.GetFirst()
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	assert.Equal(t, nil, element)
 
 	list = genLinkedList([]collection.Element{"1", 2, 3}...)
@@ -392,7 +395,7 @@ func TestLinkedList_GetLast(t *testing.T) {
 		element collection.Element
 	)
 	element, err = list.GetLast()
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	assert.Equal(t, nil, element)
 
 	list = genLinkedList([]collection.Element{"1", 2, 3}...)
@@ -521,7 +524,7 @@ func TestLinkedList_Pop(t *testing.T) {
 	)
 
 	pop, err = list.Pop()
-	assert.Equal(t, 3, pop)
+	assert.Equal(t, "1", pop)
 	assert.Equal(t, nil, err)
 
 	pop, err = list.Pop()
@@ -529,12 +532,12 @@ func TestLinkedList_Pop(t *testing.T) {
 	assert.Equal(t, nil, err)
 
 	pop, err = list.Pop()
-	assert.Equal(t, "1", pop)
+	assert.Equal(t, 3, pop)
 	assert.Equal(t, nil, err)
 
 	pop, err = list.Pop()
 	assert.Equal(t, nil, pop)
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 
 }
 
@@ -639,7 +642,7 @@ func TestLinkedList_RemoveFirst(t *testing.T) {
 
 	first, err = list.RemoveFirst()
 	assert.Equal(t, nil, first)
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	assert.Equal(t, []collection.Element{}, linkedToSlice(list))
 
 }
@@ -684,15 +687,15 @@ func TestLinkedList_RemoveIndex(t *testing.T) {
 
 	e, err = list.RemoveIndex(0)
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
 	e, err = list.RemoveIndex(-1)
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
 	e, err = list.RemoveIndex(1)
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
 }
 
@@ -717,7 +720,7 @@ func TestLinkedList_RemoveLast(t *testing.T) {
 
 	e, err = list.RemoveLast()
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 
 }
 
@@ -788,12 +791,12 @@ func TestLinkedList_Set(t *testing.T) {
 
 	e, err = list.Set(-1, "2")
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{1, "2222", 3}, linkedToSlice(list))
 
 	e, err = list.Set(list.size, "2")
 	assert.Equal(t, nil, e)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{1, "2222", 3}, linkedToSlice(list))
 
 }
@@ -817,37 +820,37 @@ func TestLinkedList_SubList(t *testing.T) {
 func TestLinkedList_checkElementIndex(t *testing.T) {
 	var err error
 	list := genLinkedList([]collection.Element{"1", 2, 3}...)
-	err = list.checkElementIndex(0)
+	err = list.checkElementIndex("LinkedList.Get", 0)
 	assert.Equal(t, nil, err)
 
-	err = list.checkElementIndex(2)
+	err = list.checkElementIndex("LinkedList.Get", 2)
 	assert.Equal(t, nil, err)
 
-	err = list.checkElementIndex(-1)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	err = list.checkElementIndex("LinkedList.Get", -1)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
-	err = list.checkElementIndex(3)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	err = list.checkElementIndex("LinkedList.Get", 3)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
-	err = list.checkElementIndex(4)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	err = list.checkElementIndex("LinkedList.Get", 4)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
 }
 
 func TestLinkedList_checkPositionIndex(t *testing.T) {
 	var err error
 	list := genLinkedList([]collection.Element{"1", 2, 3}...)
-	err = list.checkPositionIndex(0)
+	err = list.checkPositionIndex("LinkedList.AddIndex", 0)
 	assert.Equal(t, nil, err)
 
-	err = list.checkPositionIndex(3)
+	err = list.checkPositionIndex("LinkedList.AddIndex", 3)
 	assert.Equal(t, nil, err)
 
-	err = list.checkPositionIndex(4)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	err = list.checkPositionIndex("LinkedList.AddIndex", 4)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
-	err = list.checkPositionIndex(-1)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	err = list.checkPositionIndex("LinkedList.AddIndex", -1)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 
 }
 
@@ -912,7 +915,7 @@ func TestLinkedList_Delete(t *testing.T) {
 
 	first, err = list.Delete()
 	assert.Equal(t, nil, first)
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	assert.Equal(t, []collection.Element{}, linkedToSlice(list))
 
 }
@@ -962,9 +965,78 @@ func Test_itrLinkedList(t *testing.T) {
 	hasNext = linkedList.HasNext()
 	assert.False(t, hasNext)
 	next, err = linkedList.Next()
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 	err = linkedList.Remove()
-	assert.Equal(t, errs.IllegalState, err)
+	assert.True(t, errors.Is(err, errs.IllegalState))
 	assert.Equal(t, []collection.Element{}, linkedToSlice(list))
 
 }
+
+func TestLinkedList_MarshalJSON(t *testing.T) {
+	list := genLinkedList("a", "b", "c")
+	data, err := json.Marshal(list)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `["a","b","c"]`, string(data))
+
+	decoded := NewLinkedList()
+	_, _ = decoded.Add("x")
+	err = json.Unmarshal(data, decoded)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []collection.Element{"a", "b", "c"}, linkedToSlice(decoded))
+	assert.Equal(t, 3, decoded.Size())
+	assert.Equal(t, "[a, b, c]", decoded.String())
+
+	data, err = list.MarshalBinary()
+	assert.Equal(t, nil, err)
+	decoded = NewLinkedList()
+	assert.Equal(t, nil, decoded.GobDecode(data))
+	assert.Equal(t, []collection.Element{"a", "b", "c"}, linkedToSlice(decoded))
+}
+
+func TestLinkedList_RemoveIf(t *testing.T) {
+	list := genLinkedList([]collection.Element{1, 2, 3, 4}...)
+	modified, err := list.RemoveIf(func(e interface{}) bool {
+		return e.(int) != 3
+	})
+	assert.Equal(t, nil, err)
+	assert.True(t, modified)
+	assert.Equal(t, 1, list.size)
+	assert.Equal(t, list.first, list.last)
+	assert.Equal(t, []collection.Element{3}, linkedToSlice(list))
+
+	modified, err = list.RemoveIf(nil)
+	assert.True(t, errors.Is(err, errs.NilPointer))
+	assert.False(t, modified)
+}
+
+func TestLinkedList_Sort(t *testing.T) {
+	list := genLinkedList([]collection.Element{3, 1, 2}...)
+	first := list.first
+	list.Sort(nil)
+	assert.Equal(t, []collection.Element{1, 2, 3}, linkedToSlice(list))
+	// 排序只交换元素,不重新分配节点
+	assert.Equal(t, first, list.first)
+}
+
+func TestLinkedList_Conformance(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		collectiontest.TestList(t, func() collection.List {
+			return NewLinkedList()
+		})
+	})
+	t.Run("DeQueue", func(t *testing.T) {
+		collectiontest.TestDeQueue(t, func() collection.DeQueue {
+			return NewLinkedList()
+		})
+	})
+	t.Run("ListModel", func(t *testing.T) {
+		collectiontest.TestListModel(t, func() collection.List {
+			return NewLinkedList()
+		})
+	})
+	t.Run("DeQueueModel", func(t *testing.T) {
+		collectiontest.TestDeQueueModel(t, func() collection.DeQueue {
+			return NewLinkedList()
+		})
+	})
+}
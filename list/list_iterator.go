@@ -37,7 +37,7 @@ func (s *itrList) HasNext() bool {
 // Next 返回当前迭代中的下一个元素
 func (s *itrList) Next() (collection.Element, error) {
 	if s.cursor >= s.data.Size() {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "List.Iterator.Next")
 	}
 	s.lastRet = s.cursor
 	s.cursor++
@@ -49,7 +49,7 @@ func (s *itrList) Next() (collection.Element, error) {
 // 每次调用 Next 方法,才可以调用一次此方法.
 func (s *itrList) Remove() error {
 	if s.lastRet < 0 {
-		return errs.IllegalState
+		return errs.NewError(errs.IllegalState, "List.Iterator.Remove")
 	}
 	_, err := s.data.RemoveIndex(s.lastRet)
 	if err == nil {
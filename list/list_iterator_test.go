@@ -18,6 +18,7 @@
 package list
 
 import (
+	"errors"
 	"github.com/chenquan/go-util/backend/collection"
 	"github.com/chenquan/go-util/errs"
 	"github.com/stretchr/testify/assert"
@@ -62,9 +63,9 @@ func Test_sliceListIterator(t *testing.T) {
 	next, err = l.Next()
 	assert.Equal(t, false, hasNext)
 	assert.Equal(t, nil, next)
-	assert.Equal(t, errs.NoSuchElement, err)
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
 
 	err2 = l.Remove()
-	assert.Equal(t, errs.IllegalState, err2)
+	assert.True(t, errors.Is(err2, errs.IllegalState))
 
 }
@@ -18,8 +18,13 @@
 package list
 
 import (
+	"reflect"
+	"sort"
+
 	"github.com/chenquan/go-util/backend/collection"
 	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
 )
 
 var _ collection.List = (*SliceList)(nil)
@@ -59,8 +64,9 @@ func NewSliceList(initialCapacity int) *SliceList {
 // SliceList 实现 collection.List 接口
 // 注意 SliceList 协程不安全,不能用于高并发.
 type SliceList struct {
-	size int                  // 列表大小
-	data []collection.Element // 数据
+	size        int                  // 列表大小
+	data        []collection.Element // 数据
+	elementType reflect.Type         // 解码时元素的类型
 }
 
 // Slice 返回当前切片列表所有元素的切片
@@ -172,9 +178,12 @@ func (sliceList *SliceList) batchRemove(c collection.Collection, complement bool
 			w++
 		}
 	}
-	if r != 0 {
-		// r 不为0 说明元素已更改
-		// 剔除多余元素
+	if w != size {
+		// w 不等于 size 说明有元素被删除
+		// 清除多余元素的引用,便于垃圾回收
+		for i := w; i < size; i++ {
+			data[i] = nil
+		}
 		sliceList.data = sliceList.data[:w]
 		modified = true
 		sliceList.size = w
@@ -223,10 +232,10 @@ func (sliceList *SliceList) Equals(c collection.Collection) (b bool) {
 
 }
 
-// rangeCheckForAdd 检查新增操作索引范围
-func (sliceList *SliceList) rangeCheckForAdd(index int) error {
+// rangeCheckForAdd 检查操作 op 中新增操作索引范围
+func (sliceList *SliceList) rangeCheckForAdd(op string, index int) error {
 	if index > sliceList.size || index < 0 {
-		return errs.IndexOutOfBound
+		return errs.NewIndexError(op, index, sliceList.size)
 	}
 	return nil
 }
@@ -237,7 +246,7 @@ func (sliceList *SliceList) rangeCheckForAdd(index int) error {
 // 新元素将按照指定集合的迭代器返回的顺序显示在此列表中,
 // 如果在操作进行过程中修改了指定的集合,则此操作的行为是不确定的.
 func (sliceList *SliceList) AddAllIndex(index int, c collection.Collection) (bool, error) {
-	if err := sliceList.rangeCheckForAdd(index); err != nil {
+	if err := sliceList.rangeCheckForAdd("SliceList.AddAllIndex", index); err != nil {
 		return false, err
 	}
 	slice := c.Slice()
@@ -252,25 +261,27 @@ func (sliceList *SliceList) AddAllIndex(index int, c collection.Collection) (boo
 	return false, nil
 }
 
+// rangeCheck 检查操作 op 中访问操作索引范围
+func (sliceList *SliceList) rangeCheck(op string, index int) error {
+	if index >= sliceList.size || index < 0 {
+		return errs.NewIndexError(op, index, sliceList.size)
+	}
+	return nil
+}
+
 // Get 返回此列表中指定位置的元素
 func (sliceList *SliceList) Get(index int) (e collection.Element, err error) {
-	size := sliceList.size
-	if index >= size || index < 0 {
-		err = errs.IndexOutOfBound
-	} else {
+	if err = sliceList.rangeCheck("SliceList.Get", index); err == nil {
 		e = sliceList.data[index]
 	}
 	return
 }
 
-// Set 用指定的元素替换此列表中指定位置的元素
+// Set 用指定的元素替换此列表中指定位置的元素,并返回该位置原来的元素
 func (sliceList *SliceList) Set(index int, e collection.Element) (elem collection.Element, err error) {
-	size := sliceList.size
-	if index >= size {
-		err = errs.IndexOutOfBound
-	} else {
+	if err = sliceList.rangeCheck("SliceList.Set", index); err == nil {
+		elem = sliceList.data[index]
 		sliceList.data[index] = e
-		elem = e
 	}
 	return
 }
@@ -279,7 +290,7 @@ func (sliceList *SliceList) Set(index int, e collection.Element) (elem collectio
 //
 // 将当前在该位置的元素(如果有)和任何后续元素右移(即将其索引加一).
 func (sliceList *SliceList) AddIndex(index int, e collection.Element) error {
-	if err := sliceList.rangeCheckForAdd(index); err != nil {
+	if err := sliceList.rangeCheckForAdd("SliceList.AddIndex", index); err != nil {
 		return err
 	}
 	sliceList.data = append(sliceList.data, e)
@@ -293,8 +304,8 @@ func (sliceList *SliceList) AddIndex(index int, e collection.Element) error {
 //
 // 将所有后续元素向左移动(即将其索引中减去1),并返回从列表中删除的元素.
 func (sliceList *SliceList) RemoveIndex(index int) (collection.Element, error) {
-	if index >= sliceList.size {
-		return nil, errs.IndexOutOfBound
+	if err := sliceList.rangeCheck("SliceList.RemoveIndex", index); err != nil {
+		return nil, err
 	}
 	element := sliceList.data[index]
 	sliceList.data = append(sliceList.data[:index], sliceList.data[index+1:]...)
@@ -324,6 +335,64 @@ func (sliceList *SliceList) LastIndex(e collection.Element) (index int) {
 	return -1
 }
 
+// ForEach 按索引顺序对列表中的每个元素执行 action
+func (sliceList *SliceList) ForEach(action function.Consumer) {
+	for _, e := range sliceList.data[:sliceList.size] {
+		action(e)
+	}
+}
+
+// RemoveIf 删除列表中满足 filter 的所有元素
+//
+// 只遍历一次列表,将保留的元素依次前移.
+// 如果有元素被删除则返回 true,否则返回 false.
+// 如果 filter 为 nil 则返回 errs.NilPointer.
+func (sliceList *SliceList) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NewError(errs.NilPointer, "SliceList.RemoveIf")
+	}
+	data := sliceList.data
+	size := sliceList.size
+	w := 0
+	for r := 0; r < size; r++ {
+		if !filter(data[r]) {
+			data[w] = data[r]
+			w++
+		}
+	}
+	if w == size {
+		return false, nil
+	}
+	// 清除多余元素的引用,便于垃圾回收
+	for i := w; i < size; i++ {
+		data[i] = nil
+	}
+	sliceList.data = data[:w]
+	sliceList.size = w
+	return true, nil
+}
+
+// ReplaceAll 使用 operator 作用于每个元素的结果替换该元素
+func (sliceList *SliceList) ReplaceAll(operator function.UnaryOperator) {
+	data := sliceList.data[:sliceList.size]
+	for i, e := range data {
+		data[i] = operator(e)
+	}
+}
+
+// Sort 按 c 对列表进行稳定排序
+//
+// 如果 c 为 nil,则按 function.NaturalOrder 排序.
+func (sliceList *SliceList) Sort(c function.Comparator) {
+	if c == nil {
+		c = function.NaturalOrder
+	}
+	data := sliceList.data[:sliceList.size]
+	sort.SliceStable(data, func(i, j int) bool {
+		return c(data[i], data[j]) < 0
+	})
+}
+
 // Iterator 返回当前集合中元素的迭代器
 //
 // 如果当前集合是有序的,则保证返回的迭代器是有序的.
@@ -334,3 +403,65 @@ func (sliceList *SliceList) Iterator() collection.Iterator {
 		data:    sliceList,
 	}
 }
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (sliceList *SliceList) SetElementType(t reflect.Type) {
+	sliceList.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,将列表编码为 JSON 数组
+func (sliceList *SliceList) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(sliceList.Slice())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换列表中的所有元素
+func (sliceList *SliceList) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, sliceList.elementType)
+	if err != nil {
+		return err
+	}
+	sliceList.setElements(elements)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (sliceList *SliceList) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(sliceList.Slice(), sliceList.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换列表中的所有元素
+func (sliceList *SliceList) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, sliceList.elementType)
+	if err != nil {
+		return err
+	}
+	sliceList.setElements(elements)
+	return nil
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (sliceList *SliceList) GobEncode() ([]byte, error) {
+	return sliceList.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (sliceList *SliceList) GobDecode(data []byte) error {
+	return sliceList.UnmarshalBinary(data)
+}
+
+// String 返回列表的字符串表示,形如 [1, 2, 3]
+func (sliceList *SliceList) String() string {
+	return codec.String(sliceList.Slice())
+}
+
+// setElements 使用指定元素替换列表中的所有元素
+func (sliceList *SliceList) setElements(elements []collection.Element) {
+	if elements == nil {
+		elements = make([]collection.Element, 0, defaultCapacity)
+	}
+	sliceList.data = elements
+	sliceList.size = len(elements)
+}
@@ -18,7 +18,12 @@
 package list
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
 	"github.com/chenquan/go-util/errs"
 	"github.com/stretchr/testify/assert"
 	"reflect"
@@ -544,13 +549,13 @@ func TestSliceList_AddAllIndex(t *testing.T) {
 	assert.Equal(t, true, b)
 
 	b, err = sliceList.AddAllIndex(-1, sliceList2)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, 12, sliceList.size)
 	assert.Equal(t, []collection.Element{"1", "1", 2, 3, 2, 3, "1", 2, 3, "1", 2, 3}, sliceList.data)
 	assert.Equal(t, false, b)
 
 	b, err = sliceList.AddAllIndex(sliceList.size+1, sliceList2)
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, 12, sliceList.size)
 	assert.Equal(t, []collection.Element{"1", "1", 2, 3, 2, 3, "1", 2, 3, "1", 2, 3}, sliceList.data)
 	assert.Equal(t, false, b)
@@ -657,7 +662,7 @@ func TestSliceList_Set(t *testing.T) {
 	assert.Equal(t, 3, sliceList.size)
 
 	_, err = sliceList.Set(3, "111")
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{"111", 2, 3}, sliceList.data)
 	assert.Equal(t, 3, sliceList.size)
 
@@ -666,7 +671,7 @@ func TestSliceList_Set(t *testing.T) {
 		data: []collection.Element{},
 	}
 	_, err = sliceList.Set(0, "1")
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, []collection.Element{}, sliceList.data)
 	assert.Equal(t, 0, sliceList.size)
 }
@@ -694,7 +699,7 @@ func TestSliceList_AddIndex(t *testing.T) {
 	assert.Equal(t, []collection.Element{1111, 1, "1111"}, sliceList.data)
 
 	err = sliceList.AddIndex(8, "1111")
-	assert.Equal(t, errs.IndexOutOfBound, err)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
 	assert.Equal(t, 3, sliceList.size)
 	assert.Equal(t, []collection.Element{1111, 1, "1111"}, sliceList.data)
 
@@ -895,3 +900,95 @@ func TestSliceList_LastIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestSliceList_MarshalJSON(t *testing.T) {
+	sliceList := NewSliceListDefault()
+	_, _ = sliceList.Add(1)
+	_, _ = sliceList.Add(2)
+	data, err := json.Marshal(sliceList)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[1,2]", string(data))
+
+	decoded := NewSliceListDefault()
+	decoded.SetElementType(reflect.TypeOf(0))
+	err = json.Unmarshal(data, decoded)
+	assert.Equal(t, nil, err)
+	assert.True(t, sliceList.Equals(decoded))
+
+	err = json.Unmarshal([]byte("{}"), decoded)
+	assert.NotNil(t, err)
+	assert.Equal(t, "[1, 2]", decoded.String())
+}
+
+func TestSliceList_GobEncode(t *testing.T) {
+	sliceList := NewSliceListDefault()
+	_, _ = sliceList.Add(1)
+	_, _ = sliceList.Add("a")
+	var buf bytes.Buffer
+	assert.Equal(t, nil, gob.NewEncoder(&buf).Encode(sliceList))
+
+	decoded := NewSliceListDefault()
+	assert.Equal(t, nil, gob.NewDecoder(&buf).Decode(decoded))
+	assert.Equal(t, []collection.Element{1, "a"}, decoded.Slice())
+
+	data, err := sliceList.MarshalBinary()
+	assert.Equal(t, nil, err)
+	decoded = &SliceList{}
+	assert.Equal(t, nil, decoded.UnmarshalBinary(data))
+	assert.True(t, sliceList.Equals(decoded))
+}
+
+func TestSliceList_RemoveIf(t *testing.T) {
+	sliceList := &SliceList{
+		size: 5,
+		data: []collection.Element{"1", 2, 3, 4, "5"},
+	}
+	modified, err := sliceList.RemoveIf(func(e interface{}) bool {
+		_, ok := e.(int)
+		return ok
+	})
+	assert.Equal(t, nil, err)
+	assert.True(t, modified)
+	assert.Equal(t, 2, sliceList.size)
+	assert.Equal(t, []collection.Element{"1", "5"}, sliceList.data)
+	// 被删除元素的引用已被清除
+	assert.Equal(t, []collection.Element{"1", "5", nil, nil, nil}, sliceList.data[:5])
+
+	modified, err = sliceList.RemoveIf(nil)
+	assert.True(t, errors.Is(err, errs.NilPointer))
+	assert.False(t, modified)
+}
+
+func TestSliceList_Sort(t *testing.T) {
+	sliceList := &SliceList{
+		size: 3,
+		data: []collection.Element{"b", "c", "a", "z"}[:3],
+	}
+	sliceList.Sort(nil)
+	assert.Equal(t, []collection.Element{"a", "b", "c"}, sliceList.data)
+	assert.Equal(t, "z", sliceList.data[:4][3])
+}
+
+func TestSliceList_Conformance(t *testing.T) {
+	newList := func() collection.List {
+		return NewSliceListDefault()
+	}
+	t.Run("List", func(t *testing.T) {
+		collectiontest.TestList(t, newList)
+	})
+	t.Run("ListModel", func(t *testing.T) {
+		collectiontest.TestListModel(t, newList)
+	})
+}
+
+func TestSliceList_IndexError(t *testing.T) {
+	l := NewSliceListDefault()
+	_, _ = l.Add(1)
+	_, err := l.Get(3)
+	var e *errs.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "SliceList.Get", e.Op)
+	assert.Equal(t, 3, e.Index)
+	assert.Equal(t, 1, e.Size)
+	assert.Equal(t, "SliceList.Get: index out of bound (index 3, size 1)", err.Error())
+}
@@ -0,0 +1,297 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/queue"
+	xtime "github.com/chenquan/go-util/time"
+)
+
+// 异步写入器的默认配置
+const (
+	DefaultBufferSize    = 1024
+	DefaultBatchSize     = 128
+	DefaultFlushInterval = time.Second
+)
+
+// OverflowPolicy 异步写入器的缓冲区已满时写入新消息的策略
+type OverflowPolicy int
+
+const (
+	// Block 阻塞直到缓冲区有空闲位置
+	Block OverflowPolicy = iota
+	// DropNewest 丢弃新消息
+	DropNewest
+	// DropOldest 丢弃缓冲区中最旧的消息
+	DropOldest
+)
+
+// AsyncConfig 异步写入器的配置
+type AsyncConfig struct {
+	// BufferSize 缓冲区最多容纳的消息条数,小于等于0时为 DefaultBufferSize
+	BufferSize int
+	// BatchSize 缓冲的消息达到该条数时立即批量写入,小于等于0时为 DefaultBatchSize,最大为 BufferSize
+	BatchSize int
+	// FlushInterval 批量写入的时间间隔,小于等于0时为 DefaultFlushInterval
+	FlushInterval time.Duration
+	// Policy 缓冲区已满时的策略,默认为 Block
+	Policy OverflowPolicy
+	// Clock 时钟,默认为 xtime.SystemClock
+	Clock xtime.Clock
+}
+
+var _ io.WriteCloser = (*AsyncWriter)(nil)
+
+// syncer 支持将数据刷入存储的输出,例如 *os.File
+type syncer interface {
+	Sync() error
+}
+
+// AsyncWriter 异步批量写入器
+//
+// Write 将消息复制到有界缓冲区后立即返回,后台协程按时间间隔或缓冲的条数将消息合并为一次写入.
+// 缓冲区已满时按 OverflowPolicy 阻塞或丢弃消息,被丢弃的消息条数可由 Dropped 获取.
+// 后台写入的错误由之后的 Sync 或 Close 返回.
+// AsyncWriter 协程安全,可以作为任意日志记录器的输出,使用完毕后必须调用 Close.
+type AsyncWriter struct {
+	w             io.Writer
+	policy        OverflowPolicy
+	batchSize     int
+	flushInterval time.Duration
+	clock         xtime.Clock
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	buffer  *queue.RingBuffer // 等待写入的消息
+	err     error             // 上次 Sync 之后的第一个写入错误
+	closed  bool
+	dropped uint64 // 丢弃的消息条数
+
+	kick   chan struct{}   // 通知后台协程立即写入
+	syncs  chan chan error // Sync 请求
+	done   chan struct{}   // 关闭通知
+	exited chan struct{}   // 后台协程已退出
+}
+
+// NewAsyncWriter 创建将消息异步写入 w 的写入器,并启动后台协程
+func NewAsyncWriter(w io.Writer, config AsyncConfig) *AsyncWriter {
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if batchSize > bufferSize {
+		batchSize = bufferSize
+	}
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	clock := config.Clock
+	if clock == nil {
+		clock = xtime.SystemClock
+	}
+	bufferPolicy := queue.Reject
+	if config.Policy == DropOldest {
+		bufferPolicy = queue.Overwrite
+	}
+	a := &AsyncWriter{
+		w:             w,
+		policy:        config.Policy,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		clock:         clock,
+		buffer:        queue.NewRingBufferWithPolicy(bufferSize, bufferPolicy),
+		kick:          make(chan struct{}, 1),
+		syncs:         make(chan chan error),
+		done:          make(chan struct{}),
+		exited:        make(chan struct{}),
+	}
+	a.notFull = sync.NewCond(&a.mu)
+	go a.run()
+	return a
+}
+
+// Write 实现 io.Writer 接口,将 p 的副本放入缓冲区
+//
+// 写入器关闭后返回 errs.IllegalState.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	msg := make([]byte, len(p))
+	copy(msg, p)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.policy == Block && a.buffer.IsFull() && !a.closed {
+		a.notifyFlush()
+		a.notFull.Wait()
+	}
+	if a.closed {
+		return 0, errs.NewError(errs.IllegalState, "AsyncWriter.Write").WithDetail("writer closed")
+	}
+	if a.buffer.IsFull() {
+		atomic.AddUint64(&a.dropped, 1)
+		if a.policy == DropNewest {
+			return len(p), nil
+		}
+	}
+	_, _ = a.buffer.Add(msg)
+	if a.buffer.Size() >= a.batchSize {
+		a.notifyFlush()
+	}
+	return len(p), nil
+}
+
+// Dropped 返回因缓冲区已满被丢弃的消息条数
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Buffered 返回缓冲区中等待写入的消息条数
+func (a *AsyncWriter) Buffered() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.buffer.Size()
+}
+
+// Sync 写入缓冲区中的所有消息,如果输出支持 Sync 则随后调用输出的 Sync
+//
+// 返回上次 Sync 之后后台写入发生的第一个错误.
+func (a *AsyncWriter) Sync() error {
+	reply := make(chan error, 1)
+	select {
+	case a.syncs <- reply:
+		return <-reply
+	case <-a.exited:
+		return a.takeErr()
+	}
+}
+
+// Close 写入缓冲区中的所有消息并停止后台协程,不会关闭输出
+//
+// 阻塞在 Write 中的调用返回 errs.IllegalState.重复调用 Close 返回 nil.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	close(a.done)
+	<-a.exited
+	return a.takeErr()
+}
+
+// notifyFlush 通知后台协程立即写入,调用方需持有锁
+func (a *AsyncWriter) notifyFlush() {
+	select {
+	case a.kick <- struct{}{}:
+	default:
+	}
+}
+
+// run 后台协程,按时间间隔、缓冲条数、Sync 请求与关闭通知写入缓冲区中的消息
+func (a *AsyncWriter) run() {
+	defer close(a.exited)
+	timer := a.clock.NewTimer(a.flushInterval)
+	defer func() { timer.Stop() }()
+	for {
+		select {
+		case <-timer.C():
+			a.flush()
+			timer = a.clock.NewTimer(a.flushInterval)
+		case <-a.kick:
+			a.flush()
+		case reply := <-a.syncs:
+			a.flush()
+			a.sync()
+			reply <- a.takeErr()
+		case <-a.done:
+			a.flush()
+			a.sync()
+			return
+		}
+	}
+}
+
+// flush 取出缓冲区中的所有消息并合并为一次写入
+func (a *AsyncWriter) flush() {
+	var batch bytes.Buffer
+	a.mu.Lock()
+	for e := a.buffer.Poll(); e != nil; e = a.buffer.Poll() {
+		batch.Write(e.([]byte))
+	}
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+	if batch.Len() == 0 {
+		return
+	}
+	if _, err := a.w.Write(batch.Bytes()); err != nil {
+		a.setErr(err)
+	}
+}
+
+// sync 如果输出支持 Sync 则调用之
+//
+// 标准输出等非普通文件不支持 Sync,会被跳过.
+func (a *AsyncWriter) sync() {
+	if f, ok := a.w.(*os.File); ok {
+		if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+			return
+		}
+	}
+	if s, ok := a.w.(syncer); ok {
+		if err := s.Sync(); err != nil {
+			a.setErr(err)
+		}
+	}
+}
+
+// setErr 记录写入错误,只保留第一个
+func (a *AsyncWriter) setErr(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// takeErr 返回并清除记录的写入错误
+func (a *AsyncWriter) takeErr() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.err
+	a.err = nil
+	return err
+}
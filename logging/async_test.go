@@ -0,0 +1,268 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// testWriter 记录写入内容的输出,release 关闭前 Write 会阻塞
+type testWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	writes  int
+	syncs   int
+	err     error
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newTestWriter(blocking bool) *testWriter {
+	w := &testWriter{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	if !blocking {
+		close(w.release)
+	}
+	return w
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *testWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncs++
+	return nil
+}
+
+func (w *testWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter_Sync(t *testing.T) {
+	out := newTestWriter(false)
+	clock := xtime.NewManualClock(time.Now())
+	a := NewAsyncWriter(out, AsyncConfig{Clock: clock})
+	defer a.Close()
+
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		n, err := a.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+	}
+	n, err := a.Write(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 3, a.Buffered())
+	assert.Equal(t, "", out.String())
+
+	assert.Nil(t, a.Sync())
+	assert.Equal(t, 0, a.Buffered())
+	assert.Equal(t, "a\nb\nc\n", out.String())
+	assert.Equal(t, 1, out.writes)
+	assert.Equal(t, 1, out.syncs)
+}
+
+func TestAsyncWriter_Copy(t *testing.T) {
+	out := newTestWriter(false)
+	a := NewAsyncWriter(out, AsyncConfig{Clock: xtime.NewManualClock(time.Now())})
+	p := []byte("a\n")
+	_, _ = a.Write(p)
+	p[0] = 'b'
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "a\n", out.String())
+}
+
+func TestAsyncWriter_FlushInterval(t *testing.T) {
+	out := newTestWriter(false)
+	clock := xtime.NewManualClock(time.Now())
+	a := NewAsyncWriter(out, AsyncConfig{FlushInterval: time.Second, Clock: clock})
+	defer a.Close()
+
+	_, _ = a.Write([]byte("a\n"))
+	clock.WaitTimers(1)
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, 1, a.Buffered())
+	clock.Advance(500 * time.Millisecond)
+	assert.Eventually(t, func() bool { return out.String() == "a\n" }, time.Second, time.Millisecond)
+
+	_, _ = a.Write([]byte("b\n"))
+	clock.WaitTimers(1)
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return out.String() == "a\nb\n" }, time.Second, time.Millisecond)
+}
+
+func TestAsyncWriter_BatchSize(t *testing.T) {
+	out := newTestWriter(false)
+	a := NewAsyncWriter(out, AsyncConfig{BatchSize: 2, Clock: xtime.NewManualClock(time.Now())})
+	defer a.Close()
+
+	_, _ = a.Write([]byte("a\n"))
+	_, _ = a.Write([]byte("b\n"))
+	assert.Eventually(t, func() bool { return out.String() == "a\nb\n" }, time.Second, time.Millisecond)
+}
+
+// fillBlocked 写入 a 并等待后台协程阻塞在输出中,然后写满缓冲区
+func fillBlocked(t *testing.T, a *AsyncWriter, out *testWriter) {
+	_, _ = a.Write([]byte("a\n"))
+	<-out.entered
+	_, _ = a.Write([]byte("b\n"))
+	_, _ = a.Write([]byte("c\n"))
+	assert.Equal(t, 2, a.Buffered())
+}
+
+func TestAsyncWriter_DropNewest(t *testing.T) {
+	out := newTestWriter(true)
+	a := NewAsyncWriter(out, AsyncConfig{BufferSize: 2, BatchSize: 1, Policy: DropNewest, Clock: xtime.NewManualClock(time.Now())})
+	fillBlocked(t, a, out)
+
+	n, err := a.Write([]byte("d\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, uint64(1), a.Dropped())
+	close(out.release)
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "a\nb\nc\n", out.String())
+}
+
+func TestAsyncWriter_DropOldest(t *testing.T) {
+	out := newTestWriter(true)
+	a := NewAsyncWriter(out, AsyncConfig{BufferSize: 2, BatchSize: 1, Policy: DropOldest, Clock: xtime.NewManualClock(time.Now())})
+	fillBlocked(t, a, out)
+
+	_, _ = a.Write([]byte("d\n"))
+	_, _ = a.Write([]byte("e\n"))
+	assert.Equal(t, uint64(2), a.Dropped())
+	close(out.release)
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "a\nd\ne\n", out.String())
+}
+
+func TestAsyncWriter_Block(t *testing.T) {
+	out := newTestWriter(true)
+	a := NewAsyncWriter(out, AsyncConfig{BufferSize: 2, BatchSize: 1, Policy: Block, Clock: xtime.NewManualClock(time.Now())})
+	fillBlocked(t, a, out)
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := a.Write([]byte("d\n"))
+		written <- err
+	}()
+	select {
+	case <-written:
+		t.Fatal("write should block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(out.release)
+	assert.Nil(t, <-written)
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "a\nb\nc\nd\n", out.String())
+	assert.Equal(t, uint64(0), a.Dropped())
+}
+
+func TestAsyncWriter_CloseUnblocksWriters(t *testing.T) {
+	out := newTestWriter(true)
+	a := NewAsyncWriter(out, AsyncConfig{BufferSize: 2, BatchSize: 1, Clock: xtime.NewManualClock(time.Now())})
+	fillBlocked(t, a, out)
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := a.Write([]byte("d\n"))
+		written <- err
+	}()
+	closed := make(chan error, 1)
+	go func() { closed <- a.Close() }()
+	assert.True(t, errors.Is(<-written, errs.IllegalState))
+	close(out.release)
+	assert.Nil(t, <-closed)
+	assert.Equal(t, "a\nb\nc\n", out.String())
+}
+
+func TestAsyncWriter_Close(t *testing.T) {
+	out := newTestWriter(false)
+	a := NewAsyncWriter(out, AsyncConfig{})
+	_, _ = a.Write([]byte("a\n"))
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "a\n", out.String())
+	assert.Equal(t, 1, out.syncs)
+
+	assert.Nil(t, a.Close())
+	assert.Nil(t, a.Sync())
+	_, err := a.Write([]byte("b\n"))
+	assert.True(t, errors.Is(err, errs.IllegalState))
+}
+
+func TestAsyncWriter_Error(t *testing.T) {
+	out := newTestWriter(false)
+	out.err = errors.New("disk full")
+	a := NewAsyncWriter(out, AsyncConfig{Clock: xtime.NewManualClock(time.Now())})
+
+	_, _ = a.Write([]byte("a\n"))
+	assert.Equal(t, out.err, a.Sync())
+	assert.Nil(t, a.Sync())
+	_, _ = a.Write([]byte("b\n"))
+	assert.Equal(t, out.err, a.Close())
+}
+
+func TestAsyncWriter_Logger(t *testing.T) {
+	out := newTestWriter(false)
+	a := NewAsyncWriter(out, AsyncConfig{BufferSize: 16, BatchSize: 4})
+	l := NewLogger(a, DEBUG)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.Info("message", "goroutine", i, "seq", j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Nil(t, a.Close())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 200, len(lines))
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "[INFO][async_test.go:"), line)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+
+	xtime "github.com/chenquan/go-util/time"
+)
+
+// consoleTimeFormat 控制台格式默认的时间格式,与 log.LstdFlags 相同
+const consoleTimeFormat = "2006/01/02 15:04:05"
+
+// badKey 键值对中缺少键或键不是字符串时使用的键
+const badKey = "!BADKEY"
+
+// Field 日志中的键值对
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry 一条待编码的日志
+type Entry struct {
+	Time    time.Time // 时间
+	Level   Level     // 级别
+	Caller  string    // 调用位置,形如 file.go:12,未知时为空
+	Message string    // 内容
+	Fields  []Field   // 键值对,按添加顺序排列
+}
+
+// Encoder 将日志编码为一行以换行结尾的文本
+//
+// Encoder 必须协程安全.
+type Encoder interface {
+	Encode(e *Entry) []byte
+}
+
+// ConsoleEncoder 控制台格式编码器
+//
+// 格式为 [级别][文件:行号]时间 内容 k1=v1 k2=v2.
+type ConsoleEncoder struct {
+	TimeFormat string // 时间格式,为空时使用 log.LstdFlags 的格式
+}
+
+// NewConsoleEncoder 创建控制台格式编码器
+func NewConsoleEncoder() *ConsoleEncoder {
+	return &ConsoleEncoder{TimeFormat: consoleTimeFormat}
+}
+
+// Encode 实现 Encoder 接口
+func (c *ConsoleEncoder) Encode(e *Entry) []byte {
+	var b bytes.Buffer
+	b.WriteString("[")
+	b.WriteString(e.Level.String())
+	b.WriteString("]")
+	if e.Caller != "" {
+		b.WriteString("[")
+		b.WriteString(e.Caller)
+		b.WriteString("]")
+	}
+	b.WriteString(e.Time.Format(timeFormat(c.TimeFormat, consoleTimeFormat)))
+	b.WriteString(" ")
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		b.WriteString(" ")
+		writeLogfmtPair(&b, f.Key, valueString(f.Value))
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// LogfmtEncoder logfmt 格式编码器
+//
+// 格式为 time="2006-01-02 15:04:05" level=INFO caller=file.go:12 msg=内容 k1=v1.
+type LogfmtEncoder struct {
+	TimeFormat string // 时间格式,为空时使用 time.DateTimeFormat
+}
+
+// NewLogfmtEncoder 创建 logfmt 格式编码器
+func NewLogfmtEncoder() *LogfmtEncoder {
+	return &LogfmtEncoder{TimeFormat: xtime.DateTimeFormat}
+}
+
+// Encode 实现 Encoder 接口
+func (l *LogfmtEncoder) Encode(e *Entry) []byte {
+	var b bytes.Buffer
+	writeLogfmtPair(&b, "time", e.Time.Format(timeFormat(l.TimeFormat, xtime.DateTimeFormat)))
+	b.WriteString(" ")
+	writeLogfmtPair(&b, "level", e.Level.String())
+	if e.Caller != "" {
+		b.WriteString(" ")
+		writeLogfmtPair(&b, "caller", e.Caller)
+	}
+	b.WriteString(" ")
+	writeLogfmtPair(&b, "msg", e.Message)
+	for _, f := range e.Fields {
+		b.WriteString(" ")
+		writeLogfmtPair(&b, f.Key, valueString(f.Value))
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// JSONEncoder JSON 格式编码器
+//
+// 每条日志为一个 JSON 对象,依次包含 time、level、caller、msg 以及按添加顺序排列的键值对.
+type JSONEncoder struct {
+	TimeFormat string // 时间格式,为空时使用 time.DateTimeFormat
+}
+
+// NewJSONEncoder 创建 JSON 格式编码器
+func NewJSONEncoder() *JSONEncoder {
+	return &JSONEncoder{TimeFormat: xtime.DateTimeFormat}
+}
+
+// Encode 实现 Encoder 接口
+func (j *JSONEncoder) Encode(e *Entry) []byte {
+	var b bytes.Buffer
+	b.WriteString("{")
+	writeJSONPair(&b, "time", e.Time.Format(timeFormat(j.TimeFormat, xtime.DateTimeFormat)))
+	b.WriteString(",")
+	writeJSONPair(&b, "level", e.Level.String())
+	if e.Caller != "" {
+		b.WriteString(",")
+		writeJSONPair(&b, "caller", e.Caller)
+	}
+	b.WriteString(",")
+	writeJSONPair(&b, "msg", e.Message)
+	for _, f := range e.Fields {
+		b.WriteString(",")
+		writeJSONPair(&b, f.Key, f.Value)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// timeFormat 返回 format,为空时返回 defaultFormat
+func timeFormat(format, defaultFormat string) string {
+	if format == "" {
+		return defaultFormat
+	}
+	return format
+}
+
+// valueString 返回值的文本表示,error 使用其 Error 方法
+func valueString(v interface{}) string {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprint(v)
+}
+
+// writeLogfmtPair 写入 logfmt 格式的键值对,值在必要时加引号
+func writeLogfmtPair(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	b.WriteString("=")
+	if needsQuote(value) {
+		b.WriteString(strconv.Quote(value))
+	} else {
+		b.WriteString(value)
+	}
+}
+
+// needsQuote 如果 s 为空或包含空白、引号、等号或不可打印字符则返回 true
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '"' || r == '=' || r == '\\' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONPair 写入 JSON 对象的键值对
+//
+// error 编码为其 Error 方法的结果,无法编码为 JSON 的值编码为 fmt.Sprint 的结果.
+func writeJSONPair(b *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteString(":")
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	b.Write(v)
+}
@@ -0,0 +1,88 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	xtime "github.com/chenquan/go-util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:    time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Level:   WARNING,
+		Caller:  "main.go:12",
+		Message: "disk almost full",
+		Fields: []Field{
+			{Key: "path", Value: "/data"},
+			{Key: "free", Value: 0.05},
+			{Key: "err", Value: errors.New("no space")},
+		},
+	}
+}
+
+func TestConsoleEncoder(t *testing.T) {
+	e := testEntry()
+	assert.Equal(t,
+		"[WARN][main.go:12]2021/03/04 05:06:07 disk almost full path=/data free=0.05 err=\"no space\"\n",
+		string(NewConsoleEncoder().Encode(e)))
+
+	e.Caller = ""
+	e.Fields = nil
+	encoder := &ConsoleEncoder{TimeFormat: xtime.DateTimeFormat}
+	assert.Equal(t, "[WARN]2021-03-04 05:06:07 disk almost full\n", string(encoder.Encode(e)))
+	assert.Equal(t, "[WARN]2021/03/04 05:06:07 disk almost full\n", string((&ConsoleEncoder{}).Encode(e)))
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	e := testEntry()
+	assert.Equal(t,
+		"time=\"2021-03-04 05:06:07\" level=WARN caller=main.go:12 msg=\"disk almost full\" path=/data free=0.05 err=\"no space\"\n",
+		string(NewLogfmtEncoder().Encode(e)))
+
+	e.Caller = ""
+	e.Message = ""
+	e.Fields = []Field{{Key: "q", Value: `a"b`}, {Key: "eq", Value: "a=b"}, {Key: "nl", Value: "a\nb"}, {Key: "nil", Value: nil}}
+	encoder := &LogfmtEncoder{TimeFormat: time.RFC3339}
+	assert.Equal(t,
+		"time=2021-03-04T05:06:07Z level=WARN msg=\"\" q=\"a\\\"b\" eq=\"a=b\" nl=\"a\\nb\" nil=<nil>\n",
+		string(encoder.Encode(e)))
+}
+
+func TestJSONEncoder(t *testing.T) {
+	e := testEntry()
+	e.Fields = append(e.Fields,
+		Field{Key: "tags", Value: map[string]int{"b": 2, "a": 1}},
+		Field{Key: "fn", Value: func() {}},
+	)
+	assert.Equal(t,
+		`{"time":"2021-03-04 05:06:07","level":"WARN","caller":"main.go:12","msg":"disk almost full",`+
+			`"path":"/data","free":0.05,"err":"no space","tags":{"a":1,"b":2},"fn":"`+
+			fmt.Sprint(e.Fields[4].Value)+`"}`+"\n",
+		string(NewJSONEncoder().Encode(e)))
+
+	e.Caller = ""
+	e.Fields = nil
+	encoder := &JSONEncoder{TimeFormat: xtime.DateFormat}
+	assert.Equal(t, `{"time":"2021-03-04","level":"WARN","msg":"disk almost full"}`+"\n", string(encoder.Encode(e)))
+}
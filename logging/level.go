@@ -0,0 +1,121 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/chenquan/go-util/errs"
+)
+
+// ParseLevel 由名称解析日志级别
+//
+// 名称不区分大小写,可以是 debug、info、warn、warning、error、fatal 或对应的数字 0-4.
+// 无法解析时返回 errs.IllegalArgument.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if name == "WARNING" {
+		return WARNING, nil
+	}
+	for i, flag := range levelFlags {
+		if name == flag {
+			return Level(i), nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n < len(levelFlags) {
+		return Level(n), nil
+	}
+	return DEBUG, errs.NewError(errs.IllegalArgument, "logging.ParseLevel").WithElement(s).WithDetail("unknown level")
+}
+
+// LevelFromEnv 由环境变量 key 解析日志级别
+//
+// 环境变量不存在或为空时返回 defaultLevel,无法解析时返回 errs.IllegalArgument.
+func LevelFromEnv(key string, defaultLevel Level) (Level, error) {
+	s, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(s) == "" {
+		return defaultLevel, nil
+	}
+	return ParseLevel(s)
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// AtomicLevel 可在运行时原子修改的日志级别
+//
+// 多个日志记录器可以共享同一个 AtomicLevel,修改后立即对所有日志记录器生效.
+// 零值为 DEBUG.
+type AtomicLevel struct {
+	level uint32
+}
+
+// NewAtomicLevel 创建初始值为 level 的 AtomicLevel
+func NewAtomicLevel(level Level) *AtomicLevel {
+	return &AtomicLevel{level: uint32(level)}
+}
+
+// Level 返回当前级别
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadUint32(&a.level))
+}
+
+// SetLevel 修改当前级别
+func (a *AtomicLevel) SetLevel(level Level) {
+	atomic.StoreUint32(&a.level, uint32(level))
+}
+
+// Enabled 如果级别 level 不低于当前级别则返回 true,否则返回 false
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level >= a.Level()
+}
+
+// String 返回当前级别的名称
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (a *AtomicLevel) MarshalText() ([]byte, error) {
+	return a.Level().MarshalText()
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口,可用于由管理命令修改级别
+func (a *AtomicLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	a.SetLevel(level)
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]Level{
+		"debug":   DEBUG,
+		"INFO":    INFO,
+		" Warn ":  WARNING,
+		"warning": WARNING,
+		"error":   ERROR,
+		"Fatal":   FATAL,
+		"3":       ERROR,
+	}
+	for s, want := range cases {
+		level, err := ParseLevel(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, level, s)
+	}
+	for _, s := range []string{"", "verbose", "5", "-1"} {
+		_, err := ParseLevel(s)
+		assert.True(t, errors.Is(err, errs.IllegalArgument), s)
+	}
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	const key = "GO_UTIL_LOGGING_TEST_LEVEL"
+	defer os.Unsetenv(key)
+
+	level, err := LevelFromEnv(key, INFO)
+	assert.Nil(t, err)
+	assert.Equal(t, INFO, level)
+
+	_ = os.Setenv(key, "error")
+	level, err = LevelFromEnv(key, INFO)
+	assert.Nil(t, err)
+	assert.Equal(t, ERROR, level)
+
+	_ = os.Setenv(key, "nope")
+	_, err = LevelFromEnv(key, INFO)
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+}
+
+func TestLevel_Text(t *testing.T) {
+	var config struct {
+		Level Level `json:"level"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"level":"warn"}`), &config))
+	assert.Equal(t, WARNING, config.Level)
+	data, err := json.Marshal(config)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"WARN"}`, string(data))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"level":"nope"}`), &config))
+	assert.Equal(t, WARNING, config.Level)
+}
+
+func TestAtomicLevel(t *testing.T) {
+	var zero AtomicLevel
+	assert.Equal(t, DEBUG, zero.Level())
+
+	a := NewAtomicLevel(INFO)
+	assert.Equal(t, INFO, a.Level())
+	assert.False(t, a.Enabled(DEBUG))
+	assert.True(t, a.Enabled(INFO))
+	assert.Equal(t, "INFO", a.String())
+
+	a.SetLevel(ERROR)
+	assert.False(t, a.Enabled(WARNING))
+
+	assert.Nil(t, a.UnmarshalText([]byte("debug")))
+	assert.Equal(t, DEBUG, a.Level())
+	assert.NotNil(t, a.UnmarshalText([]byte("nope")))
+	assert.Equal(t, DEBUG, a.Level())
+	text, err := a.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "DEBUG", string(text))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.SetLevel(Level(i % 5))
+			_ = a.Enabled(INFO)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,223 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exit 结束进程,测试中可替换
+var exit = os.Exit
+
+// Logger 分级日志记录器
+//
+// 每个 Logger 拥有独立的输出、编码器、最低级别、调用者深度与键值对,低于最低级别的日志会被忽略.
+// Logger 协程安全,每条日志以一次 Write 调用写入输出.
+type Logger struct {
+	out         *output      // 输出
+	encoder     Encoder      // 编码器
+	level       *AtomicLevel // 最低级别
+	callerDepth int          // 调用者深度
+	fields      []Field      // 附加到每条日志的键值对
+}
+
+// output 日志输出,由同一日志记录器派生的副本共享
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// write 写入一条日志
+func (o *output) write(p []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, _ = o.w.Write(p)
+}
+
+// NewLogger 创建输出到 w、最低级别为 level 的日志记录器
+//
+// 使用控制台格式编码器,调用者深度为 DefaultCallerDepth,即日志中的文件与行号为调用 Debug 等方法的位置.
+func NewLogger(w io.Writer, level Level) *Logger {
+	return NewLoggerWithLevel(w, NewAtomicLevel(level))
+}
+
+// NewLoggerWithLevel 创建输出到 w、最低级别由 level 控制的日志记录器
+//
+// 修改 level 会立即改变日志记录器的最低级别.
+func NewLoggerWithLevel(w io.Writer, level *AtomicLevel) *Logger {
+	return &Logger{out: &output{w: w}, encoder: NewConsoleEncoder(), level: level, callerDepth: DefaultCallerDepth}
+}
+
+// WithCallerDepth 返回调用者深度为 depth 的副本,副本与当前日志记录器共享输出与最低级别
+//
+// 封装 Logger 的函数每多一层调用,depth 应加 1.
+func (l *Logger) WithCallerDepth(depth int) *Logger {
+	c := *l
+	c.callerDepth = depth
+	return &c
+}
+
+// WithEncoder 返回使用编码器 encoder 的副本,副本与当前日志记录器共享输出与最低级别
+func (l *Logger) WithEncoder(encoder Encoder) *Logger {
+	c := *l
+	c.encoder = encoder
+	return &c
+}
+
+// With 返回附加了键值对的副本,副本输出的每条日志都包含这些键值对
+//
+// keysAndValues 依次为键与值,键应为字符串,缺少键或键不是字符串时使用 !BADKEY 作为键.
+// 副本与当前日志记录器共享输出与最低级别.
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	c := *l
+	c.fields = appendFields(l.fields[:len(l.fields):len(l.fields)], keysAndValues)
+	return &c
+}
+
+// Level 返回最低级别
+func (l *Logger) Level() Level {
+	return l.level.Level()
+}
+
+// SetLevel 修改最低级别,可在运行时与日志输出并发调用
+func (l *Logger) SetLevel(level Level) {
+	l.level.SetLevel(level)
+}
+
+// AtomicLevel 返回控制最低级别的 AtomicLevel
+func (l *Logger) AtomicLevel() *AtomicLevel {
+	return l.level
+}
+
+// Enabled 如果级别 level 的日志会被输出则返回 true,否则返回 false
+func (l *Logger) Enabled(level Level) bool {
+	return l.level.Enabled(level)
+}
+
+// Debug debug级日志输出,keysAndValues 为附加的键值对
+func (l *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(l.callerDepth, DEBUG, msg, keysAndValues)
+}
+
+// Info info级日志输出,keysAndValues 为附加的键值对
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(l.callerDepth, INFO, msg, keysAndValues)
+}
+
+// Warn warn级日志输出,keysAndValues 为附加的键值对
+func (l *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(l.callerDepth, WARNING, msg, keysAndValues)
+}
+
+// Error error级日志输出,keysAndValues 为附加的键值对
+func (l *Logger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(l.callerDepth, ERROR, msg, keysAndValues)
+}
+
+// Fatal fatal级日志输出,keysAndValues 为附加的键值对,输出后结束进程
+func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.log(l.callerDepth, FATAL, msg, keysAndValues)
+	exit(1)
+}
+
+// Debugf debug级格式化日志输出
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(l.callerDepth, DEBUG, format, args)
+}
+
+// Infof info级格式化日志输出
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(l.callerDepth, INFO, format, args)
+}
+
+// Warnf warn级格式化日志输出
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(l.callerDepth, WARNING, format, args)
+}
+
+// Errorf error级格式化日志输出
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(l.callerDepth, ERROR, format, args)
+}
+
+// Fatalf fatal级格式化日志输出,输出后结束进程
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.logf(l.callerDepth, FATAL, format, args)
+	exit(1)
+}
+
+// log 输出一条带键值对的日志,depth 为 runtime.Caller 跳过的栈帧数
+func (l *Logger) log(depth int, level Level, msg string, keysAndValues []interface{}) {
+	if l.Enabled(level) {
+		l.output(depth+1, level, msg, keysAndValues)
+	}
+}
+
+// logln 以 fmt.Sprintln 的方式拼接 v 并输出一条日志,depth 为 runtime.Caller 跳过的栈帧数
+func (l *Logger) logln(depth int, level Level, v []interface{}) {
+	if l.Enabled(level) {
+		l.output(depth+1, level, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), nil)
+	}
+}
+
+// logf 以 fmt.Sprintf 的格式输出一条日志,depth 为 runtime.Caller 跳过的栈帧数
+func (l *Logger) logf(depth int, level Level, format string, args []interface{}) {
+	if l.Enabled(level) {
+		l.output(depth+1, level, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"), nil)
+	}
+}
+
+// output 编码并写入一条日志,depth 为 runtime.Caller 跳过的栈帧数
+func (l *Logger) output(depth int, level Level, msg string, keysAndValues []interface{}) {
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+	}
+	if len(keysAndValues) > 0 {
+		e.Fields = appendFields(l.fields[:len(l.fields):len(l.fields)], keysAndValues)
+	}
+	if _, fileName, line, ok := runtime.Caller(depth); ok {
+		e.Caller = filepath.Base(fileName) + ":" + strconv.Itoa(line)
+	}
+	l.out.write(l.encoder.Encode(e))
+}
+
+// appendFields 将 keysAndValues 中的键值对依次追加到 fields
+func appendFields(fields []Field, keysAndValues []interface{}) []Field {
+	for i := 0; i < len(keysAndValues); {
+		key, ok := keysAndValues[i].(string)
+		if !ok || i+1 == len(keysAndValues) {
+			fields = append(fields, Field{Key: badKey, Value: keysAndValues[i]})
+			i++
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+		i += 2
+	}
+	return fields
+}
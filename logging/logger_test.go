@@ -0,0 +1,259 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, DEBUG)
+	l.Info("hello", "count", 1)
+	assert.Regexp(t, regexp.MustCompile(`^\[INFO\]\[logger_test\.go:\d+\]\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} hello count=1\n$`), buffer.String())
+
+	buffer.Reset()
+	l.Debug("d")
+	l.Warn("w")
+	l.Error("e")
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "[DEBUG]"))
+	assert.True(t, strings.HasPrefix(lines[1], "[WARN]"))
+	assert.True(t, strings.HasPrefix(lines[2], "[ERROR]"))
+}
+
+func TestLogger_Level(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, WARNING)
+	assert.Equal(t, WARNING, l.Level())
+	assert.False(t, l.Enabled(INFO))
+	assert.True(t, l.Enabled(ERROR))
+
+	l.Debug("debug")
+	l.Info("info")
+	assert.Equal(t, 0, buffer.Len())
+	l.Warn("warn")
+	l.Error("error")
+	assert.Contains(t, buffer.String(), "warn")
+	assert.Contains(t, buffer.String(), "error")
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	buffer := bytes.Buffer{}
+	level := NewAtomicLevel(ERROR)
+	l1 := NewLoggerWithLevel(&buffer, level)
+	l2 := NewLoggerWithLevel(&buffer, level)
+	assert.Equal(t, level, l1.AtomicLevel())
+
+	l1.Info("hidden")
+	l2.Info("hidden")
+	assert.Equal(t, 0, buffer.Len())
+
+	level.SetLevel(INFO)
+	l2.Info("shown")
+	assert.Contains(t, buffer.String(), "shown")
+
+	buffer.Reset()
+	l1.SetLevel(WARNING)
+	assert.Equal(t, WARNING, l2.Level())
+	l1.WithCallerDepth(DefaultCallerDepth).Info("hidden")
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestLogger_Formatted(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, INFO)
+	l.Debugf("hidden %d", 1)
+	assert.Equal(t, 0, buffer.Len())
+
+	l.Infof("user %s logged in %d times", "tom", 3)
+	assert.Regexp(t, regexp.MustCompile(`^\[INFO\]\[logger_test\.go:\d+\].* user tom logged in 3 times\n$`), buffer.String())
+
+	buffer.Reset()
+	l.Warnf("w\n")
+	l.Errorf("e%v", 1)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "[WARN]"))
+	assert.True(t, strings.HasSuffix(lines[1], " e1"))
+}
+
+func TestLogger_With(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, DEBUG)
+	child := l.With("user", 42, "role", "admin")
+	a := child.With("request", "a")
+	b := child.With("request", "b")
+
+	a.Info("login", "ok", true)
+	assert.Regexp(t, regexp.MustCompile(` login user=42 role=admin request=a ok=true\n$`), buffer.String())
+	buffer.Reset()
+	b.Info("login")
+	assert.Regexp(t, regexp.MustCompile(` login user=42 role=admin request=b\n$`), buffer.String())
+	buffer.Reset()
+	l.Info("plain")
+	assert.Regexp(t, regexp.MustCompile(` plain\n$`), buffer.String())
+
+	buffer.Reset()
+	l.With(1, "x").Warn("bad", "dangling")
+	assert.Regexp(t, regexp.MustCompile(` bad !BADKEY=1 !BADKEY=x !BADKEY=dangling\n$`), buffer.String())
+
+	buffer.Reset()
+	child.Infof("user %d", 42)
+	assert.Regexp(t, regexp.MustCompile(`\[logger_test\.go:\d+\].* user 42 user=42 role=admin\n$`), buffer.String())
+}
+
+func TestLogger_WithEncoder(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, DEBUG).WithEncoder(NewJSONEncoder()).With("user", 42)
+	l.Error("failed", "err", errors.New("boom"))
+
+	var got map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &got))
+	assert.Equal(t, "ERROR", got["level"])
+	assert.Equal(t, "failed", got["msg"])
+	assert.Equal(t, float64(42), got["user"])
+	assert.Equal(t, "boom", got["err"])
+	assert.Regexp(t, regexp.MustCompile(`^logger_test\.go:\d+$`), got["caller"])
+	assert.True(t, strings.HasPrefix(buffer.String(), `{"time":"`))
+
+	buffer.Reset()
+	l.WithEncoder(NewLogfmtEncoder()).Info("ok")
+	assert.Regexp(t, regexp.MustCompile(`^time="[^"]+" level=INFO caller=logger_test\.go:\d+ msg=ok user=42\n$`), buffer.String())
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	code := -1
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	buffer := bytes.Buffer{}
+	NewLogger(&buffer, DEBUG).Fatal("fatal")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, buffer.String(), "[FATAL]")
+
+	code = -1
+	NewLogger(&buffer, DEBUG).Fatalf("fatal %d", 2)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, buffer.String(), "fatal 2")
+}
+
+func TestLogger_WithCallerDepth(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, DEBUG)
+	wrapped := l.WithCallerDepth(DefaultCallerDepth + 1)
+	logWrapped := func(msg string) {
+		wrapped.Info(msg)
+	}
+	logWrapped("wrapped")
+	_, _, line, _ := runtime.Caller(0)
+	assert.Contains(t, buffer.String(), fmt.Sprintf("[logger_test.go:%d]", line-1))
+
+	buffer.Reset()
+	l.Info("original")
+	assert.Contains(t, buffer.String(), "original")
+	assert.Equal(t, DefaultCallerDepth, l.callerDepth)
+}
+
+func TestLogger_Concurrent(t *testing.T) {
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, DEBUG)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				l.Info("message", "goroutine", i, "seq", j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, 1000, len(lines))
+	pattern := regexp.MustCompile(`^\[INFO\]\[logger_test\.go:\d+\].* message goroutine=\d+ seq=\d+$`)
+	for _, line := range lines {
+		assert.Regexp(t, pattern, line)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	old := Default()
+	defer SetDefault(old)
+
+	buffer := bytes.Buffer{}
+	l := NewLogger(&buffer, ERROR)
+	SetDefault(l)
+	assert.Equal(t, l, Default())
+	Info("info")
+	assert.Equal(t, 0, buffer.Len())
+	Error("error")
+	assert.Contains(t, buffer.String(), "[logger_test.go:")
+
+	buffer.Reset()
+	Infof("info %d", 1)
+	assert.Equal(t, 0, buffer.Len())
+	SetLevel(INFO)
+	assert.Equal(t, INFO, l.Level())
+	Infof("info %d", 2)
+	assert.Contains(t, buffer.String(), "[logger_test.go:")
+	assert.Contains(t, buffer.String(), "info 2")
+
+	buffer.Reset()
+	Error(errors.New("boom"), "a", 3)
+	assert.Contains(t, buffer.String(), "boom a 3\n")
+
+	buffer.Reset()
+	With("user", 1).Info("hello", "k", "v")
+	assert.Contains(t, buffer.String(), "[logger_test.go:")
+	assert.Contains(t, buffer.String(), "hello user=1 k=v")
+}
+
+func TestDefault_CallerDepth(t *testing.T) {
+	old := Default()
+	defer SetDefault(old)
+
+	buffer := bytes.Buffer{}
+	SetDefault(NewLogger(&buffer, DEBUG).WithCallerDepth(DefaultCallerDepth + 1))
+	logWrapped := func(msg string) {
+		Info(msg)
+	}
+	logWrapped("wrapped")
+	_, _, line, _ := runtime.Caller(0)
+	assert.Contains(t, buffer.String(), fmt.Sprintf("[logger_test.go:%d]", line-1))
+}
+
+func TestLevel_String(t *testing.T) {
+	assert.Equal(t, "DEBUG", DEBUG.String())
+	assert.Equal(t, "WARN", WARNING.String())
+	assert.Equal(t, "FATAL", FATAL.String())
+	assert.Equal(t, "Level(9)", Level(9).String())
+}
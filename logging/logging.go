@@ -19,12 +19,11 @@ package logging
 
 import (
 	"fmt"
-	"github.com/chenquan/go-util/file"
 	"io"
-	log "log"
 	"os"
-	"path/filepath"
-	"runtime"
+	"sync/atomic"
+
+	"github.com/chenquan/go-util/file"
 )
 
 // Level 存放日志级别
@@ -41,14 +40,35 @@ const (
 
 var (
 	DefaultCallerDepth = 2
-	logger             *log.Logger
-	logPrefix          = ""
+	defaultLogger      atomic.Value // *Logger
 	levelFlags         = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
 )
 
+func init() {
+	NewLog()
+}
+
+// String 返回日志级别的名称
+func (l Level) String() string {
+	if int(l) < len(levelFlags) {
+		return levelFlags[l]
+	}
+	return fmt.Sprintf("Level(%d)", uint8(l))
+}
+
+// Default 返回包级别函数使用的默认日志记录器
+func Default() *Logger {
+	return defaultLogger.Load().(*Logger)
+}
+
+// SetDefault 设置包级别函数使用的默认日志记录器,l 不能为 nil
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
 // newLog 新建日志
 func newLog(out io.Writer) {
-	logger = log.New(out, "", log.LstdFlags)
+	SetDefault(NewLogger(out, DEBUG))
 }
 
 // NewLog 新建标准日志
@@ -70,46 +90,74 @@ func New(filepath string) {
 	newLog(writer)
 }
 
-// Debug debug级日志输出
+// Debug debug级日志输出,参数以 fmt.Sprintln 的方式拼接
 func Debug(v ...interface{}) {
-	setPrefix(DEBUG)
-	logger.Println(v...)
+	l := Default()
+	l.logln(l.callerDepth, DEBUG, v)
 }
 
-// Info info级日志输出
+// Info info级日志输出,参数以 fmt.Sprintln 的方式拼接
 func Info(v ...interface{}) {
-	setPrefix(INFO)
-	logger.Println(v...)
+	l := Default()
+	l.logln(l.callerDepth, INFO, v)
 }
 
-// Warn warn级日志输出
+// Warn warn级日志输出,参数以 fmt.Sprintln 的方式拼接
 func Warn(v ...interface{}) {
-	setPrefix(WARNING)
-	logger.Println(v...)
+	l := Default()
+	l.logln(l.callerDepth, WARNING, v)
 }
 
-// Error error级日志输出
+// Error error级日志输出,参数以 fmt.Sprintln 的方式拼接
 func Error(v ...interface{}) {
-	setPrefix(ERROR)
-	logger.Println(v...)
+	l := Default()
+	l.logln(l.callerDepth, ERROR, v)
 }
 
-// Fatal  fatal级日志输出
+// Fatal  fatal级日志输出,参数以 fmt.Sprintln 的方式拼接
 func Fatal(v ...interface{}) {
-	setPrefix(FATAL)
-	logger.Fatalln(v...)
+	l := Default()
+	l.logln(l.callerDepth, FATAL, v)
+	exit(1)
 }
 
-// setPrefix 设置日志输出的前缀
-func setPrefix(level Level) {
-	var _, fileName, line, ok = runtime.Caller(DefaultCallerDepth)
-	if ok {
-		logPrefix = fmt.Sprintf("[%s][%s:%d]", levelFlags[level], filepath.Base(fileName), line)
-	} else {
-		logPrefix = fmt.Sprintf("[%s]", levelFlags[level])
-	}
-	if logger == nil {
-		NewLog()
-	}
-	logger.SetPrefix(logPrefix)
+// Debugf debug级格式化日志输出
+func Debugf(format string, args ...interface{}) {
+	l := Default()
+	l.logf(l.callerDepth, DEBUG, format, args)
+}
+
+// Infof info级格式化日志输出
+func Infof(format string, args ...interface{}) {
+	l := Default()
+	l.logf(l.callerDepth, INFO, format, args)
+}
+
+// Warnf warn级格式化日志输出
+func Warnf(format string, args ...interface{}) {
+	l := Default()
+	l.logf(l.callerDepth, WARNING, format, args)
+}
+
+// Errorf error级格式化日志输出
+func Errorf(format string, args ...interface{}) {
+	l := Default()
+	l.logf(l.callerDepth, ERROR, format, args)
+}
+
+// Fatalf fatal级格式化日志输出
+func Fatalf(format string, args ...interface{}) {
+	l := Default()
+	l.logf(l.callerDepth, FATAL, format, args)
+	exit(1)
+}
+
+// SetLevel 修改默认日志记录器的最低级别
+func SetLevel(level Level) {
+	Default().SetLevel(level)
+}
+
+// With 返回附加了键值对的默认日志记录器副本
+func With(keysAndValues ...interface{}) *Logger {
+	return Default().With(keysAndValues...)
 }
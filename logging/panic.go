@@ -0,0 +1,33 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/errs"
+)
+
+// ReportPanics 将 errs.Recover、errs.Try 与 errs.SafeGo 恢复的 panic 以 error 级别输出到日志
+//
+// 日志包含 panic 的值与引发 panic 时的调用栈.
+func ReportPanics() {
+	errs.SetPanicHook(func(err *errs.PanicError) {
+		Error(fmt.Sprintf("%+v", err))
+	})
+}
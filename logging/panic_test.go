@@ -0,0 +1,40 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPanics(t *testing.T) {
+	buffer := bytes.Buffer{}
+	newLog(&buffer)
+	ReportPanics()
+	defer errs.SetPanicHook(nil)
+
+	err := errs.Try(func() error { panic(errors.New("boom")) })
+	assert.NotNil(t, err)
+	assert.Contains(t, buffer.String(), "[ERROR]")
+	assert.Contains(t, buffer.String(), "panic: boom")
+	assert.Contains(t, buffer.String(), "logging.TestReportPanics")
+}
@@ -0,0 +1,418 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/file"
+	xtime "github.com/chenquan/go-util/time"
+)
+
+// backupTimeFormat 历史文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix 压缩后的历史文件的后缀
+const compressSuffix = ".gz"
+
+// RotateConfig 滚动写入器的配置
+type RotateConfig struct {
+	// Filename 当前日志文件的路径,所在目录不存在时自动创建
+	Filename string
+	// MaxSize 单个日志文件的最大字节数,写入后超过该值时先滚动,0 表示不按大小滚动
+	MaxSize int64
+	// Daily 是否在日期变化后的第一次写入前滚动
+	Daily bool
+	// MaxBackups 保留的历史文件个数,0 表示全部保留
+	MaxBackups int
+	// Compress 是否使用 gzip 压缩历史文件
+	Compress bool
+	// Clock 时钟,默认为 xtime.SystemClock
+	Clock xtime.Clock
+}
+
+var _ io.WriteCloser = (*RotatingWriter)(nil)
+
+// RotatingWriter 按大小与日期滚动的日志文件写入器
+//
+// 滚动时当前日志文件被重命名为 文件名-时间.扩展名 形式的历史文件,并重新创建当前日志文件.
+// 历史文件的压缩与清理在后台协程中进行,不会阻塞写入,其错误由 Close 返回.
+// 打开当前日志文件失败后,下一次写入会重新尝试打开.
+// RotatingWriter 协程安全,可以作为任意日志记录器的输出.
+type RotatingWriter struct {
+	mu     sync.Mutex
+	config RotateConfig
+	clock  xtime.Clock
+	file   *os.File // 当前日志文件,打开失败时为 nil
+	size   int64    // 当前日志文件的字节数
+	day    string   // 当前日志文件的日期
+	closed bool     // 是否已关闭
+
+	cleanupMu  sync.Mutex     // 串行化历史文件的压缩与清理
+	cleanups   sync.WaitGroup // 进行中的压缩与清理
+	cleanupErr error          // 压缩与清理发生的第一个错误,由 mu 保护
+}
+
+// NewRotatingWriter 创建滚动写入器并打开当前日志文件
+func NewRotatingWriter(config RotateConfig) (*RotatingWriter, error) {
+	if config.Filename == "" {
+		return nil, errs.NewError(errs.IllegalArgument, "logging.NewRotatingWriter").WithDetail("empty filename")
+	}
+	if config.MaxSize < 0 || config.MaxBackups < 0 {
+		return nil, errs.NewError(errs.IllegalArgument, "logging.NewRotatingWriter").WithDetail("negative limit")
+	}
+	clock := config.Clock
+	if clock == nil {
+		clock = xtime.SystemClock
+	}
+	w := &RotatingWriter{config: config, clock: clock}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write 实现 io.Writer 接口,在需要时先滚动再写入
+//
+// 单次写入的内容不会被拆分到两个文件中.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errs.NewError(errs.IllegalState, "RotatingWriter.Write").WithDetail("writer closed")
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即滚动当前日志文件
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errs.NewError(errs.IllegalState, "RotatingWriter.Rotate").WithDetail("writer closed")
+	}
+	return w.rotate()
+}
+
+// Reopen 关闭并重新打开当前日志文件
+//
+// 用于外部工具移动或删除日志文件之后,使后续日志写入新的文件.
+func (w *RotatingWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errs.NewError(errs.IllegalState, "RotatingWriter.Reopen").WithDetail("writer closed")
+	}
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// ReopenOnSignal 在收到任一信号 signals 时调用 Reopen,例如 syscall.SIGHUP
+//
+// 返回的函数用于停止监听.
+func (w *RotatingWriter) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+	go func() {
+		for {
+			select {
+			case <-c:
+				_ = w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// Sync 将当前日志文件刷入存储
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭当前日志文件并等待后台的压缩与清理完成,关闭后的写入返回 errs.IllegalState
+//
+// 返回关闭文件的错误或压缩与清理发生的第一个错误.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.closeFile()
+	w.mu.Unlock()
+
+	w.cleanups.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		err = w.cleanupErr
+	}
+	w.cleanupErr = nil
+	return err
+}
+
+// closeFile 关闭当前日志文件,文件未打开时什么也不做
+func (w *RotatingWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	f := w.file
+	w.file = nil
+	return f.Close()
+}
+
+// open 打开当前日志文件,并由文件的大小与修改时间初始化 size 与 day
+func (w *RotatingWriter) open() error {
+	if err := file.IsNotExistMkDir(filepath.Dir(w.config.Filename)); err != nil {
+		return err
+	}
+	f, err := file.Open(w.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.day = w.dayOf(w.clock.Now())
+	if w.size > 0 {
+		w.day = w.dayOf(info.ModTime())
+	}
+	return nil
+}
+
+// dayOf 返回时间 t 在时钟所在时区的日期
+func (w *RotatingWriter) dayOf(t time.Time) string {
+	t = t.In(w.clock.Now().Location())
+	return xtime.ToDateFormat(&t)
+}
+
+// shouldRotate 如果写入 n 个字节前需要滚动则返回 true
+func (w *RotatingWriter) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.config.MaxSize > 0 && w.size+n > w.config.MaxSize {
+		return true
+	}
+	return w.config.Daily && w.dayOf(w.clock.Now()) != w.day
+}
+
+// rotate 将当前日志文件重命名为历史文件,重新打开当前日志文件,并在后台压缩与清理历史文件
+//
+// 重命名失败时重新打开原来的日志文件并返回错误.
+func (w *RotatingWriter) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	backup := w.backupName(w.clock.Now())
+	if err := os.Rename(w.config.Filename, backup); err != nil && !os.IsNotExist(err) {
+		_ = w.open()
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	if w.config.Compress || w.config.MaxBackups > 0 {
+		w.cleanups.Add(1)
+		go w.cleanup(backup)
+	}
+	return nil
+}
+
+// cleanup 压缩历史文件 backup 并删除多余的历史文件,在后台协程中执行
+func (w *RotatingWriter) cleanup(backup string) {
+	defer w.cleanups.Done()
+	w.cleanupMu.Lock()
+	defer w.cleanupMu.Unlock()
+	var err error
+	if w.config.Compress {
+		err = compress(backup)
+	}
+	if err == nil {
+		err = w.prune()
+	}
+	if err != nil {
+		w.mu.Lock()
+		if w.cleanupErr == nil {
+			w.cleanupErr = err
+		}
+		w.mu.Unlock()
+	}
+}
+
+// backupName 返回时间 t 对应的未被占用的历史文件名
+func (w *RotatingWriter) backupName(t time.Time) string {
+	prefix, ext := w.backupPrefix()
+	stamp := t.Format(backupTimeFormat)
+	name := prefix + stamp + ext
+	for i := 1; file.CheckExist(name) || file.CheckExist(name+compressSuffix); i++ {
+		name = prefix + stamp + "." + strconv.Itoa(i) + ext
+	}
+	return name
+}
+
+// backupPrefix 返回历史文件名的前缀与扩展名
+func (w *RotatingWriter) backupPrefix() (prefix, ext string) {
+	ext = filepath.Ext(w.config.Filename)
+	return strings.TrimSuffix(w.config.Filename, ext) + "-", ext
+}
+
+// backup 历史文件
+type backup struct {
+	path  string // 路径
+	stamp string // 文件名中的时间
+	seq   int    // 同一时间的序号
+}
+
+// backups 返回所有历史文件,按从旧到新排列
+func (w *RotatingWriter) backups() ([]backup, error) {
+	prefix, ext := w.backupPrefix()
+	dir := filepath.Dir(prefix)
+	base := filepath.Base(prefix)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var result []backup
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimPrefix(name, base), compressSuffix)
+		if !strings.HasSuffix(rest, ext) {
+			continue
+		}
+		rest = strings.TrimSuffix(rest, ext)
+		if len(rest) < len(backupTimeFormat) {
+			continue
+		}
+		stamp := rest[:len(backupTimeFormat)]
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		seq := 0
+		if suffix := rest[len(backupTimeFormat):]; suffix != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(suffix, ".")); err != nil || !strings.HasPrefix(suffix, ".") {
+				continue
+			}
+		}
+		result = append(result, backup{path: filepath.Join(dir, name), stamp: stamp, seq: seq})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].stamp != result[j].stamp {
+			return result[i].stamp < result[j].stamp
+		}
+		return result[i].seq < result[j].seq
+	})
+	return result, nil
+}
+
+// prune 删除超出 MaxBackups 个数的最旧的历史文件
+func (w *RotatingWriter) prune() error {
+	if w.config.MaxBackups == 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(backups)-w.config.MaxBackups; i++ {
+		if err := os.Remove(backups[i].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// compress 将文件 name 压缩为 name.gz 并删除原文件
+func compress(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(name + compressSuffix)
+		}
+	}()
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(name)
+}
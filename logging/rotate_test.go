@@ -0,0 +1,295 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+// tempDir 创建临时目录,返回的函数用于删除该目录
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "logging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func readFile(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile(name)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func backupContents(t *testing.T, w *RotatingWriter) []string {
+	w.cleanups.Wait()
+	backups, err := w.backups()
+	assert.Nil(t, err)
+	contents := make([]string, 0, len(backups))
+	for _, b := range backups {
+		if strings.HasSuffix(b.path, compressSuffix) {
+			f, err := os.Open(b.path)
+			assert.Nil(t, err)
+			gz, err := gzip.NewReader(f)
+			assert.Nil(t, err)
+			data, err := ioutil.ReadAll(gz)
+			assert.Nil(t, err)
+			_ = f.Close()
+			contents = append(contents, string(data))
+		} else {
+			contents = append(contents, readFile(t, b.path))
+		}
+	}
+	return contents
+}
+
+func TestRotatingWriter_Size(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "logs", "app.log")
+	clock := xtime.NewManualClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	w, err := NewRotatingWriter(RotateConfig{Filename: name, MaxSize: 10, Clock: clock})
+	assert.Nil(t, err)
+	defer w.Close()
+
+	for _, line := range []string{"line1\n", "line2\n", "line3\n"} {
+		n, err := w.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Equal(t, len(line), n)
+	}
+	assert.Equal(t, "line3\n", readFile(t, name))
+	assert.Equal(t, []string{"line1\n", "line2\n"}, backupContents(t, w))
+
+	backups, _ := w.backups()
+	assert.Equal(t, filepath.Join(filepath.Dir(name), "app-2021-03-04T05-06-07.000.log"), backups[0].path)
+	assert.Equal(t, filepath.Join(filepath.Dir(name), "app-2021-03-04T05-06-07.000.1.log"), backups[1].path)
+
+	_, err = w.Write([]byte("a line longer than max size\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a line longer than max size\n", readFile(t, name))
+	assert.Equal(t, 3, len(backupContents(t, w)))
+}
+
+func TestRotatingWriter_Daily(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	clock := xtime.NewManualClock(time.Date(2021, 3, 4, 23, 0, 0, 0, time.UTC))
+	w, err := NewRotatingWriter(RotateConfig{Filename: name, Daily: true, Clock: clock})
+	assert.Nil(t, err)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("day1\n"))
+	clock.Advance(30 * time.Minute)
+	_, _ = w.Write([]byte("day1 again\n"))
+	assert.Equal(t, 0, len(backupContents(t, w)))
+
+	clock.Advance(time.Hour)
+	_, _ = w.Write([]byte("day2\n"))
+	assert.Equal(t, "day2\n", readFile(t, name))
+	assert.Equal(t, []string{"day1\nday1 again\n"}, backupContents(t, w))
+	assert.FileExists(t, filepath.Join(filepath.Dir(name), "app-2021-03-05T00-30-00.000.log"))
+}
+
+func TestRotatingWriter_DailyExistingFile(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	assert.Nil(t, ioutil.WriteFile(name, []byte("old\n"), 0644))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	assert.Nil(t, os.Chtimes(name, yesterday, yesterday))
+
+	w, err := NewRotatingWriter(RotateConfig{Filename: name, Daily: true})
+	assert.Nil(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("new\n"))
+	assert.Equal(t, "new\n", readFile(t, name))
+	assert.Equal(t, []string{"old\n"}, backupContents(t, w))
+}
+
+func TestRotatingWriter_MaxBackupsCompress(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	clock := xtime.NewManualClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	w, err := NewRotatingWriter(RotateConfig{Filename: name, MaxBackups: 2, Compress: true, Clock: clock})
+	assert.Nil(t, err)
+	defer w.Close()
+
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		_, _ = w.Write([]byte(line))
+		assert.Nil(t, w.Rotate())
+		clock.Advance(time.Second)
+	}
+	assert.Equal(t, []string{"3\n", "4\n"}, backupContents(t, w))
+	backups, _ := w.backups()
+	for _, b := range backups {
+		assert.True(t, strings.HasSuffix(b.path, ".log.gz"), b.path)
+	}
+	assert.Equal(t, "", readFile(t, name))
+}
+
+func TestRotatingWriter_Reopen(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	w, err := NewRotatingWriter(RotateConfig{Filename: name})
+	assert.Nil(t, err)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("before\n"))
+	moved := filepath.Join(dir, "moved.log")
+	assert.Nil(t, os.Rename(name, moved))
+	assert.Nil(t, w.Reopen())
+	_, _ = w.Write([]byte("after\n"))
+	assert.Equal(t, "before\n", readFile(t, moved))
+	assert.Equal(t, "after\n", readFile(t, name))
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	stop := w.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+	assert.Nil(t, os.Rename(name, moved))
+	p, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	assert.Nil(t, p.Signal(syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(name)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	_, _ = w.Write([]byte("signal\n"))
+	assert.Equal(t, "signal\n", readFile(t, name))
+	stop()
+}
+
+func TestRotatingWriter_Recover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directories with open files cannot be renamed on windows")
+	}
+	root, remove := tempDir(t)
+	defer remove()
+	dir := filepath.Join(root, "logs")
+	name := filepath.Join(dir, "app.log")
+	w, err := NewRotatingWriter(RotateConfig{Filename: name})
+	assert.Nil(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("a\n"))
+
+	// 日志目录被替换为普通文件,滚动与重新打开都会失败
+	moved := filepath.Join(root, "moved")
+	assert.Nil(t, os.Rename(dir, moved))
+	assert.Nil(t, ioutil.WriteFile(dir, nil, 0644))
+	assert.NotNil(t, w.Rotate())
+	assert.NotNil(t, w.Reopen())
+	_, err = w.Write([]byte("b\n"))
+	assert.NotNil(t, err)
+	assert.Nil(t, w.Sync())
+
+	// 恢复目录后写入重新打开日志文件
+	assert.Nil(t, os.Remove(dir))
+	assert.Nil(t, os.Mkdir(dir, 0755))
+	_, err = w.Write([]byte("c\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "c\n", readFile(t, name))
+	assert.Nil(t, w.Rotate())
+	assert.Nil(t, w.Reopen())
+	_, err = w.Write([]byte("d\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "d\n", readFile(t, name))
+	assert.Equal(t, "a\n", readFile(t, filepath.Join(moved, "app.log")))
+}
+
+func TestRotatingWriter_RenameFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not enforced on windows")
+	}
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	w, err := NewRotatingWriter(RotateConfig{Filename: name})
+	assert.Nil(t, err)
+	defer w.Close()
+	_, _ = w.Write([]byte("a\n"))
+
+	// 目录只读时重命名失败,仍继续写入原来的日志文件
+	assert.Nil(t, os.Chmod(dir, 0555))
+	defer os.Chmod(dir, 0755)
+	if f, err := os.Create(filepath.Join(dir, "probe")); err == nil {
+		_ = f.Close()
+		t.Skip("directory permissions are not enforced for this user")
+	}
+	assert.NotNil(t, w.Rotate())
+	_, err = w.Write([]byte("b\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a\nb\n", readFile(t, name))
+}
+
+func TestRotatingWriter_Close(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	w, err := NewRotatingWriter(RotateConfig{Filename: filepath.Join(dir, "app.log")})
+	assert.Nil(t, err)
+	assert.Nil(t, w.Sync())
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Sync())
+	_, err = w.Write([]byte("x"))
+	assert.True(t, errors.Is(err, errs.IllegalState))
+	assert.True(t, errors.Is(w.Rotate(), errs.IllegalState))
+	assert.True(t, errors.Is(w.Reopen(), errs.IllegalState))
+
+	_, err = NewRotatingWriter(RotateConfig{})
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+	_, err = NewRotatingWriter(RotateConfig{Filename: "app.log", MaxSize: -1})
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+}
+
+func TestRotatingWriter_Logger(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	name := filepath.Join(dir, "app.log")
+	w, err := NewRotatingWriter(RotateConfig{Filename: name, MaxSize: 200, MaxBackups: 3})
+	assert.Nil(t, err)
+	defer w.Close()
+
+	l := NewLogger(w, DEBUG)
+	for i := 0; i < 20; i++ {
+		l.Info("message", "seq", i)
+	}
+	contents := backupContents(t, w)
+	assert.Equal(t, 3, len(contents))
+	for _, c := range append(contents, readFile(t, name)) {
+		assert.LessOrEqual(t, len(c), 200)
+		assert.True(t, strings.HasSuffix(c, "\n"))
+	}
+	assert.Contains(t, readFile(t, name), "seq=19")
+}
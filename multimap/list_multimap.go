@@ -0,0 +1,62 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package multimap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/list"
+)
+
+var _ _map.ListMultimap = (*ListMultimap)(nil)
+
+// ListMultimap 值以 list.SliceList 保存的多值映射
+//
+// 同一个键下允许重复的值,并保持值的插入顺序.
+// 注意 ListMultimap 协程不安全,不能用于高并发.
+type ListMultimap struct {
+	multimap
+}
+
+// NewListMultimap 创建空的列表多值映射
+func NewListMultimap() *ListMultimap {
+	m := &ListMultimap{multimap{
+		data: make(map[interface{}]collection.Collection),
+		newCollection: func() collection.Collection {
+			return list.NewSliceListDefault()
+		},
+	}}
+	m.view = func(k _map.Key) collection.Collection {
+		return m.GetList(k)
+	}
+	return m
+}
+
+// Get 返回键 k 对应的值列表
+//
+// 返回的列表是实时视图,对其的修改会反映到当前多值映射中,反之亦然.
+func (m *ListMultimap) Get(k _map.Key) collection.Collection {
+	return m.GetList(k)
+}
+
+// GetList 返回键 k 对应的值列表
+//
+// 返回的列表是实时视图,对其的修改会反映到当前多值映射中,反之亦然.
+func (m *ListMultimap) GetList(k _map.Key) collection.List {
+	return &valueList{valueCollection{m: &m.multimap, key: k}}
+}
@@ -0,0 +1,203 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package multimap 多值映射的实现
+package multimap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/hashmap"
+	"github.com/chenquan/go-util/list"
+	"github.com/chenquan/go-util/set"
+)
+
+// multimap 多值映射的公共实现
+//
+// 值集合为空的键不会保存在 data 中.
+type multimap struct {
+	data          map[interface{}]collection.Collection  // 键到值集合
+	newCollection func() collection.Collection           // 创建值集合
+	view          func(k _map.Key) collection.Collection // 创建值集合视图
+}
+
+// Size 返回所有键值对的个数
+func (m *multimap) Size() int {
+	size := 0
+	for _, c := range m.data {
+		size += c.Size()
+	}
+	return size
+}
+
+// IsEmpty 如果不存在任何键值对则返回 true,否则返回 false
+func (m *multimap) IsEmpty() bool {
+	return len(m.data) == 0
+}
+
+// ContainsKey 如果存在至少一个键为 k 的键值对则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *multimap) ContainsKey(k _map.Key) (bool, error) {
+	_, ok := m.data[k]
+	return ok, nil
+}
+
+// ContainsValue 如果存在至少一个值为 v 的键值对则返回 true,否则返回 false
+func (m *multimap) ContainsValue(v _map.Value) (bool, error) {
+	for _, c := range m.data {
+		contains, err := c.Contains(v)
+		if err != nil || contains {
+			return contains, err
+		}
+	}
+	return false, nil
+}
+
+// ContainsEntry 如果存在键为 k 且值为 v 的键值对则返回 true,否则返回 false
+func (m *multimap) ContainsEntry(k _map.Key, v _map.Value) (bool, error) {
+	c, ok := m.data[k]
+	if !ok {
+		return false, nil
+	}
+	return c.Contains(v)
+}
+
+// Put 添加键值对
+//
+// 如果当前多值映射由于调用而更改,则返回 true.
+func (m *multimap) Put(k _map.Key, v _map.Value) (bool, error) {
+	c, ok := m.data[k]
+	if !ok {
+		c = m.newCollection()
+	}
+	added, err := c.Add(v)
+	if !ok && !c.IsEmpty() {
+		m.data[k] = c
+	}
+	return added, err
+}
+
+// PutAll 将指定集合中的所有值添加到键 k 下
+//
+// 如果当前多值映射由于调用而更改,则返回 true.
+func (m *multimap) PutAll(k _map.Key, values collection.Collection) (bool, error) {
+	if values == nil {
+		return false, errs.NewError(errs.NilPointer, "Multimap.PutAll")
+	}
+	c, ok := m.data[k]
+	if !ok {
+		c = m.newCollection()
+	}
+	added, err := c.AddAll(values)
+	if !ok && !c.IsEmpty() {
+		m.data[k] = c
+	}
+	return added, err
+}
+
+// Remove 删除一个键为 k 且值为 v 的键值对
+//
+// 如果当前多值映射由于调用而更改,则返回 true.
+func (m *multimap) Remove(k _map.Key, v _map.Value) (bool, error) {
+	c, ok := m.data[k]
+	if !ok {
+		return false, nil
+	}
+	removed, err := c.Remove(v)
+	if c.IsEmpty() {
+		delete(m.data, k)
+	}
+	return removed, err
+}
+
+// RemoveAll 删除键 k 的所有值,并返回被删除的值
+//
+// 当前返回的error接口总为 nil
+func (m *multimap) RemoveAll(k _map.Key) (collection.Collection, error) {
+	c, ok := m.data[k]
+	if !ok {
+		return m.newCollection(), nil
+	}
+	delete(m.data, k)
+	return c, nil
+}
+
+// ReplaceValues 用指定集合替换键 k 的所有值,并返回被替换的值
+func (m *multimap) ReplaceValues(k _map.Key, values collection.Collection) (collection.Collection, error) {
+	if values == nil {
+		return nil, errs.NewError(errs.NilPointer, "Multimap.ReplaceValues")
+	}
+	old, _ := m.RemoveAll(k)
+	if _, err := m.PutAll(k, values); err != nil {
+		return old, err
+	}
+	return old, nil
+}
+
+// Clear 清空所有键值对
+//
+// 当前返回的error接口总为 nil
+func (m *multimap) Clear() error {
+	m.data = make(map[interface{}]collection.Collection)
+	return nil
+}
+
+// KeySet 返回所有不重复键的集
+//
+// 返回的集是当前多值映射的快照,修改它不会影响当前多值映射.
+func (m *multimap) KeySet() collection.Set {
+	keys := set.NewHashSet()
+	for k := range m.data {
+		_, _ = keys.Add(k)
+	}
+	return keys
+}
+
+// Keys 返回所有键的多重集,每个键的次数等于其值的个数
+//
+// 返回的多重集是当前多值映射的快照,修改它不会影响当前多值映射.
+func (m *multimap) Keys() collection.Multiset {
+	keys := set.NewHashMultiset()
+	for k, c := range m.data {
+		_, _ = keys.AddCount(k, c.Size())
+	}
+	return keys
+}
+
+// Values 返回所有值的集合
+//
+// 返回的集合是当前多值映射的快照,修改它不会影响当前多值映射.
+func (m *multimap) Values() collection.Collection {
+	values := list.NewSliceList(m.Size())
+	for _, c := range m.data {
+		_, _ = values.AddAll(c)
+	}
+	return values
+}
+
+// AsMap 返回键到值集合的映射
+//
+// 映射本身是当前多值映射的快照,但其中的值集合与 Get 返回的值集合相同,均为实时视图.
+func (m *multimap) AsMap() _map.Map {
+	result := hashmap.NewHashMap()
+	for k := range m.data {
+		_, _ = result.Put(k, m.view(k))
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package multimap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/list"
+	"github.com/chenquan/go-util/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListMultimap(t *testing.T) {
+	m := NewListMultimap()
+	_, _ = m.Put("a", 1)
+	_, _ = m.Put("a", 1)
+	_, _ = m.Put("b", 2)
+	assert.Equal(t, 3, m.Size())
+	assert.Equal(t, []collection.Element{1, 1}, m.Get("a").Slice())
+
+	contains, _ := m.ContainsEntry("b", 2)
+	assert.True(t, contains)
+	contains, _ = m.ContainsValue(3)
+	assert.False(t, contains)
+
+	keys := m.Keys()
+	assert.Equal(t, 2, keys.Count("a"))
+	assert.Equal(t, 1, keys.Count("b"))
+	assert.True(t, m.KeySet().Equals(set.NewHashSetWithElements("a", "b")))
+	assert.Equal(t, 3, m.Values().Size())
+
+	removed, _ := m.Remove("b", 2)
+	assert.True(t, removed)
+	contains, _ = m.ContainsKey("b")
+	assert.False(t, contains)
+
+	old, _ := m.RemoveAll("a")
+	assert.Equal(t, 2, old.Size())
+	assert.True(t, m.IsEmpty())
+}
+
+func TestListMultimap_LiveView(t *testing.T) {
+	m := NewListMultimap()
+	values := m.GetList("a")
+	assert.True(t, values.IsEmpty())
+	_, err := values.Get(0)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
+	var e *errs.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "Multimap.View.Get", e.Op)
+	assert.Equal(t, 0, e.Size)
+
+	_, _ = values.Add(1)
+	assert.Nil(t, values.AddIndex(0, 0))
+	assert.Equal(t, 2, m.Size())
+	assert.Equal(t, []collection.Element{0, 1}, m.Get("a").Slice())
+
+	_, _ = m.Put("a", 2)
+	assert.Equal(t, 3, values.Size())
+	assert.Equal(t, 2, values.LastIndex(2))
+
+	itr := values.Iterator()
+	for itr.HasNext() {
+		_, _ = itr.Next()
+		assert.Nil(t, itr.Remove())
+	}
+	contains, _ := m.ContainsKey("a")
+	assert.False(t, contains)
+
+	_, _ = m.PutAll("b", list.NewSliceListWithCollection(set.NewHashSetWithElements(1)))
+	asMap := m.AsMap()
+	v, _ := asMap.Get("b")
+	_, _ = v.(collection.Collection).Add(2)
+	assert.Equal(t, 2, m.Size())
+
+	old, _ := m.ReplaceValues("b", set.NewHashSetWithElements(9))
+	assert.Equal(t, []collection.Element{1, 2}, old.Slice())
+	assert.Equal(t, []collection.Element{9}, m.Get("b").Slice())
+}
+
+func TestSetMultimap(t *testing.T) {
+	m := NewSetMultimap()
+	added, _ := m.Put("a", 1)
+	assert.True(t, added)
+	added, _ = m.Put("a", 1)
+	assert.False(t, added)
+	_, _ = m.Put("a", 2)
+	assert.Equal(t, 2, m.Size())
+	assert.True(t, m.GetSet("a").Equals(set.NewHashSetWithElements(1, 2)))
+
+	values := m.Get("b")
+	added, _ = values.Add(3)
+	assert.True(t, added)
+	assert.Equal(t, 3, m.Size())
+	assert.Nil(t, values.Clear())
+	contains, _ := m.ContainsKey("b")
+	assert.False(t, contains)
+
+	assert.Nil(t, m.Clear())
+	assert.True(t, m.IsEmpty())
+	assert.Equal(t, 0, m.Get("a").Size())
+}
+
+func TestMultimap_Conformance(t *testing.T) {
+	t.Run("ListView", func(t *testing.T) {
+		collectiontest.TestList(t, func() collection.List {
+			return NewListMultimap().GetList("k")
+		})
+	})
+	t.Run("ListViewModel", func(t *testing.T) {
+		collectiontest.TestListModel(t, func() collection.List {
+			return NewListMultimap().GetList("k")
+		})
+	})
+	t.Run("SetView", func(t *testing.T) {
+		collectiontest.TestSet(t, func() collection.Set {
+			return NewSetMultimap().GetSet("k")
+		})
+	})
+	t.Run("SetViewModel", func(t *testing.T) {
+		collectiontest.TestSetModel(t, func() collection.Set {
+			return NewSetMultimap().GetSet("k")
+		})
+	})
+}
@@ -0,0 +1,62 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package multimap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/set"
+)
+
+var _ _map.SetMultimap = (*SetMultimap)(nil)
+
+// SetMultimap 值以 set.HashSet 保存的多值映射
+//
+// 同一个键下不允许重复的值,值的迭代顺序是不确定的.
+// 注意 SetMultimap 协程不安全,不能用于高并发.
+type SetMultimap struct {
+	multimap
+}
+
+// NewSetMultimap 创建空的集多值映射
+func NewSetMultimap() *SetMultimap {
+	m := &SetMultimap{multimap{
+		data: make(map[interface{}]collection.Collection),
+		newCollection: func() collection.Collection {
+			return set.NewHashSet()
+		},
+	}}
+	m.view = func(k _map.Key) collection.Collection {
+		return m.GetSet(k)
+	}
+	return m
+}
+
+// Get 返回键 k 对应的值集
+//
+// 返回的集是实时视图,对其的修改会反映到当前多值映射中,反之亦然.
+func (m *SetMultimap) Get(k _map.Key) collection.Collection {
+	return m.GetSet(k)
+}
+
+// GetSet 返回键 k 对应的值集
+//
+// 返回的集是实时视图,对其的修改会反映到当前多值映射中,反之亦然.
+func (m *SetMultimap) GetSet(k _map.Key) collection.Set {
+	return &valueCollection{m: &m.multimap, key: k}
+}
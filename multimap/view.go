@@ -0,0 +1,302 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package multimap
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+)
+
+var (
+	_ collection.Collection = (*valueCollection)(nil)
+	_ collection.List       = (*valueList)(nil)
+)
+
+// valueCollection 键对应的值集合视图
+//
+// 所有操作都委托给多值映射中该键当前的值集合,
+// 写入时按需创建值集合,值集合变为空时从多值映射中删除该键.
+type valueCollection struct {
+	m   *multimap   // 所属多值映射
+	key interface{} // 键
+}
+
+// delegate 返回键当前的值集合,不存在则返回 nil
+func (c *valueCollection) delegate() collection.Collection {
+	return c.m.data[c.key]
+}
+
+// getOrCreate 返回键当前的值集合,不存在则创建
+func (c *valueCollection) getOrCreate() collection.Collection {
+	d, ok := c.m.data[c.key]
+	if !ok {
+		d = c.m.newCollection()
+		c.m.data[c.key] = d
+	}
+	return d
+}
+
+// removeIfEmpty 值集合为空时从多值映射中删除该键
+func (c *valueCollection) removeIfEmpty() {
+	if d, ok := c.m.data[c.key]; ok && d.IsEmpty() {
+		delete(c.m.data, c.key)
+	}
+}
+
+// Size 返回值集合的大小
+func (c *valueCollection) Size() int {
+	if d := c.delegate(); d != nil {
+		return d.Size()
+	}
+	return 0
+}
+
+// IsEmpty 如果不存在元素则返回 true,否则返回 false
+func (c *valueCollection) IsEmpty() bool {
+	return c.Size() == 0
+}
+
+// Contains 如果值集合包含元素 e 则返回 true,否则返回 false
+func (c *valueCollection) Contains(e collection.Element) (bool, error) {
+	if d := c.delegate(); d != nil {
+		return d.Contains(e)
+	}
+	return false, nil
+}
+
+// Add 添加指定元素
+func (c *valueCollection) Add(e collection.Element) (bool, error) {
+	defer c.removeIfEmpty()
+	return c.getOrCreate().Add(e)
+}
+
+// Remove 删除指定元素
+func (c *valueCollection) Remove(e collection.Element) (bool, error) {
+	d := c.delegate()
+	if d == nil {
+		return false, nil
+	}
+	defer c.removeIfEmpty()
+	return d.Remove(e)
+}
+
+// ContainsAll 如果值集合包含指定集合中的所有元素，则返回 true,否则返回 false.
+func (c *valueCollection) ContainsAll(o collection.Collection) (bool, error) {
+	if o == nil {
+		return false, errs.NewError(errs.NilPointer, "Multimap.View.ContainsAll")
+	}
+	if d := c.delegate(); d != nil {
+		return d.ContainsAll(o)
+	}
+	return o.IsEmpty(), nil
+}
+
+// AddAll 将指定集合中的所有元素添加到值集合中
+func (c *valueCollection) AddAll(o collection.Collection) (bool, error) {
+	if o == nil {
+		return false, errs.NewError(errs.NilPointer, "Multimap.View.AddAll")
+	}
+	defer c.removeIfEmpty()
+	return c.getOrCreate().AddAll(o)
+}
+
+// RemoveAll 删除值集合中与指定集合相同的所有元素
+func (c *valueCollection) RemoveAll(o collection.Collection) (bool, error) {
+	d := c.delegate()
+	if d == nil {
+		return false, nil
+	}
+	defer c.removeIfEmpty()
+	return d.RemoveAll(o)
+}
+
+// RetainAll 仅保留值集合中包含在指定集合中的元素
+func (c *valueCollection) RetainAll(o collection.Collection) (bool, error) {
+	d := c.delegate()
+	if d == nil {
+		return false, nil
+	}
+	defer c.removeIfEmpty()
+	return d.RetainAll(o)
+}
+
+// Clear 清空值集合,即从多值映射中删除该键
+//
+// 当前返回的error接口总为 nil
+func (c *valueCollection) Clear() error {
+	delete(c.m.data, c.key)
+	return nil
+}
+
+// Equals 比较指定集合与值集合的相等性
+func (c *valueCollection) Equals(o collection.Collection) bool {
+	if o == nil {
+		return false
+	}
+	if d := c.delegate(); d != nil {
+		return d.Equals(o)
+	}
+	return o.IsEmpty()
+}
+
+// Slice 返回一个包含值集合中所有元素的切片
+func (c *valueCollection) Slice() []collection.Element {
+	if d := c.delegate(); d != nil {
+		return d.Slice()
+	}
+	return []collection.Element{}
+}
+
+// Iterator 返回值集合中元素的迭代器
+func (c *valueCollection) Iterator() collection.Iterator {
+	d := c.delegate()
+	if d == nil {
+		return &valueIterator{view: c}
+	}
+	return &valueIterator{view: c, itr: d.Iterator()}
+}
+
+// ForEach 对值集合中的每个元素执行 action
+func (c *valueCollection) ForEach(action function.Consumer) {
+	if d := c.delegate(); d != nil {
+		d.ForEach(action)
+	}
+}
+
+// RemoveIf 删除值集合中满足 filter 的所有元素
+func (c *valueCollection) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NewError(errs.NilPointer, "Multimap.View.RemoveIf")
+	}
+	d := c.delegate()
+	if d == nil {
+		return false, nil
+	}
+	defer c.removeIfEmpty()
+	return d.RemoveIf(filter)
+}
+
+// valueIterator 值集合视图的迭代器
+type valueIterator struct {
+	view *valueCollection    // 值集合视图
+	itr  collection.Iterator // 值集合的迭代器,值集合不存在时为 nil
+}
+
+// HasNext 如果当前迭代还有更多的元素则返回 true,否则返回 false
+func (itr *valueIterator) HasNext() bool {
+	return itr.itr != nil && itr.itr.HasNext()
+}
+
+// Next 返回当前迭代中的下一个元素
+func (itr *valueIterator) Next() (collection.Element, error) {
+	if itr.itr == nil {
+		return nil, errs.NewError(errs.NoSuchElement, "Multimap.View.Iterator.Next")
+	}
+	return itr.itr.Next()
+}
+
+// Remove 从值集合中移除当前迭代器返回的最后一个元素
+func (itr *valueIterator) Remove() error {
+	if itr.itr == nil {
+		return errs.NewError(errs.IllegalState, "Multimap.View.Iterator.Remove")
+	}
+	defer itr.view.removeIfEmpty()
+	return itr.itr.Remove()
+}
+
+// valueList 键对应的值列表视图
+type valueList struct {
+	valueCollection
+}
+
+// list 返回键当前的值列表,不存在则返回 nil
+func (l *valueList) list() collection.List {
+	if d := l.delegate(); d != nil {
+		return d.(collection.List)
+	}
+	return nil
+}
+
+// AddAllIndex 将指定集合中的所有元素插入值列表中的指定位置
+func (l *valueList) AddAllIndex(index int, c collection.Collection) (bool, error) {
+	defer l.removeIfEmpty()
+	return l.getOrCreate().(collection.List).AddAllIndex(index, c)
+}
+
+// Get 返回值列表中指定位置的元素
+func (l *valueList) Get(index int) (collection.Element, error) {
+	if d := l.list(); d != nil {
+		return d.Get(index)
+	}
+	return nil, errs.NewIndexError("Multimap.View.Get", index, 0)
+}
+
+// Set 用指定的元素替换值列表中指定位置的元素
+func (l *valueList) Set(index int, e collection.Element) (collection.Element, error) {
+	if d := l.list(); d != nil {
+		return d.Set(index, e)
+	}
+	return nil, errs.NewIndexError("Multimap.View.Set", index, 0)
+}
+
+// AddIndex 将指定的元素插入值列表中的指定位置
+func (l *valueList) AddIndex(index int, e collection.Element) error {
+	defer l.removeIfEmpty()
+	return l.getOrCreate().(collection.List).AddIndex(index, e)
+}
+
+// RemoveIndex 删除值列表中指定位置的元素
+func (l *valueList) RemoveIndex(index int) (collection.Element, error) {
+	d := l.list()
+	if d == nil {
+		return nil, errs.NewIndexError("Multimap.View.RemoveIndex", index, 0)
+	}
+	defer l.removeIfEmpty()
+	return d.RemoveIndex(index)
+}
+
+// Index 返回指定元素在值列表中首次出现的索引,如果不存在则返回-1
+func (l *valueList) Index(e collection.Element) int {
+	if d := l.list(); d != nil {
+		return d.Index(e)
+	}
+	return -1
+}
+
+// LastIndex 返回指定元素在值列表中最后一次出现的索引,如果不存在则返回-1
+func (l *valueList) LastIndex(e collection.Element) int {
+	if d := l.list(); d != nil {
+		return d.LastIndex(e)
+	}
+	return -1
+}
+
+// ReplaceAll 使用 operator 作用于每个元素的结果替换该元素
+func (l *valueList) ReplaceAll(operator function.UnaryOperator) {
+	if d := l.list(); d != nil {
+		d.ReplaceAll(operator)
+	}
+}
+
+// Sort 按 c 对值列表进行稳定排序
+func (l *valueList) Sort(c function.Comparator) {
+	if d := l.list(); d != nil {
+		d.Sort(c)
+	}
+}
@@ -0,0 +1,317 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package persistent
+
+import (
+	"math/bits"
+
+	_map "github.com/chenquan/go-util/backend/map"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/hashmap"
+	"github.com/chenquan/go-util/internal/hashcode"
+)
+
+// hamtEntry 哈希数组映射字典树的槽位
+//
+// node 不为 nil 时表示子树,否则表示键值对.
+type hamtEntry struct {
+	hash  uint64      // 键的哈希值
+	key   interface{} // 键
+	value interface{} // 值
+	node  *hamtNode   // 子树
+}
+
+// hamtNode 哈希数组映射字典树节点
+//
+// 哈希值的所有位都用完后仍然冲突的键值对保存在冲突节点中,冲突节点按线性查找.
+type hamtNode struct {
+	bitmap    uint32      // 槽位位图
+	entries   []hamtEntry // 按位图压缩的槽位
+	collision bool        // 是否为冲突节点
+}
+
+// index 返回哈希值在 shift 位移处对应的位与压缩后的下标
+func (n *hamtNode) index(hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & mask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// find 查找键 k
+func (n *hamtNode) find(hash uint64, shift uint, k interface{}) (interface{}, bool) {
+	for {
+		if n.collision {
+			for _, e := range n.entries {
+				if e.key == k {
+					return e.value, true
+				}
+			}
+			return nil, false
+		}
+		bit, i := n.index(hash, shift)
+		if n.bitmap&bit == 0 {
+			return nil, false
+		}
+		e := n.entries[i]
+		if e.node == nil {
+			if e.hash == hash && e.key == k {
+				return e.value, true
+			}
+			return nil, false
+		}
+		n = e.node
+		shift += nodeBits
+	}
+}
+
+// put 返回插入键值对后的新节点,以及是否新增了键
+func (n *hamtNode) put(hash uint64, shift uint, k, v interface{}) (*hamtNode, bool) {
+	if n.collision {
+		for i, e := range n.entries {
+			if e.key == k {
+				c := n.cloneEntries(0)
+				c.entries[i].value = v
+				return c, false
+			}
+		}
+		c := n.cloneEntries(1)
+		c.entries[len(n.entries)] = hamtEntry{hash: hash, key: k, value: v}
+		return c, true
+	}
+	bit, i := n.index(hash, shift)
+	if n.bitmap&bit == 0 {
+		c := &hamtNode{bitmap: n.bitmap | bit, entries: make([]hamtEntry, len(n.entries)+1)}
+		copy(c.entries, n.entries[:i])
+		c.entries[i] = hamtEntry{hash: hash, key: k, value: v}
+		copy(c.entries[i+1:], n.entries[i:])
+		return c, true
+	}
+	e := n.entries[i]
+	c := n.cloneEntries(0)
+	if e.node != nil {
+		child, added := e.node.put(hash, shift+nodeBits, k, v)
+		c.entries[i] = hamtEntry{node: child}
+		return c, added
+	}
+	if e.hash == hash && e.key == k {
+		c.entries[i].value = v
+		return c, false
+	}
+	c.entries[i] = hamtEntry{node: newHamtNode(e, hamtEntry{hash: hash, key: k, value: v}, shift+nodeBits)}
+	return c, true
+}
+
+// newHamtNode 创建包含两个键值对的子树
+func newHamtNode(e1, e2 hamtEntry, shift uint) *hamtNode {
+	if shift >= 64 {
+		return &hamtNode{collision: true, entries: []hamtEntry{e1, e2}}
+	}
+	b1 := uint32(1) << ((e1.hash >> shift) & mask)
+	b2 := uint32(1) << ((e2.hash >> shift) & mask)
+	if b1 == b2 {
+		return &hamtNode{bitmap: b1, entries: []hamtEntry{{node: newHamtNode(e1, e2, shift+nodeBits)}}}
+	}
+	if b1 > b2 {
+		e1, e2 = e2, e1
+	}
+	return &hamtNode{bitmap: b1 | b2, entries: []hamtEntry{e1, e2}}
+}
+
+// remove 返回删除键 k 后的新节点,以及是否删除了键
+//
+// 节点变为空时返回 nil.
+func (n *hamtNode) remove(hash uint64, shift uint, k interface{}) (*hamtNode, bool) {
+	if n.collision {
+		for i, e := range n.entries {
+			if e.key == k {
+				return n.without(0, i), true
+			}
+		}
+		return n, false
+	}
+	bit, i := n.index(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	e := n.entries[i]
+	if e.node == nil {
+		if e.hash != hash || e.key != k {
+			return n, false
+		}
+		return n.without(bit, i), true
+	}
+	child, removed := e.node.remove(hash, shift+nodeBits, k)
+	if !removed {
+		return n, false
+	}
+	if child == nil {
+		return n.without(bit, i), true
+	}
+	c := n.cloneEntries(0)
+	if len(child.entries) == 1 && child.entries[0].node == nil {
+		// 子树只剩一个键值对,将其上移
+		c.entries[i] = child.entries[0]
+	} else {
+		c.entries[i] = hamtEntry{node: child}
+	}
+	return c, true
+}
+
+// without 返回删除下标 i 处槽位后的新节点,节点变为空时返回 nil
+func (n *hamtNode) without(bit uint32, i int) *hamtNode {
+	if len(n.entries) == 1 {
+		return nil
+	}
+	c := &hamtNode{bitmap: n.bitmap &^ bit, collision: n.collision, entries: make([]hamtEntry, len(n.entries)-1)}
+	copy(c.entries, n.entries[:i])
+	copy(c.entries[i:], n.entries[i+1:])
+	return c
+}
+
+// cloneEntries 浅拷贝节点,并额外预留 extra 个槽位
+func (n *hamtNode) cloneEntries(extra int) *hamtNode {
+	c := &hamtNode{bitmap: n.bitmap, collision: n.collision, entries: make([]hamtEntry, len(n.entries), len(n.entries)+extra)}
+	copy(c.entries, n.entries)
+	c.entries = c.entries[:len(n.entries)+extra]
+	return c
+}
+
+// forEach 遍历所有键值对,action 返回 false 时停止遍历
+func (n *hamtNode) forEach(action func(k, v interface{}) bool) bool {
+	for _, e := range n.entries {
+		if e.node != nil {
+			if !e.node.forEach(action) {
+				return false
+			}
+		} else if !action(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Map 持久化映射
+//
+// 基于哈希数组映射字典树(HAMT)实现,键必须是可比较的.
+// Map 不可变,可安全地在多个协程之间共享.
+type Map struct {
+	root *hamtNode // 根节点
+	size int       // 键值对个数
+}
+
+var emptyMap = &Map{root: &hamtNode{}}
+
+// EmptyMap 返回空的持久化映射
+func EmptyMap() *Map {
+	return emptyMap
+}
+
+// NewMapWithMap 由指定映射创建持久化映射
+func NewMapWithMap(m _map.Map) *Map {
+	p := emptyMap
+	itr := m.EntrySet().Iterator()
+	for itr.HasNext() {
+		next, _ := itr.Next()
+		e := next.(_map.Entry)
+		k, _ := e.Key()
+		v, _ := e.Value()
+		p = p.Put(k, v)
+	}
+	return p
+}
+
+// hash 返回键的哈希值
+func hash(k interface{}) uint64 {
+	return uint64(hashcode.Of(k))
+}
+
+// Size 返回键值对的个数
+func (m *Map) Size() int {
+	return m.size
+}
+
+// IsEmpty 如果不存在键值对则返回 true,否则返回 false
+func (m *Map) IsEmpty() bool {
+	return m.size == 0
+}
+
+// Get 返回键 k 映射的值
+//
+// 如果键 k 不存在则返回 false.
+func (m *Map) Get(k _map.Key) (_map.Value, bool) {
+	return m.root.find(hash(k), 0, k)
+}
+
+// GetOrDefault 返回键 k 映射的值,如果不存在则返回 defaultValue
+func (m *Map) GetOrDefault(k _map.Key, defaultValue _map.Value) _map.Value {
+	if v, ok := m.Get(k); ok {
+		return v
+	}
+	return defaultValue
+}
+
+// ContainsKey 如果存在键 k 则返回 true,否则返回 false
+func (m *Map) ContainsKey(k _map.Key) bool {
+	_, ok := m.Get(k)
+	return ok
+}
+
+// Put 返回将键 k 映射到值 v 后的新映射
+func (m *Map) Put(k _map.Key, v _map.Value) *Map {
+	root, added := m.root.put(hash(k), 0, k, v)
+	size := m.size
+	if added {
+		size++
+	}
+	return &Map{root: root, size: size}
+}
+
+// Remove 返回删除键 k 后的新映射
+//
+// 如果键 k 不存在则返回当前映射.
+func (m *Map) Remove(k _map.Key) *Map {
+	root, removed := m.root.remove(hash(k), 0, k)
+	if !removed {
+		return m
+	}
+	if root == nil {
+		return emptyMap
+	}
+	return &Map{root: root, size: m.size - 1}
+}
+
+// ForEach 遍历所有键值对,遍历顺序是不确定的
+func (m *Map) ForEach(action function.BiConsumer) {
+	m.root.forEach(func(k, v interface{}) bool {
+		action(k, v)
+		return true
+	})
+}
+
+// Range 遍历所有键值对,f 返回 false 时停止遍历
+func (m *Map) Range(f func(k, v interface{}) bool) {
+	m.root.forEach(f)
+}
+
+// ToMap 返回包含所有键值对的可变映射
+func (m *Map) ToMap() *hashmap.HashMap {
+	h := hashmap.NewHashMap()
+	m.ForEach(func(k, v interface{}) {
+		_, _ = h.Put(k, v)
+	})
+	return h
+}
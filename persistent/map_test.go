@@ -0,0 +1,108 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package persistent
+
+import (
+	"testing"
+
+	"github.com/chenquan/go-util/hashmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// collidingKey 哈希码总是相同的键
+type collidingKey int
+
+func (k collidingKey) HashCode() int {
+	return 42
+}
+
+func TestMap(t *testing.T) {
+	m := EmptyMap()
+	versions := []*Map{m}
+	for i := 0; i < 5000; i++ {
+		m = m.Put(i, i*2)
+		versions = append(versions, m)
+	}
+	assert.Equal(t, 5000, m.Size())
+	for i := 0; i < 5000; i++ {
+		v, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i*2, v)
+	}
+	assert.False(t, m.ContainsKey(5000))
+	assert.Equal(t, -1, m.GetOrDefault(5000, -1))
+	for i, old := range versions {
+		assert.Equal(t, i, old.Size())
+	}
+
+	n := m.Put(1, "x")
+	assert.Equal(t, 5000, n.Size())
+	v, _ := n.Get(1)
+	assert.Equal(t, "x", v)
+	v, _ = m.Get(1)
+	assert.Equal(t, 2, v)
+
+	for i := 0; i < 5000; i += 2 {
+		m = m.Remove(i)
+	}
+	assert.Equal(t, 2500, m.Size())
+	assert.False(t, m.ContainsKey(0))
+	assert.True(t, m.ContainsKey(1))
+	assert.Equal(t, m, m.Remove(0))
+	for i := 1; i < 5000; i += 2 {
+		m = m.Remove(i)
+	}
+	assert.True(t, m.IsEmpty())
+	assert.Equal(t, 5000, versions[5000].Size())
+}
+
+func TestMap_Collision(t *testing.T) {
+	m := EmptyMap()
+	for i := 0; i < 10; i++ {
+		m = m.Put(collidingKey(i), i)
+	}
+	assert.Equal(t, 10, m.Size())
+	for i := 0; i < 10; i++ {
+		v, ok := m.Get(collidingKey(i))
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	m = m.Put(collidingKey(3), "x")
+	v, _ := m.Get(collidingKey(3))
+	assert.Equal(t, "x", v)
+	for i := 0; i < 10; i++ {
+		m = m.Remove(collidingKey(i))
+		assert.Equal(t, 9-i, m.Size())
+	}
+}
+
+func TestMap_Conversion(t *testing.T) {
+	h := hashmap.NewHashMap()
+	_, _ = h.Put("a", 1)
+	_, _ = h.Put("b", 2)
+	m := NewMapWithMap(h)
+	assert.Equal(t, 2, m.Size())
+	assert.True(t, h.Equals(m.ToMap()))
+
+	count := 0
+	m.Range(func(k, v interface{}) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+}
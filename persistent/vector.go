@@ -0,0 +1,321 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package persistent 持久化(不可变)集合
+//
+// 每次更新都返回一个新版本,新旧版本之间共享未修改的结构,
+// 因此更新的代价很小,且任意版本都可以安全地在多个协程之间共享.
+package persistent
+
+import (
+	"fmt"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/list"
+)
+
+var (
+	ImmutableErr = fmt.Errorf("immutable collection: %w", errs.UnsupportedOperation)
+)
+
+const (
+	nodeBits = 5
+	width    = 1 << nodeBits
+	mask     = width - 1
+)
+
+// vectorNode 向量字典树节点
+//
+// 内部节点的 array 中保存 *vectorNode,叶子节点的 array 中保存元素.
+type vectorNode struct {
+	array [width]interface{}
+}
+
+// clone 浅拷贝节点
+func (n *vectorNode) clone() *vectorNode {
+	c := *n
+	return &c
+}
+
+var emptyVectorNode = &vectorNode{}
+
+// Vector 持久化向量
+//
+// 基于32叉字典树实现,Get、Set、Append 与 Pop 的时间复杂度均为 O(log32 n).
+// Vector 不可变,可安全地在多个协程之间共享.
+type Vector struct {
+	size  int           // 元素个数
+	shift uint          // 根节点所在层的位移
+	root  *vectorNode   // 根节点
+	tail  []interface{} // 尾部缓冲区,最多 width 个元素
+}
+
+var emptyVector = &Vector{shift: nodeBits, root: emptyVectorNode, tail: []interface{}{}}
+
+// EmptyVector 返回空的持久化向量
+func EmptyVector() *Vector {
+	return emptyVector
+}
+
+// NewVector 由指定元素创建持久化向量
+func NewVector(elements ...collection.Element) *Vector {
+	v := emptyVector
+	for _, e := range elements {
+		v = v.Append(e)
+	}
+	return v
+}
+
+// NewVectorWithCollection 由指定集合创建持久化向量
+//
+// 元素顺序与集合迭代器返回的顺序相同.
+func NewVectorWithCollection(c collection.Collection) *Vector {
+	return NewVector(c.Slice()...)
+}
+
+// Size 返回元素个数
+func (v *Vector) Size() int {
+	return v.size
+}
+
+// IsEmpty 如果不存在元素则返回 true,否则返回 false
+func (v *Vector) IsEmpty() bool {
+	return v.size == 0
+}
+
+// tailOffset 返回尾部缓冲区中第一个元素的下标
+func (v *Vector) tailOffset() int {
+	if v.size < width {
+		return 0
+	}
+	return ((v.size - 1) >> nodeBits) << nodeBits
+}
+
+// arrayFor 返回包含下标 i 的叶子数组
+func (v *Vector) arrayFor(i int) []interface{} {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	n := v.root
+	for level := v.shift; level > 0; level -= nodeBits {
+		n = n.array[(i>>level)&mask].(*vectorNode)
+	}
+	return n.array[:]
+}
+
+// Get 返回指定位置的元素
+func (v *Vector) Get(index int) (collection.Element, error) {
+	if index < 0 || index >= v.size {
+		return nil, errs.NewIndexError("Vector.Get", index, v.size)
+	}
+	return v.arrayFor(index)[index&mask], nil
+}
+
+// Set 返回将指定位置的元素替换为 e 后的新向量
+func (v *Vector) Set(index int, e collection.Element) (*Vector, error) {
+	if index < 0 || index >= v.size {
+		return nil, errs.NewIndexError("Vector.Set", index, v.size)
+	}
+	if index >= v.tailOffset() {
+		tail := make([]interface{}, len(v.tail))
+		copy(tail, v.tail)
+		tail[index&mask] = e
+		return &Vector{size: v.size, shift: v.shift, root: v.root, tail: tail}, nil
+	}
+	return &Vector{size: v.size, shift: v.shift, root: doSet(v.shift, v.root, index, e), tail: v.tail}, nil
+}
+
+// doSet 返回将下标 i 处的元素替换为 e 后的新节点
+func doSet(level uint, n *vectorNode, i int, e collection.Element) *vectorNode {
+	c := n.clone()
+	if level == 0 {
+		c.array[i&mask] = e
+	} else {
+		sub := (i >> level) & mask
+		c.array[sub] = doSet(level-nodeBits, n.array[sub].(*vectorNode), i, e)
+	}
+	return c
+}
+
+// Append 返回在末尾添加元素 e 后的新向量
+func (v *Vector) Append(e collection.Element) *Vector {
+	if v.size-v.tailOffset() < width {
+		tail := make([]interface{}, len(v.tail)+1)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = e
+		return &Vector{size: v.size + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+	// 尾部缓冲区已满,将其放入字典树
+	tailNode := &vectorNode{}
+	copy(tailNode.array[:], v.tail)
+	shift := v.shift
+	var root *vectorNode
+	if (v.size >> nodeBits) > (1 << v.shift) {
+		// 根节点已满,树高加一
+		root = &vectorNode{}
+		root.array[0] = v.root
+		root.array[1] = newPath(v.shift, tailNode)
+		shift += nodeBits
+	} else {
+		root = v.pushTail(v.shift, v.root, tailNode)
+	}
+	return &Vector{size: v.size + 1, shift: shift, root: root, tail: []interface{}{e}}
+}
+
+// pushTail 将尾部节点放入字典树
+func (v *Vector) pushTail(level uint, parent, tailNode *vectorNode) *vectorNode {
+	sub := ((v.size - 1) >> level) & mask
+	c := parent.clone()
+	if level == nodeBits {
+		c.array[sub] = tailNode
+	} else if child, ok := parent.array[sub].(*vectorNode); ok {
+		c.array[sub] = v.pushTail(level-nodeBits, child, tailNode)
+	} else {
+		c.array[sub] = newPath(level-nodeBits, tailNode)
+	}
+	return c
+}
+
+// newPath 创建从 level 层到叶子节点 n 的路径
+func newPath(level uint, n *vectorNode) *vectorNode {
+	if level == 0 {
+		return n
+	}
+	p := &vectorNode{}
+	p.array[0] = newPath(level-nodeBits, n)
+	return p
+}
+
+// Pop 返回删除最后一个元素后的新向量
+//
+// 如果向量为空则返回 errs.NoSuchElement.
+func (v *Vector) Pop() (*Vector, error) {
+	if v.size == 0 {
+		return nil, errs.NewError(errs.NoSuchElement, "Vector.Pop")
+	}
+	if v.size == 1 {
+		return emptyVector, nil
+	}
+	if v.size-v.tailOffset() > 1 {
+		tail := make([]interface{}, len(v.tail)-1)
+		copy(tail, v.tail)
+		return &Vector{size: v.size - 1, shift: v.shift, root: v.root, tail: tail}, nil
+	}
+	// 尾部缓冲区只剩一个元素,从字典树中取出最后一个叶子作为新的尾部缓冲区
+	leaf := v.arrayFor(v.size - 2)
+	tail := make([]interface{}, width)
+	copy(tail, leaf)
+	root := v.popTail(v.shift, v.root)
+	shift := v.shift
+	if root == nil {
+		root = emptyVectorNode
+	}
+	if shift > nodeBits && root.array[1] == nil {
+		root = root.array[0].(*vectorNode)
+		shift -= nodeBits
+	}
+	return &Vector{size: v.size - 1, shift: shift, root: root, tail: tail}, nil
+}
+
+// popTail 从字典树中删除最后一个叶子节点,节点变为空时返回 nil
+func (v *Vector) popTail(level uint, n *vectorNode) *vectorNode {
+	sub := ((v.size - 2) >> level) & mask
+	if level > nodeBits {
+		child := v.popTail(level-nodeBits, n.array[sub].(*vectorNode))
+		if child == nil && sub == 0 {
+			return nil
+		}
+		c := n.clone()
+		if child == nil {
+			c.array[sub] = nil
+		} else {
+			c.array[sub] = child
+		}
+		return c
+	}
+	if sub == 0 {
+		return nil
+	}
+	c := n.clone()
+	c.array[sub] = nil
+	return c
+}
+
+// Slice 返回一个包含所有元素的切片
+//
+// 返回的切片是安全的,可任意修改不会影响当前向量.
+func (v *Vector) Slice() []collection.Element {
+	elements := make([]collection.Element, 0, v.size)
+	for i := 0; i < v.size; i += width {
+		array := v.arrayFor(i)
+		n := v.size - i
+		if n > width {
+			n = width
+		}
+		for _, e := range array[:n] {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// Iterator 返回元素的迭代器
+//
+// 迭代器的 Remove 方法总是返回 ImmutableErr.
+func (v *Vector) Iterator() collection.Iterator {
+	return &itrVector{data: v}
+}
+
+// ToList 返回包含所有元素的可变列表
+func (v *Vector) ToList() *list.SliceList {
+	l := list.NewSliceList(v.size)
+	for _, e := range v.Slice() {
+		_, _ = l.Add(e)
+	}
+	return l
+}
+
+// itrVector 实现 collection.Iterator 接口
+type itrVector struct {
+	data   *Vector       // 向量
+	cursor int           // 游标,指向下一个元素
+	array  []interface{} // 游标所在的叶子数组
+}
+
+// HasNext 如果当前迭代还有更多的元素则返回 true,否则返回 false
+func (itr *itrVector) HasNext() bool {
+	return itr.cursor < itr.data.size
+}
+
+// Next 返回当前迭代中的下一个元素
+func (itr *itrVector) Next() (collection.Element, error) {
+	if !itr.HasNext() {
+		return nil, errs.NewError(errs.NoSuchElement, "Vector.Iterator.Next")
+	}
+	if itr.cursor&mask == 0 {
+		itr.array = itr.data.arrayFor(itr.cursor)
+	}
+	e := itr.array[itr.cursor&mask]
+	itr.cursor++
+	return e, nil
+}
+
+// Remove 总是返回 ImmutableErr
+func (itr *itrVector) Remove() error {
+	return ImmutableErr
+}
@@ -0,0 +1,101 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package persistent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector(t *testing.T) {
+	v := EmptyVector()
+	assert.True(t, v.IsEmpty())
+	versions := []*Vector{v}
+	for i := 0; i < 2000; i++ {
+		v = v.Append(i)
+		versions = append(versions, v)
+	}
+	assert.Equal(t, 2000, v.Size())
+	for i := 0; i < 2000; i++ {
+		e, err := v.Get(i)
+		assert.Nil(t, err)
+		assert.Equal(t, i, e)
+	}
+	// 旧版本不受影响
+	for i, old := range versions {
+		assert.Equal(t, i, old.Size())
+	}
+	_, err := v.Get(2000)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
+	var indexErr *errs.Error
+	assert.True(t, errors.As(err, &indexErr))
+	assert.Equal(t, "Vector.Get", indexErr.Op)
+	assert.Equal(t, 2000, indexErr.Index)
+	assert.Equal(t, 2000, indexErr.Size)
+
+	w, err := v.Set(1000, "x")
+	assert.Nil(t, err)
+	e, _ := w.Get(1000)
+	assert.Equal(t, "x", e)
+	e, _ = v.Get(1000)
+	assert.Equal(t, 1000, e)
+	w, _ = w.Set(1999, "y")
+	e, _ = w.Get(1999)
+	assert.Equal(t, "y", e)
+
+	for i := 1999; i >= 0; i-- {
+		v, err = v.Pop()
+		assert.Nil(t, err)
+		assert.Equal(t, i, v.Size())
+		if i > 0 {
+			e, _ = v.Get(i - 1)
+			assert.Equal(t, i-1, e)
+		}
+	}
+	_, err = v.Pop()
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
+	assert.Equal(t, 2000, versions[2000].Size())
+}
+
+func TestVector_Conversion(t *testing.T) {
+	l := list.NewSliceListDefault()
+	for i := 0; i < 100; i++ {
+		_, _ = l.Add(i)
+	}
+	v := NewVectorWithCollection(l)
+	assert.Equal(t, l.Slice(), v.Slice())
+	assert.True(t, l.Equals(v.ToList()))
+
+	itr := v.Iterator()
+	var elements []collection.Element
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		elements = append(elements, e)
+	}
+	assert.Equal(t, l.Slice(), elements)
+	err := itr.Remove()
+	assert.Equal(t, ImmutableErr, err)
+	assert.True(t, errors.Is(err, errs.UnsupportedOperation))
+	_, err = itr.Next()
+	assert.True(t, errors.Is(err, errs.NoSuchElement))
+}
@@ -0,0 +1,258 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+)
+
+// Delayed 延迟元素,到期后才能从 DelayQueue 中取出
+type Delayed interface {
+	// Deadline 返回元素到期的时刻
+	Deadline() time.Time
+}
+
+// DelayedValue 在 At 时刻到期的值
+type DelayedValue struct {
+	Value interface{} // 值
+	At    time.Time   // 到期时刻
+}
+
+// Deadline 返回元素到期的时刻
+func (d DelayedValue) Deadline() time.Time {
+	return d.At
+}
+
+// delayHeap 按到期时刻排序的最小堆,实现 heap.Interface
+type delayHeap []Delayed
+
+// Len 返回元素个数
+func (h delayHeap) Len() int {
+	return len(h)
+}
+
+// Less 如果下标 i 的元素早于下标 j 的元素到期则返回 true
+func (h delayHeap) Less(i, j int) bool {
+	return h[i].Deadline().Before(h[j].Deadline())
+}
+
+// Swap 交换下标 i 与下标 j 的元素
+func (h delayHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+// Push 在末尾追加元素,由 heap.Push 调用
+func (h *delayHeap) Push(x interface{}) {
+	*h = append(*h, x.(Delayed))
+}
+
+// Pop 删除并返回末尾的元素,由 heap.Pop 调用
+func (h *delayHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// DelayQueue 延迟队列
+//
+// 元素按到期时刻排序,只有到期的元素才能被取出,常用于定时任务、重试与过期处理.
+// 时间由注入的时钟决定,测试时可以使用 time.ManualClock.
+// DelayQueue 协程安全.
+type DelayQueue struct {
+	mu      sync.Mutex
+	queue   delayHeap     // 按到期时刻排序的元素
+	clock   xtime.Clock   // 时钟
+	changed chan struct{} // 队列改变时关闭,用于唤醒等待的协程
+}
+
+// NewDelayQueue 创建使用系统时钟的延迟队列
+func NewDelayQueue() *DelayQueue {
+	return NewDelayQueueWithClock(xtime.SystemClock)
+}
+
+// NewDelayQueueWithClock 创建使用指定时钟的延迟队列
+func NewDelayQueueWithClock(clock xtime.Clock) *DelayQueue {
+	return &DelayQueue{
+		clock:   clock,
+		changed: make(chan struct{}),
+	}
+}
+
+// signal 唤醒所有等待的协程,调用时必须持有锁
+func (q *DelayQueue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// head 返回队头元素,如果队列为空则返回 nil,调用时必须持有锁
+func (q *DelayQueue) head() Delayed {
+	if len(q.queue) == 0 {
+		return nil
+	}
+	return q.queue[0]
+}
+
+// Offer 将元素 e 插入队列
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
+func (q *DelayQueue) Offer(e Delayed) error {
+	if e == nil {
+		return errs.NewError(errs.NilPointer, "DelayQueue.Offer")
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	old := q.head()
+	heap.Push(&q.queue, e)
+	if old == nil || e.Deadline().Before(old.Deadline()) {
+		// 队头改变,等待的协程需要重新计算等待时间
+		q.signal()
+	}
+	return nil
+}
+
+// Poll 删除并返回已到期的队头元素,如果队列为空或队头元素未到期则返回 nil
+func (q *DelayQueue) Poll() Delayed {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	head := q.head()
+	if head == nil || head.Deadline().After(q.clock.Now()) {
+		return nil
+	}
+	heap.Pop(&q.queue)
+	return head
+}
+
+// DrainExpired 删除并返回所有已到期的元素,按到期时刻排序
+func (q *DelayQueue) DrainExpired() []Delayed {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	var expired []Delayed
+	for head := q.head(); head != nil && !head.Deadline().After(now); head = q.head() {
+		heap.Pop(&q.queue)
+		expired = append(expired, head)
+	}
+	return expired
+}
+
+// Take 删除并返回队头元素,如果队列为空或队头元素未到期则阻塞直到有元素到期
+func (q *DelayQueue) Take() Delayed {
+	e, _ := q.TakeContext(context.Background())
+	return e
+}
+
+// TakeContext 删除并返回队头元素,如果队列为空或队头元素未到期则阻塞直到有元素到期
+//
+// 如果在有元素到期之前 ctx 结束,则返回 ctx.Err().
+func (q *DelayQueue) TakeContext(ctx context.Context) (Delayed, error) {
+	for {
+		q.mu.Lock()
+		var (
+			timer   xtime.Timer
+			expired <-chan time.Time
+		)
+		if head := q.head(); head != nil {
+			delay := head.Deadline().Sub(q.clock.Now())
+			if delay <= 0 {
+				heap.Pop(&q.queue)
+				q.mu.Unlock()
+				return head, nil
+			}
+			timer = q.clock.NewTimer(delay)
+			expired = timer.C()
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-expired:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Peek 返回但不删除队头元素,无论其是否到期,如果队列为空则返回 nil
+func (q *DelayQueue) Peek() Delayed {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.head()
+}
+
+// Remove 删除队列中的一个元素 e,无论其是否到期
+//
+// 如果队列包含元素 e 则返回 true,否则返回 false.
+func (q *DelayQueue) Remove(e Delayed) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, x := range q.queue {
+		if x == e {
+			heap.Remove(&q.queue, i)
+			q.signal()
+			return true
+		}
+	}
+	return false
+}
+
+// Size 返回队列中元素个数,包括未到期的元素
+func (q *DelayQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queue)
+}
+
+// IsEmpty 如果队列中不存在元素则返回 true,否则返回 false
+func (q *DelayQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Clear 清空队列中的所有元素
+func (q *DelayQueue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queue = nil
+	q.signal()
+}
+
+// Slice 返回按到期时刻排序的所有元素
+func (q *DelayQueue) Slice() []Delayed {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	elements := make([]Delayed, len(q.queue))
+	copy(elements, q.queue)
+	sort.SliceStable(elements, func(i, j int) bool {
+		return elements[i].Deadline().Before(elements[j].Deadline())
+	})
+	return elements
+}
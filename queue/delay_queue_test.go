@@ -0,0 +1,156 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chenquan/go-util/errs"
+	xtime "github.com/chenquan/go-util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func delayed(value interface{}, d time.Duration) DelayedValue {
+	return DelayedValue{Value: value, At: epoch.Add(d)}
+}
+
+func TestDelayQueue_Poll(t *testing.T) {
+	clock := xtime.NewManualClock(epoch)
+	q := NewDelayQueueWithClock(clock)
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+	assert.True(t, errors.Is(q.Offer(nil), errs.NilPointer))
+
+	assert.NoError(t, q.Offer(delayed("c", 3*time.Second)))
+	assert.NoError(t, q.Offer(delayed("a", time.Second)))
+	assert.NoError(t, q.Offer(delayed("b", 2*time.Second)))
+	assert.Equal(t, 3, q.Size())
+	assert.Equal(t, delayed("a", time.Second), q.Peek())
+	assert.Equal(t, []Delayed{delayed("a", time.Second), delayed("b", 2*time.Second), delayed("c", 3*time.Second)}, q.Slice())
+
+	// 未到期的元素不会被取出
+	assert.Nil(t, q.Poll())
+	clock.Advance(time.Second)
+	assert.Equal(t, delayed("a", time.Second), q.Poll())
+	assert.Nil(t, q.Poll())
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, []Delayed{delayed("b", 2*time.Second), delayed("c", 3*time.Second)}, q.DrainExpired())
+	assert.True(t, q.IsEmpty())
+	assert.Empty(t, q.DrainExpired())
+}
+
+func TestDelayQueue_Remove(t *testing.T) {
+	q := NewDelayQueueWithClock(xtime.NewManualClock(epoch))
+	_ = q.Offer(delayed(1, time.Second))
+	_ = q.Offer(delayed(2, time.Second))
+	assert.True(t, q.Remove(delayed(1, time.Second)))
+	assert.False(t, q.Remove(delayed(1, time.Second)))
+	assert.Equal(t, 1, q.Size())
+	q.Clear()
+	assert.True(t, q.IsEmpty())
+}
+
+func TestDelayQueue_Take(t *testing.T) {
+	clock := xtime.NewManualClock(epoch)
+	q := NewDelayQueueWithClock(clock)
+	_ = q.Offer(delayed("a", time.Minute))
+
+	taken := make(chan Delayed)
+	go func() {
+		taken <- q.Take()
+	}()
+	clock.WaitTimers(1)
+	select {
+	case <-taken:
+		assert.Fail(t, "Take returned before the head expired")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	clock.WaitTimers(1)
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, delayed("a", time.Minute), <-taken)
+	assert.True(t, q.IsEmpty())
+}
+
+func TestDelayQueue_TakeEarlierOffer(t *testing.T) {
+	clock := xtime.NewManualClock(epoch)
+	q := NewDelayQueueWithClock(clock)
+	_ = q.Offer(delayed("late", time.Hour))
+
+	taken := make(chan Delayed)
+	go func() {
+		taken <- q.Take()
+	}()
+	clock.WaitTimers(1)
+
+	// 插入更早到期的元素后,等待的协程按新的队头重新等待
+	_ = q.Offer(delayed("early", time.Second))
+	// 无论等待的协程是否已按新的队头创建定时器,前进时钟后都应取出新的队头
+	clock.Advance(time.Second)
+	assert.Equal(t, delayed("early", time.Second), <-taken)
+	assert.Equal(t, 1, q.Size())
+}
+
+func TestDelayQueue_TakeEmpty(t *testing.T) {
+	clock := xtime.NewManualClock(epoch)
+	q := NewDelayQueueWithClock(clock)
+
+	taken := make(chan Delayed)
+	go func() {
+		taken <- q.Take()
+	}()
+	// 已到期的元素插入后立即被取出
+	time.Sleep(10 * time.Millisecond)
+	_ = q.Offer(delayed("now", 0))
+	assert.Equal(t, delayed("now", 0), <-taken)
+}
+
+func TestDelayQueue_TakeContext(t *testing.T) {
+	clock := xtime.NewManualClock(epoch)
+	q := NewDelayQueueWithClock(clock)
+	_ = q.Offer(delayed("a", time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		_, err := q.TakeContext(ctx)
+		errc <- err
+	}()
+	clock.WaitTimers(1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-errc)
+	assert.Equal(t, 1, q.Size())
+	// 取消后等待的定时器被停止
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestDelayQueue_SystemClock(t *testing.T) {
+	q := NewDelayQueue()
+	start := time.Now()
+	_ = q.Offer(DelayedValue{Value: "a", At: start.Add(20 * time.Millisecond)})
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, "a", q.Take().(DelayedValue).Value)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
@@ -0,0 +1,557 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package queue
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
+)
+
+var _ collection.Queue = (*RingBuffer)(nil)
+
+var (
+	// FullErr 缓冲区已满,与 errs.Full 相同
+	FullErr = errs.Full
+)
+
+// OverflowPolicy 环形缓冲区已满时添加元素的策略
+type OverflowPolicy int
+
+const (
+	// Overwrite 覆盖最旧的元素
+	Overwrite OverflowPolicy = iota
+	// Reject 拒绝添加新元素
+	Reject
+)
+
+// RingBuffer 固定容量的环形缓冲区
+//
+// 元素按添加顺序从最旧到最新排列,队头为最旧的元素.
+// 缓冲区已满时按 OverflowPolicy 覆盖最旧的元素或拒绝新元素.
+// 迭代器遍历创建时的快照,遍历过程中缓冲区被覆盖不会影响迭代.
+// 不允许 nil 元素.零值的容量为0,添加元素总返回 FullErr,应使用 NewRingBuffer 创建.
+// 注意 RingBuffer 协程不安全,不能用于高并发.
+type RingBuffer struct {
+	elements    []collection.Element // 数据,长度即容量
+	head        int                  // 最旧元素的下标
+	size        int                  // 元素个数
+	policy      OverflowPolicy       // 已满时的策略
+	modCount    int                  // 修改次数,用于迭代器检测并发修改
+	elementType reflect.Type         // 解码时元素的类型
+}
+
+// NewRingBuffer 创建容量为 capacity 的环形缓冲区,已满时覆盖最旧的元素
+//
+// 如果 capacity 小于1则引发 panic.
+func NewRingBuffer(capacity int) *RingBuffer {
+	return NewRingBufferWithPolicy(capacity, Overwrite)
+}
+
+// NewRingBufferWithPolicy 创建容量为 capacity 的环形缓冲区,已满时按 policy 处理
+//
+// 如果 capacity 小于1则引发 panic.
+func NewRingBufferWithPolicy(capacity int, policy OverflowPolicy) *RingBuffer {
+	if capacity < 1 {
+		panic(errs.NewError(errs.IllegalArgument, "NewRingBufferWithPolicy").WithDetail("capacity must be positive"))
+	}
+	return &RingBuffer{elements: make([]collection.Element, capacity), policy: policy}
+}
+
+// Cap 返回缓冲区的容量
+func (r *RingBuffer) Cap() int {
+	return len(r.elements)
+}
+
+// Policy 返回缓冲区已满时的策略
+func (r *RingBuffer) Policy() OverflowPolicy {
+	return r.policy
+}
+
+// IsFull 如果缓冲区已满则返回 true,否则返回 false
+func (r *RingBuffer) IsFull() bool {
+	return r.size == len(r.elements)
+}
+
+// physical 返回逻辑下标 i 对应的切片下标
+func (r *RingBuffer) physical(i int) int {
+	return (r.head + i) % len(r.elements)
+}
+
+// Size 返回缓冲区中元素个数
+func (r *RingBuffer) Size() int {
+	return r.size
+}
+
+// IsEmpty 如果缓冲区中不存在元素则返回 true,否则返回 false
+func (r *RingBuffer) IsEmpty() bool {
+	return r.size == 0
+}
+
+// Get 返回逻辑下标为 index 的元素,下标0为最旧的元素
+//
+// 如果下标越界则返回 errs.IndexOutOfBound.
+func (r *RingBuffer) Get(index int) (collection.Element, error) {
+	if index < 0 || index >= r.size {
+		return nil, errs.NewIndexError("RingBuffer.Get", index, r.size)
+	}
+	return r.elements[r.physical(index)], nil
+}
+
+// Set 使用元素 e 替换逻辑下标为 index 的元素,并返回原来的元素
+//
+// 如果下标越界则返回 errs.IndexOutOfBound,如果 e 为 nil 则返回 errs.NilPointer.
+func (r *RingBuffer) Set(index int, e collection.Element) (collection.Element, error) {
+	if e == nil {
+		return nil, errs.NewError(errs.NilPointer, "RingBuffer.Set")
+	}
+	if index < 0 || index >= r.size {
+		return nil, errs.NewIndexError("RingBuffer.Set", index, r.size)
+	}
+	p := r.physical(index)
+	old := r.elements[p]
+	r.elements[p] = e
+	return old, nil
+}
+
+// index 返回元素 e 首次出现的逻辑下标,不存在则返回-1
+func (r *RingBuffer) index(e collection.Element) int {
+	if e == nil {
+		return -1
+	}
+	for i := 0; i < r.size; i++ {
+		if r.elements[r.physical(i)] == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains 如果缓冲区包含元素 e 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (r *RingBuffer) Contains(e collection.Element) (bool, error) {
+	return r.index(e) >= 0, nil
+}
+
+// Add 将元素 e 添加为最新的元素
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
+// 缓冲区已满时,如果策略为 Overwrite 则覆盖最旧的元素,如果策略为 Reject 则返回 FullErr.
+func (r *RingBuffer) Add(e collection.Element) (bool, error) {
+	if e == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.Add")
+	}
+	if r.IsFull() {
+		if r.policy == Reject || len(r.elements) == 0 {
+			return false, errs.NewError(errs.Full, "RingBuffer.Add").WithSize(len(r.elements)).WithElement(e)
+		}
+		r.elements[r.head] = e
+		r.head = (r.head + 1) % len(r.elements)
+	} else {
+		r.elements[r.physical(r.size)] = e
+		r.size++
+	}
+	r.modCount++
+	return true, nil
+}
+
+// removeAt 删除逻辑下标为 i 的元素,之后的元素依次前移
+func (r *RingBuffer) removeAt(i int) {
+	for j := i; j < r.size-1; j++ {
+		r.elements[r.physical(j)] = r.elements[r.physical(j+1)]
+	}
+	r.elements[r.physical(r.size-1)] = nil
+	r.size--
+	r.modCount++
+}
+
+// Remove 删除缓冲区中最旧的一个元素 e
+//
+// 如果缓冲区包含元素 e 则返回 true,否则返回 false.
+// 当前返回的error接口总为 nil
+func (r *RingBuffer) Remove(e collection.Element) (bool, error) {
+	i := r.index(e)
+	if i < 0 {
+		return false, nil
+	}
+	r.removeAt(i)
+	return true, nil
+}
+
+// ContainsAll 如果缓冲区包含指定集合中的所有元素则返回 true,否则返回 false
+func (r *RingBuffer) ContainsAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.ContainsAll")
+	}
+	for _, e := range c.Slice() {
+		if r.index(e) < 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// AddAll 将指定集合中的所有元素按迭代顺序添加到缓冲区
+//
+// 遇到错误时停止添加,已添加的元素不会回滚.
+func (r *RingBuffer) AddAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.AddAll")
+	}
+	modified := false
+	for _, e := range c.Slice() {
+		if _, err := r.Add(e); err != nil {
+			return modified, err
+		}
+		modified = true
+	}
+	return modified, nil
+}
+
+// RemoveAll 删除缓冲区中包含在指定集合中的所有元素
+func (r *RingBuffer) RemoveAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.RemoveAll")
+	}
+	return r.batchRemove(c, true)
+}
+
+// RetainAll 仅保留缓冲区中包含在指定集合中的元素
+func (r *RingBuffer) RetainAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.RetainAll")
+	}
+	return r.batchRemove(c, false)
+}
+
+// batchRemove 批量删除元素
+//
+// 如果 remove 等于 true,则删除包含在指定集合中的元素,否则删除未包含在指定集合中的元素.
+func (r *RingBuffer) batchRemove(c collection.Collection, remove bool) (bool, error) {
+	var err error
+	modified, _ := r.RemoveIf(func(e interface{}) bool {
+		if err != nil {
+			return false
+		}
+		contains, e1 := c.Contains(e)
+		if e1 != nil {
+			err = e1
+			return false
+		}
+		return contains == remove
+	})
+	return modified, err
+}
+
+// Clear 清空缓冲区中的所有元素
+//
+// 当前返回的error接口总为 nil
+func (r *RingBuffer) Clear() error {
+	for i := range r.elements {
+		r.elements[i] = nil
+	}
+	r.head, r.size = 0, 0
+	r.modCount++
+	return nil
+}
+
+// Equals 如果指定集合与缓冲区大小相同且按迭代顺序对应的元素均相等则返回 true,否则返回 false
+func (r *RingBuffer) Equals(c collection.Collection) bool {
+	if r == c {
+		return true
+	}
+	if c == nil || r.size != c.Size() {
+		return false
+	}
+	i1 := r.Iterator()
+	i2 := c.Iterator()
+	for i1.HasNext() && i2.HasNext() {
+		e1, _ := i1.Next()
+		e2, _ := i2.Next()
+		if e1 != e2 {
+			return false
+		}
+	}
+	return !(i1.HasNext() || i2.HasNext())
+}
+
+// Slice 按从最旧到最新的顺序返回缓冲区中所有元素的切片
+//
+// 返回的切片是安全的,可任意修改不会影响缓冲区.
+func (r *RingBuffer) Slice() []collection.Element {
+	elements := make([]collection.Element, r.size)
+	for i := range elements {
+		elements[i] = r.elements[r.physical(i)]
+	}
+	return elements
+}
+
+// Iterator 返回按从最旧到最新的顺序遍历缓冲区快照的迭代器
+func (r *RingBuffer) Iterator() collection.Iterator {
+	return &itrRingBuffer{buffer: r, snapshot: r.Slice(), lastRet: -1, expectedModCount: r.modCount}
+}
+
+// DescendingIterator 返回按从最新到最旧的顺序遍历缓冲区快照的迭代器
+func (r *RingBuffer) DescendingIterator() collection.Iterator {
+	return &itrRingBuffer{buffer: r, snapshot: r.Slice(), lastRet: -1, expectedModCount: r.modCount, descending: true}
+}
+
+// ForEach 按从最旧到最新的顺序对缓冲区中的每个元素执行 action
+func (r *RingBuffer) ForEach(action function.Consumer) {
+	for i := 0; i < r.size; i++ {
+		action(r.elements[r.physical(i)])
+	}
+}
+
+// RemoveIf 删除缓冲区中满足 filter 的所有元素
+//
+// 只遍历一次缓冲区,将保留的元素依次前移.
+// 如果有元素被删除则返回 true,否则返回 false.
+// 如果 filter 为 nil 则返回 errs.NilPointer.
+func (r *RingBuffer) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NewError(errs.NilPointer, "RingBuffer.RemoveIf")
+	}
+	w := 0
+	for i := 0; i < r.size; i++ {
+		if e := r.elements[r.physical(i)]; !filter(e) {
+			r.elements[r.physical(w)] = e
+			w++
+		}
+	}
+	if w == r.size {
+		return false, nil
+	}
+	for i := w; i < r.size; i++ {
+		r.elements[r.physical(i)] = nil
+	}
+	r.size = w
+	r.modCount++
+	return true, nil
+}
+
+// Offer 将元素 e 添加为最新的元素
+//
+// 与 Add 不同,缓冲区已满且策略为 Reject 时返回 false 而不返回错误.
+// 如果 e 为 nil 则返回 errs.NilPointer.
+func (r *RingBuffer) Offer(e collection.Element) (bool, error) {
+	added, err := r.Add(e)
+	if errors.Is(err, FullErr) {
+		return false, nil
+	}
+	return added, err
+}
+
+// Poll 删除并返回最旧的元素,如果缓冲区为空则返回 nil
+func (r *RingBuffer) Poll() collection.Element {
+	if r.size == 0 {
+		return nil
+	}
+	e := r.elements[r.head]
+	r.elements[r.head] = nil
+	r.head = (r.head + 1) % len(r.elements)
+	r.size--
+	r.modCount++
+	return e
+}
+
+// Delete 删除并返回最旧的元素
+//
+// 如果缓冲区为空则返回 errs.NoSuchElement.
+func (r *RingBuffer) Delete() (collection.Element, error) {
+	if r.size == 0 {
+		return nil, errs.NewError(errs.NoSuchElement, "RingBuffer.Delete")
+	}
+	return r.Poll(), nil
+}
+
+// Element 返回但不删除最旧的元素
+//
+// 如果缓冲区为空则返回 errs.NoSuchElement.
+func (r *RingBuffer) Element() (collection.Element, error) {
+	return r.GetFirst()
+}
+
+// Peek 返回但不删除最旧的元素,如果缓冲区为空则返回 nil
+func (r *RingBuffer) Peek() collection.Element {
+	e, _ := r.GetFirst()
+	return e
+}
+
+// GetFirst 返回但不删除最旧的元素
+//
+// 如果缓冲区为空则返回 errs.NoSuchElement.
+func (r *RingBuffer) GetFirst() (collection.Element, error) {
+	if r.size == 0 {
+		return nil, errs.NewError(errs.NoSuchElement, "RingBuffer.GetFirst")
+	}
+	return r.elements[r.head], nil
+}
+
+// GetLast 返回但不删除最新的元素
+//
+// 如果缓冲区为空则返回 errs.NoSuchElement.
+func (r *RingBuffer) GetLast() (collection.Element, error) {
+	if r.size == 0 {
+		return nil, errs.NewError(errs.NoSuchElement, "RingBuffer.GetLast")
+	}
+	return r.elements[r.physical(r.size-1)], nil
+}
+
+// itrRingBuffer 环形缓冲区快照的迭代器
+type itrRingBuffer struct {
+	buffer           *RingBuffer
+	snapshot         []collection.Element // 创建迭代器时的元素
+	cursor           int                  // 已返回的元素个数
+	lastRet          int                  // 上一个返回元素在快照中的下标,-1表示不存在
+	removed          int                  // 通过迭代器删除的元素个数
+	expectedModCount int                  // 期望的缓冲区修改次数
+	descending       bool                 // 是否从最新到最旧遍历
+}
+
+// HasNext 如果还有更多的元素则返回 true,否则返回 false
+func (itr *itrRingBuffer) HasNext() bool {
+	return itr.cursor < len(itr.snapshot)
+}
+
+// Next 返回快照中的下一个元素
+//
+// 如果没有更多的元素则返回 errs.NoSuchElement.
+func (itr *itrRingBuffer) Next() (collection.Element, error) {
+	if !itr.HasNext() {
+		return nil, errs.NewError(errs.NoSuchElement, "RingBuffer.Iterator.Next")
+	}
+	itr.lastRet = itr.cursor
+	if itr.descending {
+		itr.lastRet = len(itr.snapshot) - 1 - itr.cursor
+	}
+	itr.cursor++
+	return itr.snapshot[itr.lastRet], nil
+}
+
+// Remove 从缓冲区中删除上一次调用 Next 返回的元素
+//
+// 如果创建迭代器后缓冲区被迭代器以外的操作修改则返回 errs.ConcurrentModification.
+func (itr *itrRingBuffer) Remove() error {
+	if itr.lastRet < 0 {
+		return errs.NewError(errs.IllegalState, "RingBuffer.Iterator.Remove")
+	}
+	if itr.buffer.modCount != itr.expectedModCount {
+		return errs.NewError(errs.ConcurrentModification, "RingBuffer.Iterator.Remove")
+	}
+	i := itr.lastRet
+	if !itr.descending {
+		// 之前删除的元素都位于该元素之前
+		i -= itr.removed
+	}
+	itr.buffer.removeAt(i)
+	itr.removed++
+	itr.expectedModCount = itr.buffer.modCount
+	itr.lastRet = -1
+	return nil
+}
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (r *RingBuffer) SetElementType(t reflect.Type) {
+	r.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,按从最旧到最新的顺序编码为 JSON 数组
+func (r *RingBuffer) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(r.Slice())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换缓冲区中的所有元素
+//
+// 缓冲区的容量与策略不变,元素个数超过容量时按策略处理.
+// 如果存在 null 元素则返回 errs.NilPointer.
+func (r *RingBuffer) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, r.elementType)
+	if err != nil {
+		return err
+	}
+	return r.setElements(elements)
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (r *RingBuffer) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(r.Slice(), r.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换缓冲区中的所有元素
+//
+// 缓冲区的容量与策略不变,元素个数超过容量时按策略处理.
+func (r *RingBuffer) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, r.elementType)
+	if err != nil {
+		return err
+	}
+	return r.setElements(elements)
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (r *RingBuffer) GobEncode() ([]byte, error) {
+	return r.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (r *RingBuffer) GobDecode(data []byte) error {
+	return r.UnmarshalBinary(data)
+}
+
+// String 返回缓冲区的字符串表示,按从最旧到最新的顺序,形如 [1, 2, 3]
+func (r *RingBuffer) String() string {
+	return codec.String(r.Slice())
+}
+
+// setElements 使用指定元素替换缓冲区中的所有元素
+//
+// 如果存在 nil 元素,或者元素个数超过容量且策略为 Reject,则返回错误且缓冲区不变.
+// 零值的缓冲区容量取元素个数.
+func (r *RingBuffer) setElements(elements []collection.Element) error {
+	for _, e := range elements {
+		if e == nil {
+			return errs.NewError(errs.NilPointer, "RingBuffer.Unmarshal")
+		}
+	}
+	if len(r.elements) == 0 {
+		if len(elements) == 0 {
+			return nil
+		}
+		r.elements = make([]collection.Element, len(elements))
+	}
+	if len(elements) > len(r.elements) {
+		if r.policy == Reject {
+			return errs.NewError(errs.Full, "RingBuffer.Unmarshal").WithSize(len(r.elements))
+		}
+		elements = elements[len(elements)-len(r.elements):]
+	}
+	_ = r.Clear()
+	for _, e := range elements {
+		_, _ = r.Add(e)
+	}
+	return nil
+}
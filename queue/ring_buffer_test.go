@@ -0,0 +1,183 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuffer_Overwrite(t *testing.T) {
+	r := NewRingBuffer(3)
+	for i := 1; i <= 5; i++ {
+		added, err := r.Add(i)
+		assert.True(t, added)
+		assert.Nil(t, err)
+	}
+	assert.True(t, r.IsFull())
+	assert.Equal(t, 3, r.Size())
+	assert.Equal(t, []collection.Element{3, 4, 5}, r.Slice())
+	first, _ := r.GetFirst()
+	last, _ := r.GetLast()
+	assert.Equal(t, 3, first)
+	assert.Equal(t, 5, last)
+	assert.Equal(t, "[3, 4, 5]", r.String())
+
+	assert.Equal(t, 3, r.Poll())
+	_, _ = r.Offer(6)
+	_, _ = r.Offer(7)
+	assert.Equal(t, []collection.Element{5, 6, 7}, r.Slice())
+
+	_, err := r.Add(nil)
+	assert.True(t, errors.Is(err, errs.NilPointer))
+}
+
+func TestRingBuffer_Reject(t *testing.T) {
+	r := NewRingBufferWithPolicy(2, Reject)
+	_, _ = r.Add(1)
+	_, _ = r.Add(2)
+	added, err := r.Add(3)
+	assert.False(t, added)
+	assert.True(t, errors.Is(err, FullErr))
+	added, err = r.Offer(3)
+	assert.False(t, added)
+	assert.Nil(t, err)
+	assert.Equal(t, []collection.Element{1, 2}, r.Slice())
+
+	var zero RingBuffer
+	_, err = zero.Add(1)
+	assert.True(t, errors.Is(err, FullErr))
+	assert.Nil(t, zero.Poll())
+
+	assert.Panics(t, func() { NewRingBuffer(0) })
+}
+
+func TestRingBuffer_Get(t *testing.T) {
+	r := NewRingBuffer(3)
+	for i := 1; i <= 4; i++ {
+		_, _ = r.Add(i)
+	}
+	for i, want := range []int{2, 3, 4} {
+		e, err := r.Get(i)
+		assert.Nil(t, err)
+		assert.Equal(t, want, e)
+	}
+	_, err := r.Get(3)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
+	_, err = r.Get(-1)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
+
+	old, err := r.Set(0, 20)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, old)
+	assert.Equal(t, []collection.Element{20, 3, 4}, r.Slice())
+	_, err = r.Set(3, 1)
+	assert.True(t, errors.Is(err, errs.IndexOutOfBound))
+}
+
+func TestRingBuffer_Iterator(t *testing.T) {
+	r := NewRingBuffer(4)
+	for i := 1; i <= 6; i++ {
+		_, _ = r.Add(i)
+	}
+	// 遍历快照,期间覆盖不影响迭代
+	itr := r.Iterator()
+	_, _ = r.Add(7)
+	var got []collection.Element
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		got = append(got, e)
+	}
+	assert.Equal(t, []collection.Element{3, 4, 5, 6}, got)
+	assert.True(t, errors.Is(itr.Remove(), errs.ConcurrentModification))
+
+	itr = r.Iterator()
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		if e.(int)%2 == 0 {
+			assert.Nil(t, itr.Remove())
+		}
+	}
+	assert.Equal(t, []collection.Element{5, 7}, r.Slice())
+
+	_, _ = r.Add(8)
+	_, _ = r.Add(9)
+	got = nil
+	itr = r.DescendingIterator()
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		got = append(got, e)
+		if e == 8 {
+			assert.Nil(t, itr.Remove())
+		}
+	}
+	assert.Equal(t, []collection.Element{9, 8, 7, 5}, got)
+	assert.Equal(t, []collection.Element{5, 7, 9}, r.Slice())
+}
+
+func TestRingBuffer_RemoveIf(t *testing.T) {
+	r := NewRingBuffer(5)
+	for i := 1; i <= 8; i++ {
+		_, _ = r.Add(i)
+	}
+	modified, err := r.RemoveIf(func(e interface{}) bool { return e.(int)%2 == 1 })
+	assert.Nil(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, []collection.Element{4, 6, 8}, r.Slice())
+	_, _ = r.Add(9)
+	_, _ = r.Add(10)
+	_, _ = r.Add(11)
+	assert.Equal(t, []collection.Element{6, 8, 9, 10, 11}, r.Slice())
+}
+
+func TestRingBuffer_JSON(t *testing.T) {
+	r := NewRingBuffer(2)
+	assert.Nil(t, json.Unmarshal([]byte(`[1,2,3]`), r))
+	assert.Equal(t, []collection.Element{float64(2), float64(3)}, r.Slice())
+	data, err := json.Marshal(r)
+	assert.Nil(t, err)
+	assert.Equal(t, `[2,3]`, string(data))
+
+	r = NewRingBufferWithPolicy(2, Reject)
+	assert.True(t, errors.Is(json.Unmarshal([]byte(`[1,2,3]`), r), FullErr))
+	assert.True(t, r.IsEmpty())
+}
+
+func TestRingBuffer_Conformance(t *testing.T) {
+	collectiontest.TestQueue(t, func() collection.Queue { return NewRingBuffer(64) })
+}
+
+func TestRingBuffer_FullError(t *testing.T) {
+	r := NewRingBufferWithPolicy(1, Reject)
+	_, _ = r.Add("a")
+	_, err := r.Add("b")
+	assert.True(t, errors.Is(err, errs.Full))
+	var e *errs.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 1, e.Size)
+	assert.Equal(t, "b", e.Element)
+
+	assert.PanicsWithError(t, "NewRingBufferWithPolicy: illegal argument: capacity must be positive",
+		func() { NewRingBuffer(-1) })
+}
@@ -18,79 +18,125 @@
 package queue
 
 import (
-	"fmt"
+	"reflect"
+
 	"github.com/chenquan/go-util/backend/collection"
 	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
+)
+
+const (
+	// 默认容量
+	defaultCapacity = 16
+	// 最小容量
+	minCapacity = 8
 )
 
+// SliceDeQueue 基于循环切片实现 collection.DeQueue 接口
+//
+// 不允许 nil 元素.
+// 注意 SliceDeQueue 协程不安全,不能用于高并发.
 type SliceDeQueue struct {
-	elements []collection.Element
-	head     int
-	tail     int
+	elements    []collection.Element // 数据,长度总是2的幂
+	head        int                  // 队头下标
+	tail        int                  // 下一个入队元素的下标
+	elementType reflect.Type         // 解码时元素的类型
 }
 
+// NewSliceDeQueue 创建双端队列
+//
+// 默认容量: 16
+func NewSliceDeQueue() *SliceDeQueue {
+	return &SliceDeQueue{elements: make([]collection.Element, defaultCapacity)}
+}
+
+// NewSliceDeQueueWithCapacity 创建可容纳至少 numElements 个元素的双端队列
+func NewSliceDeQueueWithCapacity(numElements int) *SliceDeQueue {
+	return &SliceDeQueue{elements: make([]collection.Element, calculateSize(numElements))}
+}
+
+// calculateSize 返回可容纳 numElements 个元素的最小的2的幂
+func calculateSize(numElements int) int {
+	capacity := minCapacity
+	for capacity <= numElements {
+		capacity <<= 1
+	}
+	return capacity
+}
+
+// Size 返回队列中元素个数
 func (s *SliceDeQueue) Size() int {
-	return (s.tail-s.head)&len(s.elements) - 1
+	return (s.tail - s.head) & (len(s.elements) - 1)
 }
 
+// IsEmpty 如果队列中不存在元素则返回 true,否则返回 false
 func (s *SliceDeQueue) IsEmpty() bool {
-	return s.Size() == 0
+	return s.head == s.tail
 }
 
+// Contains 如果队列包含元素 e 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
 func (s *SliceDeQueue) Contains(e collection.Element) (bool, error) {
 	if e == nil {
 		return false, nil
 	}
-	n := len(s.elements) - 1
-	i := s.head
-	x := s.elements[i]
-	for ; x != nil; x = s.elements[i] {
-		if x == e {
+	mask := len(s.elements) - 1
+	for i := s.head; i != s.tail; i = (i + 1) & mask {
+		if s.elements[i] == e {
 			return true, nil
 		}
-		i = (i + 1) & n
 	}
 	return false, nil
 }
 
+// Add 将元素 e 添加到队尾
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
 func (s *SliceDeQueue) Add(e collection.Element) (bool, error) {
-	return true, s.AddLast(e)
+	if err := s.AddLast(e); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
+// Remove 删除队列中首次出现的元素 e
+//
+// 等同于 RemoveFirstOccurrence.
 func (s *SliceDeQueue) Remove(e collection.Element) (bool, error) {
-	iterator := s.Iterator()
-	for iterator.HasNext() {
-		next, _ := iterator.Next()
-		if next == e {
-			_ = iterator.Remove()
-			return true, nil
-		}
-	}
-	return false, nil
+	return s.RemoveFirstOccurrence(e)
 }
 
+// ContainsAll 如果队列包含指定集合中的所有元素则返回 true,否则返回 false
 func (s *SliceDeQueue) ContainsAll(c collection.Collection) (bool, error) {
 	if c == nil {
-		return false, errs.NilPointer
+		return false, errs.NewError(errs.NilPointer, "SliceDeQueue.ContainsAll")
 	}
 	iterator := c.Iterator()
 	for iterator.HasNext() {
-		next, _ := iterator.Next()
+		next, err := iterator.Next()
+		if err != nil {
+			return false, err
+		}
 		if contains, _ := s.Contains(next); !contains {
 			return false, nil
 		}
 	}
-	return false, nil
+	return true, nil
 }
 
+// AddAll 将指定集合中的所有元素按迭代顺序添加到队尾
 func (s *SliceDeQueue) AddAll(c collection.Collection) (bool, error) {
 	if c == nil {
-		return false, errs.NilPointer
+		return false, errs.NewError(errs.NilPointer, "SliceDeQueue.AddAll")
 	}
-	elements := c.Slice()
 	modified := false
-	for _, element := range elements {
-		add, _ := s.Add(element)
+	for _, element := range c.Slice() {
+		add, err := s.Add(element)
+		if err != nil {
+			return modified, err
+		}
 		if add {
 			modified = true
 		}
@@ -98,68 +144,69 @@ func (s *SliceDeQueue) AddAll(c collection.Collection) (bool, error) {
 	return modified, nil
 }
 
+// RemoveAll 删除队列中包含在指定集合中的所有元素
 func (s *SliceDeQueue) RemoveAll(c collection.Collection) (bool, error) {
 	if c == nil {
-		return false, errs.NilPointer
+		return false, errs.NewError(errs.NilPointer, "SliceDeQueue.RemoveAll")
 	}
-	modified := false
-
-	iterator := s.Iterator()
-	for iterator.HasNext() {
-		e, _ := iterator.Next()
-		contains, err := c.Contains(e)
-		if err != nil {
-			return false, err
-		}
-		if contains {
-			_ = iterator.Remove()
-			modified = true
-		}
-	}
-	return modified, nil
+	return s.batchRemove(c, true)
 }
 
+// RetainAll 仅保留队列中包含在指定集合中的元素
 func (s *SliceDeQueue) RetainAll(c collection.Collection) (bool, error) {
 	if c == nil {
-		return false, errs.NilPointer
+		return false, errs.NewError(errs.NilPointer, "SliceDeQueue.RetainAll")
 	}
-	modified := false
+	return s.batchRemove(c, false)
+}
 
+// batchRemove 批量删除元素
+//
+// 如果 remove 等于 true,则删除包含在指定集合中的元素,否则删除未包含在指定集合中的元素.
+func (s *SliceDeQueue) batchRemove(c collection.Collection, remove bool) (bool, error) {
+	modified := false
 	iterator := s.Iterator()
 	for iterator.HasNext() {
-		e, _ := iterator.Next()
+		e, err := iterator.Next()
+		if err != nil {
+			return modified, err
+		}
 		contains, err := c.Contains(e)
 		if err != nil {
-			return false, err
+			return modified, err
 		}
-		if !contains {
-			_ = iterator.Remove()
+		if contains == remove {
+			if err = iterator.Remove(); err != nil {
+				return modified, err
+			}
 			modified = true
 		}
 	}
 	return modified, nil
 }
 
+// Clear 清空队列中的所有元素
+//
+// 当前返回的error接口总为 nil
 func (s *SliceDeQueue) Clear() error {
 	h, t := s.head, s.tail
 	if t != h {
 		s.tail, s.head = 0, 0
 		mask := len(s.elements) - 1
-		i := h
-		for i != t {
-			// help cg
+		for i := h; i != t; i = (i + 1) & mask {
+			// help gc
 			s.elements[i] = nil
-			i = (i + 1) & mask
 		}
 	}
 	return nil
 }
 
+// Equals 如果指定集合与队列大小相同且按迭代顺序对应的元素均相等则返回 true,否则返回 false
 func (s *SliceDeQueue) Equals(c collection.Collection) bool {
 	if s == c {
 		return true
 	}
-	if s.Size() != c.Size() {
+	if c == nil || s.Size() != c.Size() {
 		return false
 	}
 	i1 := s.Iterator()
@@ -171,201 +218,283 @@ func (s *SliceDeQueue) Equals(c collection.Collection) bool {
 			return false
 		}
 	}
-	return true
+	return !(i1.HasNext() || i2.HasNext())
 }
 
+// Slice 按从队头到队尾的顺序返回队列中所有元素的切片
+//
+// 返回的切片是安全的,可任意修改不会影响队列.
 func (s *SliceDeQueue) Slice() []collection.Element {
-	n := len(s.elements)
-	h := s.head
-	r := n - h
-	elements := make([]collection.Element, n)
-	copy(elements[0:r], s.elements[h:n])
-	copy(elements[r:r+h], s.elements[0:h])
+	size := s.Size()
+	elements := make([]collection.Element, size)
+	if size == 0 {
+		return elements
+	}
+	if s.head < s.tail {
+		copy(elements, s.elements[s.head:s.tail])
+	} else {
+		r := copy(elements, s.elements[s.head:])
+		copy(elements[r:], s.elements[:s.tail])
+	}
 	return elements
 }
 
+// ForEach 按从队头到队尾的顺序对队列中的每个元素执行 action
+func (s *SliceDeQueue) ForEach(action function.Consumer) {
+	mask := len(s.elements) - 1
+	for i := s.head; i != s.tail; i = (i + 1) & mask {
+		action(s.elements[i])
+	}
+}
+
+// RemoveIf 删除队列中满足 filter 的所有元素
+//
+// 只遍历一次队列,将保留的元素依次向队头方向移动.
+// 如果有元素被删除则返回 true,否则返回 false.
+// 如果 filter 为 nil 则返回 errs.NilPointer.
+func (s *SliceDeQueue) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NewError(errs.NilPointer, "SliceDeQueue.RemoveIf")
+	}
+	mask := len(s.elements) - 1
+	w := s.head
+	for r := s.head; r != s.tail; r = (r + 1) & mask {
+		if e := s.elements[r]; !filter(e) {
+			s.elements[w] = e
+			w = (w + 1) & mask
+		}
+	}
+	if w == s.tail {
+		return false, nil
+	}
+	// 清除多余元素的引用,便于垃圾回收
+	for i := w; i != s.tail; i = (i + 1) & mask {
+		s.elements[i] = nil
+	}
+	s.tail = w
+	return true, nil
+}
+
+// Iterator 返回按从队头到队尾的顺序遍历队列的迭代器
 func (s *SliceDeQueue) Iterator() collection.Iterator {
 	return &sliceDequeueItr{data: s, cursor: s.head, fence: s.tail, lastRet: -1}
 }
 
+// Offer 将元素 e 添加到队尾
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
 func (s *SliceDeQueue) Offer(e collection.Element) (bool, error) {
-	err := s.AddLast(e)
-	if err != nil {
-		return false, err
-	}
-	return true, nil
+	return s.Add(e)
 }
 
+// Poll 删除并返回队头元素,如果队列为空则返回 nil
 func (s *SliceDeQueue) Poll() collection.Element {
 	element, _ := s.RemoveFirst()
 	return element
 }
 
+// Delete 删除并返回队头元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) Delete() (collection.Element, error) {
 	return s.RemoveFirst()
 }
 
+// Element 返回但不删除队头元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) Element() (collection.Element, error) {
 	return s.GetFirst()
 }
 
+// Peek 返回但不删除队头元素,如果队列为空则返回 nil
 func (s *SliceDeQueue) Peek() collection.Element {
-	return s.elements[s.head]
+	element, _ := s.GetFirst()
+	return element
 }
 
+// AddFirst 将元素 e 添加到队头
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
 func (s *SliceDeQueue) AddFirst(e collection.Element) error {
 	if e == nil {
-		return errs.NilPointer
+		return errs.NewError(errs.NilPointer, "SliceDeQueue.AddFirst")
 	}
+	s.ensureInitialized()
 	s.head = (s.head - 1) & (len(s.elements) - 1)
 	s.elements[s.head] = e
 	if s.head == s.tail {
-		if err := s.doubleCapacity(); err != nil {
-			return err
-		}
+		return s.doubleCapacity()
 	}
 	return nil
 }
 
+// AddLast 将元素 e 添加到队尾
+//
+// 如果 e 为 nil 则返回 errs.NilPointer.
 func (s *SliceDeQueue) AddLast(e collection.Element) error {
 	if e == nil {
-		return errs.NilPointer
+		return errs.NewError(errs.NilPointer, "SliceDeQueue.AddLast")
 	}
+	s.ensureInitialized()
 	s.elements[s.tail] = e
 	s.tail = (s.tail + 1) & (len(s.elements) - 1)
 	if s.tail == s.head {
-		if err := s.doubleCapacity(); err != nil {
-			return err
-		}
+		return s.doubleCapacity()
 	}
 	return nil
 }
 
+// ensureInitialized 为零值的队列分配默认容量的切片
+func (s *SliceDeQueue) ensureInitialized() {
+	if len(s.elements) == 0 {
+		s.elements = make([]collection.Element, defaultCapacity)
+		s.head, s.tail = 0, 0
+	}
+}
+
+// RemoveFirst 删除并返回队头元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) RemoveFirst() (collection.Element, error) {
-	element := s.elements[s.head]
-	if element == nil {
-		return nil, errs.NoSuchElement
+	if s.IsEmpty() {
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.RemoveFirst")
 	}
+	element := s.elements[s.head]
 	s.elements[s.head] = nil
 	s.head = (s.head + 1) & (len(s.elements) - 1)
 	return element, nil
 }
 
+// RemoveLast 删除并返回队尾元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) RemoveLast() (collection.Element, error) {
+	if s.IsEmpty() {
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.RemoveLast")
+	}
 	t := (s.tail - 1) & (len(s.elements) - 1)
 	element := s.elements[t]
-	if element == nil {
-		return nil, errs.NoSuchElement
-	}
 	s.elements[t] = nil
 	s.tail = t
 	return element, nil
 }
 
+// GetFirst 返回但不删除队头元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) GetFirst() (collection.Element, error) {
-	element := s.elements[s.head]
-	if element == nil {
-		return nil, errs.NoSuchElement
+	if s.IsEmpty() {
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.GetFirst")
 	}
-	return element, nil
+	return s.elements[s.head], nil
 }
 
+// GetLast 返回但不删除队尾元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) GetLast() (collection.Element, error) {
-	element := s.elements[(s.tail-1)&(len(s.elements)-1)]
-	if element == nil {
-		return nil, errs.NoSuchElement
+	if s.IsEmpty() {
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.GetLast")
 	}
-	return element, nil
+	return s.elements[(s.tail-1)&(len(s.elements)-1)], nil
 }
 
+// RemoveFirstOccurrence 删除从队头到队尾首次出现的元素 e
+//
+// 如果队列包含元素 e 则返回 true,否则返回 false.
+// 当前返回的error接口总为 nil
 func (s *SliceDeQueue) RemoveFirstOccurrence(e collection.Element) (bool, error) {
-	i := s.head
-	x := s.elements[i]
+	if e == nil {
+		return false, nil
+	}
 	mask := len(s.elements) - 1
-	for ; x != nil; x = s.elements[i] {
-		if e == x {
-			if _, err := s.delete(i); err != nil {
-				return false, err
-			}
+	for i := s.head; i != s.tail; i = (i + 1) & mask {
+		if s.elements[i] == e {
+			s.delete(i)
 			return true, nil
 		}
-		i = (i + 1) & mask
 	}
 	return false, nil
 }
-func (s *SliceDeQueue) delete(index int) (bool, error) {
-	h := s.head
-	t := s.tail
-	mask := len(s.elements) - 1
-	front := (index - h) & mask
-	back := (t - index) & mask
-	if front >= ((t - h) & mask) {
-		return false, errs.ConcurrentModification
-	}
-	if front < back {
-		if h <= index {
-			copy(s.elements[h+1:h+1+front+1], s.elements[h:h+front+1])
-		} else {
-			copy(s.elements[1:index+2], s.elements[:index+1])
-			s.elements[0] = mask
-			copy(s.elements[h+1:h+mask-h+1], s.elements[h:h+mask-h+1])
-		}
-		s.elements[h] = nil
-		s.head = (h + 1) & mask
-		return true, nil
-	} else {
-		if index < t {
-			copy(s.elements[index:index+back+1], s.elements[index+1:index+1+back+1])
-			s.tail = t - 1
-		} else {
-			copy(s.elements[index:index+mask-index+1], s.elements[index+1:index+1+mask-index+1])
-			s.elements[mask] = s.elements[0]
-			copy(s.elements[:t+1], s.elements[1:1+t+1])
-			s.tail = (t - 1) & mask
-		}
-	}
-
-	return true, nil
-}
 
+// RemoveLastOccurrence 删除从队头到队尾最后一次出现的元素 e
+//
+// 如果队列包含元素 e 则返回 true,否则返回 false.
+// 当前返回的error接口总为 nil
 func (s *SliceDeQueue) RemoveLastOccurrence(e collection.Element) (bool, error) {
 	if e == nil {
 		return false, nil
 	}
 	mask := len(s.elements) - 1
-	i := (s.tail - 1) & mask
-
-	for x := s.elements[i]; x != nil; x = s.elements[i] {
-		if x == e {
-			if _, err := s.delete(i); err != nil {
-				return false, err
-			}
+	for i := s.tail; i != s.head; {
+		i = (i - 1) & mask
+		if s.elements[i] == e {
+			s.delete(i)
 			return true, nil
 		}
-		i = (i - 1) & mask
 	}
 	return false, nil
 }
 
+// delete 删除下标为 i 的元素,移动离 i 较近一端的元素填补空位
+//
+// 如果移动的是 i 之后的元素(即队尾前移)则返回 true,否则返回 false(即队头后移).
+func (s *SliceDeQueue) delete(i int) bool {
+	mask := len(s.elements) - 1
+	h, t := s.head, s.tail
+	front := (i - h) & mask
+	back := (t - i) & mask
+	if front < back {
+		// 将 [h, i) 中的元素后移一位
+		for j := i; j != h; {
+			p := (j - 1) & mask
+			s.elements[j] = s.elements[p]
+			j = p
+		}
+		s.elements[h] = nil
+		s.head = (h + 1) & mask
+		return false
+	}
+	// 将 (i, t) 中的元素前移一位
+	for j := i; ; {
+		n := (j + 1) & mask
+		if n == t {
+			break
+		}
+		s.elements[j] = s.elements[n]
+		j = n
+	}
+	s.tail = (t - 1) & mask
+	s.elements[s.tail] = nil
+	return true
+}
+
+// Push 将元素 e 压入队列表示的栈中,即添加到队头
 func (s *SliceDeQueue) Push(e collection.Element) error {
 	return s.AddFirst(e)
 }
 
+// Pop 从队列表示的栈中弹出元素,即删除并返回队头元素
+//
+// 如果队列为空则返回 errs.NoSuchElement.
 func (s *SliceDeQueue) Pop() (collection.Element, error) {
 	return s.RemoveFirst()
 }
 
+// DescendingIterator 返回按从队尾到队头的顺序遍历队列的迭代器
 func (s *SliceDeQueue) DescendingIterator() collection.Iterator {
-	panic("implement me")
+	return &descendingSliceDequeueItr{data: s, cursor: s.tail, fence: s.head, lastRet: -1}
 }
 
+// doubleCapacity 在队列已满时将容量扩大一倍
 func (s *SliceDeQueue) doubleCapacity() error {
 	n := len(s.elements)
 	h := s.head
 	r := n - h
 	newSize := n << 1
 	if newSize < 0 {
-		return fmt.Errorf("deque too big")
+		return errs.NewError(errs.Full, "SliceDeQueue.doubleCapacity").WithSize(n)
 	}
 	elements := make([]collection.Element, newSize)
 	copy(elements[0:r], s.elements[h:n])
@@ -376,40 +505,157 @@ func (s *SliceDeQueue) doubleCapacity() error {
 	return nil
 }
 
+// sliceDequeueItr 从队头到队尾的迭代器
 type sliceDequeueItr struct {
 	data    *SliceDeQueue
-	cursor  int
-	fence   int
-	lastRet int
+	cursor  int // 下一个返回元素的下标
+	fence   int // 迭代结束的下标,用于检测并发修改
+	lastRet int // 上一个返回元素的下标,-1表示不存在
 }
 
+// HasNext 如果还有更多的元素则返回 true,否则返回 false
 func (s *sliceDequeueItr) HasNext() bool {
-	return s.lastRet != s.fence
+	return s.cursor != s.fence
 }
 
+// Next 返回下一个元素
+//
+// 如果没有更多的元素则返回 errs.NoSuchElement,
+// 如果迭代过程中队列被迭代器以外的操作修改则返回 errs.ConcurrentModification.
 func (s *sliceDequeueItr) Next() (collection.Element, error) {
 	if !s.HasNext() {
-		return nil, errs.NoSuchElement
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.Iterator.Next")
 	}
 	element := s.data.elements[s.cursor]
-	if s.HasNext() || element == nil {
-		return nil, errs.ConcurrentModification
+	if s.data.tail != s.fence || element == nil {
+		return nil, errs.NewError(errs.ConcurrentModification, "SliceDeQueue.Iterator.Next")
 	}
 	s.lastRet = s.cursor
 	s.cursor = (s.cursor + 1) & (len(s.data.elements) - 1)
 	return element, nil
 }
 
+// Remove 删除上一次调用 Next 返回的元素
 func (s *sliceDequeueItr) Remove() error {
 	if s.lastRet < 0 {
-		return errs.IllegalState
+		return errs.NewError(errs.IllegalState, "SliceDeQueue.Iterator.Remove")
 	}
-	if b, err := s.data.delete(s.cursor); err != nil {
-		return err
-	} else if b {
+	if s.data.delete(s.lastRet) {
+		// 后续元素前移了一位
 		s.cursor = (s.cursor - 1) & (len(s.data.elements) - 1)
 		s.fence = s.data.tail
 	}
 	s.lastRet = -1
 	return nil
 }
+
+// descendingSliceDequeueItr 从队尾到队头的迭代器
+type descendingSliceDequeueItr struct {
+	data    *SliceDeQueue
+	cursor  int // 上一个返回元素之后的下标
+	fence   int // 迭代结束的下标,用于检测并发修改
+	lastRet int // 上一个返回元素的下标,-1表示不存在
+}
+
+// HasNext 如果还有更多的元素则返回 true,否则返回 false
+func (s *descendingSliceDequeueItr) HasNext() bool {
+	return s.cursor != s.fence
+}
+
+// Next 返回下一个元素
+//
+// 如果没有更多的元素则返回 errs.NoSuchElement,
+// 如果迭代过程中队列被迭代器以外的操作修改则返回 errs.ConcurrentModification.
+func (s *descendingSliceDequeueItr) Next() (collection.Element, error) {
+	if !s.HasNext() {
+		return nil, errs.NewError(errs.NoSuchElement, "SliceDeQueue.DescendingIterator.Next")
+	}
+	cursor := (s.cursor - 1) & (len(s.data.elements) - 1)
+	element := s.data.elements[cursor]
+	if s.data.head != s.fence || element == nil {
+		return nil, errs.NewError(errs.ConcurrentModification, "SliceDeQueue.DescendingIterator.Next")
+	}
+	s.cursor = cursor
+	s.lastRet = cursor
+	return element, nil
+}
+
+// Remove 删除上一次调用 Next 返回的元素
+func (s *descendingSliceDequeueItr) Remove() error {
+	if s.lastRet < 0 {
+		return errs.NewError(errs.IllegalState, "SliceDeQueue.DescendingIterator.Remove")
+	}
+	if !s.data.delete(s.lastRet) {
+		// 之前的元素后移了一位
+		s.cursor = (s.cursor + 1) & (len(s.data.elements) - 1)
+		s.fence = s.data.head
+	}
+	s.lastRet = -1
+	return nil
+}
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (s *SliceDeQueue) SetElementType(t reflect.Type) {
+	s.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,按从队头到队尾的顺序编码为 JSON 数组
+func (s *SliceDeQueue) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(s.Slice())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换队列中的所有元素
+//
+// 如果存在 null 元素则返回 errs.NilPointer.
+func (s *SliceDeQueue) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, s.elementType)
+	if err != nil {
+		return err
+	}
+	return s.setElements(elements)
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (s *SliceDeQueue) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(s.Slice(), s.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换队列中的所有元素
+func (s *SliceDeQueue) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, s.elementType)
+	if err != nil {
+		return err
+	}
+	return s.setElements(elements)
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (s *SliceDeQueue) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (s *SliceDeQueue) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// String 返回队列的字符串表示,形如 [1, 2, 3]
+func (s *SliceDeQueue) String() string {
+	return codec.String(s.Slice())
+}
+
+// setElements 使用指定元素替换队列中的所有元素
+func (s *SliceDeQueue) setElements(elements []collection.Element) error {
+	for _, e := range elements {
+		if e == nil {
+			return errs.NewError(errs.NilPointer, "SliceDeQueue.Unmarshal")
+		}
+	}
+	s.elements = make([]collection.Element, calculateSize(len(elements)))
+	s.head = 0
+	s.tail = copy(s.elements, elements)
+	return nil
+}
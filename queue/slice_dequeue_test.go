@@ -18,7 +18,14 @@
 package queue
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
@@ -30,3 +37,75 @@ func Test1(t *testing.T) {
 	copy(s, []int{33, 3})
 	fmt.Println(s)
 }
+
+func TestSliceDeQueue_MarshalJSON(t *testing.T) {
+	q := NewSliceDeQueue()
+	_ = q.AddLast(2)
+	_ = q.AddFirst(1)
+	_ = q.AddLast(3)
+	data, err := json.Marshal(q)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[1,2,3]", string(data))
+	assert.Equal(t, "[1, 2, 3]", q.String())
+
+	decoded := &SliceDeQueue{}
+	decoded.SetElementType(reflect.TypeOf(0))
+	assert.Equal(t, nil, json.Unmarshal(data, decoded))
+	assert.Equal(t, []collection.Element{1, 2, 3}, decoded.Slice())
+	assert.Equal(t, 3, decoded.Size())
+	assert.True(t, errors.Is(json.Unmarshal([]byte("[1,null]"), NewSliceDeQueue()), errs.NilPointer))
+
+	data, err = q.GobEncode()
+	assert.Equal(t, nil, err)
+	decoded = NewSliceDeQueue()
+	assert.Equal(t, nil, decoded.UnmarshalBinary(data))
+	assert.Equal(t, []collection.Element{1, 2, 3}, decoded.Slice())
+}
+
+func TestSliceDeQueue_RemoveIf(t *testing.T) {
+	// 元素跨越切片末尾
+	d := NewSliceDeQueueWithCapacity(7)
+	for i := 1; i <= 4; i++ {
+		_ = d.AddLast(i)
+	}
+	for i := 0; i > -3; i-- {
+		_ = d.AddFirst(i)
+	}
+	assert.Greater(t, d.head, d.tail)
+
+	modified, err := d.RemoveIf(func(e interface{}) bool {
+		return e.(int)%2 != 0
+	})
+	assert.Equal(t, nil, err)
+	assert.True(t, modified)
+	assert.Equal(t, []collection.Element{-2, 0, 2, 4}, d.Slice())
+	assert.Equal(t, 4, d.Size())
+	nils := 0
+	for _, e := range d.elements {
+		if e == nil {
+			nils++
+		}
+	}
+	assert.Equal(t, len(d.elements)-4, nils)
+
+	modified, err = d.RemoveIf(nil)
+	assert.True(t, errors.Is(err, errs.NilPointer))
+	assert.False(t, modified)
+}
+
+func TestSliceDeQueue_Conformance(t *testing.T) {
+	newDeQueue := func() collection.DeQueue {
+		return NewSliceDeQueue()
+	}
+	t.Run("DeQueue", func(t *testing.T) {
+		collectiontest.TestDeQueue(t, newDeQueue)
+	})
+	t.Run("DeQueueModel", func(t *testing.T) {
+		collectiontest.TestDeQueueModel(t, newDeQueue)
+	})
+	t.Run("ZeroValue", func(t *testing.T) {
+		collectiontest.TestDeQueue(t, func() collection.DeQueue {
+			return &SliceDeQueue{}
+		})
+	})
+}
@@ -0,0 +1,195 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package set
+
+import (
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+)
+
+// DisjointSet 并查集
+//
+// 使用路径压缩与按秩合并,各操作的均摊时间复杂度接近 O(1).
+// 元素必须是可比较的(可作为 map 的键).
+// 注意 DisjointSet 协程不安全,不能用于高并发.
+type DisjointSet struct {
+	parent map[collection.Element]collection.Element // 元素的父节点,根节点的父节点为自身
+	rank   map[collection.Element]int                // 根节点的秩
+	sizes  map[collection.Element]int                // 根节点所在集合的大小
+}
+
+// NewDisjointSet 创建空的并查集
+func NewDisjointSet() *DisjointSet {
+	return &DisjointSet{
+		parent: make(map[collection.Element]collection.Element),
+		rank:   make(map[collection.Element]int),
+		sizes:  make(map[collection.Element]int),
+	}
+}
+
+// NewDisjointSetWithElements 创建每个指定元素各自成为一个集合的并查集
+func NewDisjointSetWithElements(elements ...collection.Element) *DisjointSet {
+	d := NewDisjointSet()
+	for _, e := range elements {
+		d.Add(e)
+	}
+	return d
+}
+
+// Size 返回元素个数
+func (d *DisjointSet) Size() int {
+	return len(d.parent)
+}
+
+// SetCount 返回集合个数
+func (d *DisjointSet) SetCount() int {
+	return len(d.sizes)
+}
+
+// Contains 如果包含元素 e 则返回 true,否则返回 false
+func (d *DisjointSet) Contains(e collection.Element) bool {
+	_, ok := d.parent[e]
+	return ok
+}
+
+// Add 将元素 e 添加为只包含自身的集合
+//
+// 如果元素不存在则返回 true,否则返回 false.
+func (d *DisjointSet) Add(e collection.Element) bool {
+	if d.Contains(e) {
+		return false
+	}
+	d.parent[e] = e
+	d.rank[e] = 0
+	d.sizes[e] = 1
+	return true
+}
+
+// Find 返回元素 e 所在集合的代表元素
+//
+// 如果元素不存在则返回 errs.NoSuchElement.
+func (d *DisjointSet) Find(e collection.Element) (collection.Element, error) {
+	if !d.Contains(e) {
+		return nil, errs.NoSuchElement
+	}
+	return d.find(e), nil
+}
+
+// find 返回元素 e 所在集合的根节点,并将路径上的节点直接指向根节点
+func (d *DisjointSet) find(e collection.Element) collection.Element {
+	root := e
+	for d.parent[root] != root {
+		root = d.parent[root]
+	}
+	for e != root {
+		next := d.parent[e]
+		d.parent[e] = root
+		e = next
+	}
+	return root
+}
+
+// Union 合并元素 a 与元素 b 所在的集合,不存在的元素会被自动添加
+//
+// 如果两个元素原本不在同一个集合中则返回 true,否则返回 false.
+func (d *DisjointSet) Union(a, b collection.Element) bool {
+	d.Add(a)
+	d.Add(b)
+	rootA, rootB := d.find(a), d.find(b)
+	if rootA == rootB {
+		return false
+	}
+	// 将秩较小的树合并到秩较大的树下
+	if d.rank[rootA] < d.rank[rootB] {
+		rootA, rootB = rootB, rootA
+	}
+	d.parent[rootB] = rootA
+	if d.rank[rootA] == d.rank[rootB] {
+		d.rank[rootA]++
+	}
+	d.sizes[rootA] += d.sizes[rootB]
+	delete(d.rank, rootB)
+	delete(d.sizes, rootB)
+	return true
+}
+
+// Connected 如果元素 a 与元素 b 在同一个集合中则返回 true,否则返回 false
+//
+// 任一元素不存在时返回 false.
+func (d *DisjointSet) Connected(a, b collection.Element) bool {
+	if !d.Contains(a) || !d.Contains(b) {
+		return false
+	}
+	return d.find(a) == d.find(b)
+}
+
+// SetSize 返回元素 e 所在集合的大小
+//
+// 如果元素不存在则返回 errs.NoSuchElement.
+func (d *DisjointSet) SetSize(e collection.Element) (int, error) {
+	if !d.Contains(e) {
+		return 0, errs.NoSuchElement
+	}
+	return d.sizes[d.find(e)], nil
+}
+
+// Group 返回元素 e 所在集合的所有元素
+//
+// 返回的集是副本,修改不会影响并查集.
+// 如果元素不存在则返回 errs.NoSuchElement.
+func (d *DisjointSet) Group(e collection.Element) (collection.Set, error) {
+	if !d.Contains(e) {
+		return nil, errs.NoSuchElement
+	}
+	root := d.find(e)
+	group := NewHashSet()
+	for x := range d.parent {
+		if d.find(x) == root {
+			group.data[x] = struct{}{}
+		}
+	}
+	return group, nil
+}
+
+// Groups 返回所有集合
+//
+// 返回的集是副本,修改不会影响并查集,集合之间的顺序是不确定的.
+func (d *DisjointSet) Groups() []collection.Set {
+	groups := make(map[collection.Element]*HashSet, len(d.sizes))
+	for x := range d.parent {
+		root := d.find(x)
+		group, ok := groups[root]
+		if !ok {
+			group = &HashSet{data: make(map[collection.Element]struct{}, d.sizes[root])}
+			groups[root] = group
+		}
+		group.data[x] = struct{}{}
+	}
+	sets := make([]collection.Set, 0, len(groups))
+	for _, group := range groups {
+		sets = append(sets, group)
+	}
+	return sets
+}
+
+// Clear 删除所有元素
+func (d *DisjointSet) Clear() {
+	d.parent = make(map[collection.Element]collection.Element)
+	d.rank = make(map[collection.Element]int)
+	d.sizes = make(map[collection.Element]int)
+}
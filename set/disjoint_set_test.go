@@ -0,0 +1,121 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package set
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisjointSet(t *testing.T) {
+	d := NewDisjointSetWithElements(1, 2, 3, 4, 5)
+	assert.Equal(t, 5, d.Size())
+	assert.Equal(t, 5, d.SetCount())
+	assert.False(t, d.Add(1))
+
+	assert.True(t, d.Union(1, 2))
+	assert.True(t, d.Union(3, 4))
+	assert.True(t, d.Union(2, 4))
+	assert.False(t, d.Union(1, 3))
+	assert.True(t, d.Connected(1, 4))
+	assert.False(t, d.Connected(1, 5))
+	assert.False(t, d.Connected(1, 6))
+	assert.Equal(t, 2, d.SetCount())
+
+	size, err := d.SetSize(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, size)
+	_, err = d.SetSize(6)
+	assert.Equal(t, errs.NoSuchElement, err)
+
+	r1, err := d.Find(1)
+	assert.Nil(t, err)
+	r4, _ := d.Find(4)
+	assert.Equal(t, r1, r4)
+	_, err = d.Find(6)
+	assert.Equal(t, errs.NoSuchElement, err)
+
+	group, err := d.Group(2)
+	assert.Nil(t, err)
+	assert.True(t, NewHashSetWithElements(1, 2, 3, 4).Equals(group))
+	_, err = d.Group(6)
+	assert.Equal(t, errs.NoSuchElement, err)
+
+	groups := d.Groups()
+	assert.Len(t, groups, 2)
+	for _, g := range groups {
+		if g.Size() == 1 {
+			assert.True(t, NewHashSetWithElements(5).Equals(g))
+		} else {
+			assert.True(t, NewHashSetWithElements(1, 2, 3, 4).Equals(g))
+		}
+	}
+
+	// 合并不存在的元素时自动添加
+	assert.True(t, d.Union("a", "b"))
+	assert.Equal(t, 7, d.Size())
+	assert.Equal(t, 3, d.SetCount())
+
+	d.Clear()
+	assert.Equal(t, 0, d.Size())
+	assert.Equal(t, 0, d.SetCount())
+}
+
+func TestDisjointSet_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(2021))
+	const n = 200
+	d := NewDisjointSet()
+	// 参照模型:每个元素所属集合的编号
+	label := make([]int, n)
+	for i := range label {
+		label[i] = i
+		d.Add(i)
+	}
+	for step := 0; step < 500; step++ {
+		a, b := r.Intn(n), r.Intn(n)
+		merged := label[a] != label[b]
+		assert.Equal(t, merged, d.Union(a, b))
+		if merged {
+			old := label[b]
+			for i := range label {
+				if label[i] == old {
+					label[i] = label[a]
+				}
+			}
+		}
+		x, y := r.Intn(n), r.Intn(n)
+		assert.Equal(t, label[x] == label[y], d.Connected(x, y))
+	}
+	sizes := make(map[int]int)
+	for _, l := range label {
+		sizes[l]++
+	}
+	assert.Equal(t, len(sizes), d.SetCount())
+	for i := 0; i < n; i++ {
+		size, _ := d.SetSize(i)
+		assert.Equal(t, sizes[label[i]], size)
+	}
+	total := 0
+	for _, g := range d.Groups() {
+		total += g.Size()
+	}
+	assert.Equal(t, n, total)
+}
@@ -0,0 +1,416 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package set
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
+	"github.com/chenquan/go-util/internal/hashcode"
+)
+
+var _ collection.Multiset = (*HashMultiset)(nil)
+
+var (
+	NegativeCountErr = fmt.Errorf("count cannot be negative: %w", errs.IllegalArgument)
+)
+
+// HashMultiset 基于哈希表实现 collection.Multiset 接口
+//
+// 元素必须是可比较的(可作为 map 的键),迭代顺序是不确定的,
+// 但同一元素的所有出现总是连续的.
+// 注意 HashMultiset 协程不安全,不能用于高并发.
+type HashMultiset struct {
+	counts      map[collection.Element]int // 元素出现的次数
+	size        int                        // 所有元素出现的总次数
+	elementType reflect.Type               // 解码时元素的类型
+}
+
+// NewHashMultiset 创建空的哈希多重集
+func NewHashMultiset() *HashMultiset {
+	return &HashMultiset{counts: make(map[collection.Element]int)}
+}
+
+// NewHashMultisetWithCollection 由指定集合创建哈希多重集
+func NewHashMultisetWithCollection(c collection.Collection) *HashMultiset {
+	m := NewHashMultiset()
+	for _, e := range c.Slice() {
+		m.counts[e]++
+		m.size++
+	}
+	return m
+}
+
+// Size 返回所有元素出现的总次数
+func (m *HashMultiset) Size() int {
+	return m.size
+}
+
+// IsEmpty 如果不存在元素则返回 true,否则返回 false
+func (m *HashMultiset) IsEmpty() bool {
+	return m.size == 0
+}
+
+// Count 返回指定元素在当前多重集中出现的次数
+func (m *HashMultiset) Count(e collection.Element) int {
+	return m.counts[e]
+}
+
+// Contains 如果当前多重集包含元素 e 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (m *HashMultiset) Contains(e collection.Element) (bool, error) {
+	return m.counts[e] > 0, nil
+}
+
+// Add 将指定元素的次数加一
+//
+// 当前返回值总为 true, nil
+func (m *HashMultiset) Add(e collection.Element) (bool, error) {
+	m.counts[e]++
+	m.size++
+	return true, nil
+}
+
+// Remove 将指定元素的次数减一
+//
+// 如果当前多重集中存在指定元素则返回 true,否则返回 false.
+// 当前返回的error接口总为 nil
+func (m *HashMultiset) Remove(e collection.Element) (bool, error) {
+	old, _ := m.RemoveCount(e, 1)
+	return old > 0, nil
+}
+
+// AddCount 将指定元素的次数增加 n,并返回操作前的次数
+//
+// 如果 n 为负数则返回 NegativeCountErr.
+func (m *HashMultiset) AddCount(e collection.Element, n int) (int, error) {
+	old := m.counts[e]
+	if n < 0 {
+		return old, NegativeCountErr
+	}
+	if n > 0 {
+		m.counts[e] = old + n
+		m.size += n
+	}
+	return old, nil
+}
+
+// RemoveCount 将指定元素的次数减少 n(最少减为0),并返回操作前的次数
+//
+// 如果 n 为负数则返回 NegativeCountErr.
+func (m *HashMultiset) RemoveCount(e collection.Element, n int) (int, error) {
+	old := m.counts[e]
+	if n < 0 {
+		return old, NegativeCountErr
+	}
+	if old == 0 || n == 0 {
+		return old, nil
+	}
+	if n >= old {
+		delete(m.counts, e)
+		m.size -= old
+	} else {
+		m.counts[e] = old - n
+		m.size -= n
+	}
+	return old, nil
+}
+
+// SetCount 将指定元素的次数设置为 n,并返回操作前的次数
+//
+// 如果 n 为负数则返回 NegativeCountErr.
+func (m *HashMultiset) SetCount(e collection.Element, n int) (int, error) {
+	old := m.counts[e]
+	if n < 0 {
+		return old, NegativeCountErr
+	}
+	if n == 0 {
+		delete(m.counts, e)
+	} else {
+		m.counts[e] = n
+	}
+	m.size += n - old
+	return old, nil
+}
+
+// ElementSet 返回当前多重集中不重复元素的集合
+//
+// 返回的集合是当前多重集的快照,修改它不会影响当前多重集.
+func (m *HashMultiset) ElementSet() collection.Set {
+	s := &HashSet{data: make(map[collection.Element]struct{}, len(m.counts))}
+	for e := range m.counts {
+		s.data[e] = struct{}{}
+	}
+	return s
+}
+
+// ContainsAll 如果当前多重集包含指定集合中的所有元素，则返回 true,否则返回 false.
+//
+// 不考虑元素出现的次数.
+func (m *HashMultiset) ContainsAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	for _, e := range c.Slice() {
+		if m.counts[e] == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// AddAll 将指定集合中的所有元素添加到当前多重集中
+//
+// 如果调用 AddAll 改变了当前多重集,则返回 true,否则返回 false.
+func (m *HashMultiset) AddAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	for _, e := range c.Slice() {
+		m.counts[e]++
+		m.size++
+	}
+	return c.Size() != 0, nil
+}
+
+// RemoveAll 删除当前多重集中与指定集合相同的所有元素的所有出现
+//
+// 如果调用 RemoveAll 改变了当前多重集,则返回 true,否则返回 false.
+func (m *HashMultiset) RemoveAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for _, e := range c.Slice() {
+		if old, _ := m.SetCount(e, 0); old > 0 {
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// RetainAll 仅保留当前多重集中包含在指定集合中的元素
+//
+// 如果调用 RetainAll 改变了当前多重集,则返回 true,否则返回 false.
+func (m *HashMultiset) RetainAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for e, n := range m.counts {
+		contains, err := c.Contains(e)
+		if err != nil {
+			return modified, err
+		}
+		if !contains {
+			delete(m.counts, e)
+			m.size -= n
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// Clear 清空多重集中所有元素
+//
+// 当前返回的error接口总为 nil
+func (m *HashMultiset) Clear() error {
+	m.counts = make(map[collection.Element]int)
+	m.size = 0
+	return nil
+}
+
+// Equals 如果指定集合也是多重集,且每个元素出现的次数都相同则返回 true,否则返回 false
+func (m *HashMultiset) Equals(c collection.Collection) bool {
+	if c == nil {
+		return false
+	}
+	if m == c {
+		return true
+	}
+	o, ok := c.(collection.Multiset)
+	if !ok || m.size != o.Size() {
+		return false
+	}
+	for e, n := range m.counts {
+		if o.Count(e) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// HashCode 返回当前多重集的哈希码
+func (m *HashMultiset) HashCode() int {
+	h := 0
+	for e, n := range m.counts {
+		h += hashcode.Of(e) ^ n
+	}
+	return h
+}
+
+// Slice 返回一个包含当前多重集中所有元素的切片,每个元素按其次数重复出现
+//
+// 返回的切片是安全的,可任意修改不会影响当前多重集.
+func (m *HashMultiset) Slice() []collection.Element {
+	elements := make([]collection.Element, 0, m.size)
+	for e, n := range m.counts {
+		for i := 0; i < n; i++ {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+// ForEach 对多重集中的每个元素执行 action,每个元素按其次数重复执行,执行顺序不确定
+func (m *HashMultiset) ForEach(action function.Consumer) {
+	for e, n := range m.counts {
+		for i := 0; i < n; i++ {
+			action(e)
+		}
+	}
+}
+
+// RemoveIf 删除多重集中满足 filter 的所有元素,包括其所有重复
+//
+// 每个不同的元素只调用一次 filter.
+// 如果有元素被删除则返回 true,否则返回 false.
+// 如果 filter 为 nil 则返回 errs.NilPointer.
+func (m *HashMultiset) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for e, n := range m.counts {
+		if filter(e) {
+			delete(m.counts, e)
+			m.size -= n
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// Iterator 返回当前多重集中元素的迭代器,每个元素按其次数重复出现
+//
+// 迭代器遍历的是创建时刻的元素快照,迭代器的 Remove 方法使元素的次数减一.
+func (m *HashMultiset) Iterator() collection.Iterator {
+	return &itrHashMultiset{data: m, elements: m.Slice(), lastRet: -1}
+}
+
+// itrHashMultiset 实现 collection.Iterator 接口
+type itrHashMultiset struct {
+	data     *HashMultiset        // 多重集
+	elements []collection.Element // 元素快照
+	cursor   int                  // 游标,指向下一个元素
+	lastRet  int                  // 最近一次返回的下标
+}
+
+// HasNext 如果当前迭代还有更多的元素则返回 true,否则返回 false
+func (itr *itrHashMultiset) HasNext() bool {
+	return itr.cursor < len(itr.elements)
+}
+
+// Next 返回当前迭代中的下一个元素
+func (itr *itrHashMultiset) Next() (collection.Element, error) {
+	if !itr.HasNext() {
+		return nil, errs.NoSuchElement
+	}
+	itr.lastRet = itr.cursor
+	itr.cursor++
+	return itr.elements[itr.lastRet], nil
+}
+
+// Remove 使当前迭代器返回的最后一个元素的次数减一
+//
+// 每次调用 Next 方法,才可以调用一次此方法.
+func (itr *itrHashMultiset) Remove() error {
+	if itr.lastRet < 0 {
+		return errs.IllegalState
+	}
+	_, _ = itr.data.RemoveCount(itr.elements[itr.lastRet], 1)
+	itr.lastRet = -1
+	return nil
+}
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (m *HashMultiset) SetElementType(t reflect.Type) {
+	m.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,每个元素按其次数重复出现,编码为 JSON 数组
+func (m *HashMultiset) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(m.Slice())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换多重集中的所有元素
+func (m *HashMultiset) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, m.elementType)
+	if err != nil {
+		return err
+	}
+	m.setElements(elements)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (m *HashMultiset) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(m.Slice(), m.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换多重集中的所有元素
+func (m *HashMultiset) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, m.elementType)
+	if err != nil {
+		return err
+	}
+	m.setElements(elements)
+	return nil
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (m *HashMultiset) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (m *HashMultiset) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
+
+// String 返回多重集的字符串表示,形如 [1, 2, 3]
+func (m *HashMultiset) String() string {
+	return codec.String(m.Slice())
+}
+
+// setElements 使用指定元素替换多重集中的所有元素
+func (m *HashMultiset) setElements(elements []collection.Element) {
+	m.counts = make(map[collection.Element]int)
+	for _, e := range elements {
+		m.counts[e]++
+	}
+	m.size = len(elements)
+}
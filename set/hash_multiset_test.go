@@ -0,0 +1,149 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashMultiset(t *testing.T) {
+	m := NewHashMultiset()
+	_, _ = m.Add(1)
+	_, _ = m.Add(1)
+	_, _ = m.Add(2)
+	assert.Equal(t, 3, m.Size())
+	assert.Equal(t, 2, m.Count(1))
+	assert.Equal(t, []int{1, 1, 2}, sortedInts(m.Slice()))
+
+	old, err := m.AddCount(3, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, old)
+	assert.Equal(t, 7, m.Size())
+
+	old, _ = m.RemoveCount(3, 10)
+	assert.Equal(t, 4, old)
+	assert.Equal(t, 0, m.Count(3))
+	assert.Equal(t, 3, m.Size())
+
+	old, _ = m.SetCount(2, 5)
+	assert.Equal(t, 1, old)
+	assert.Equal(t, 7, m.Size())
+
+	_, err = m.SetCount(2, -1)
+	assert.Equal(t, NegativeCountErr, err)
+	assert.True(t, errors.Is(err, errs.IllegalArgument))
+
+	removed, _ := m.Remove(1)
+	assert.True(t, removed)
+	assert.Equal(t, 1, m.Count(1))
+
+	assert.True(t, m.ElementSet().Equals(NewHashSetWithElements(1, 2)))
+}
+
+func TestHashMultiset_Bulk(t *testing.T) {
+	m := NewHashMultisetWithCollection(NewHashSetWithElements(1, 2, 3))
+	_, _ = m.AddCount(1, 2)
+
+	modified, _ := m.RemoveAll(NewHashSetWithElements(1))
+	assert.True(t, modified)
+	assert.Equal(t, 2, m.Size())
+
+	modified, _ = m.RetainAll(NewHashSetWithElements(2))
+	assert.True(t, modified)
+	assert.Equal(t, 1, m.Size())
+
+	other := NewHashMultiset()
+	_, _ = other.Add(2)
+	assert.True(t, m.Equals(other))
+	assert.Equal(t, other.HashCode(), m.HashCode())
+	assert.False(t, m.Equals(NewHashSetWithElements(2)))
+}
+
+func TestHashMultiset_Iterator(t *testing.T) {
+	m := NewHashMultiset()
+	_, _ = m.AddCount("a", 3)
+	itr := m.Iterator()
+	n := 0
+	for itr.HasNext() {
+		e, _ := itr.Next()
+		assert.Equal(t, "a", e)
+		assert.Nil(t, itr.Remove())
+		assert.Equal(t, errs.IllegalState, itr.Remove())
+		n++
+	}
+	assert.Equal(t, 3, n)
+	assert.True(t, m.IsEmpty())
+}
+
+func TestHashMultiset_MarshalJSON(t *testing.T) {
+	m := NewHashMultiset()
+	_, _ = m.AddCount("a", 2)
+	data, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.Equal(t, `["a","a"]`, string(data))
+	assert.Equal(t, "[a, a]", m.String())
+
+	decoded := NewHashMultiset()
+	decoded.SetElementType(reflect.TypeOf(""))
+	assert.Nil(t, json.Unmarshal(data, decoded))
+	assert.True(t, m.Equals(decoded))
+
+	data, err = m.MarshalBinary()
+	assert.Nil(t, err)
+	decoded = NewHashMultiset()
+	assert.Nil(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, 2, decoded.Count("a"))
+}
+
+func TestHashMultiset_RemoveIf(t *testing.T) {
+	m := NewHashMultiset()
+	_, _ = m.AddCount("a", 3)
+	_, _ = m.AddCount("b", 2)
+
+	calls := 0
+	m.ForEach(func(e interface{}) {
+		calls++
+	})
+	assert.Equal(t, 5, calls)
+
+	calls = 0
+	modified, err := m.RemoveIf(func(e interface{}) bool {
+		calls++
+		return e == "a"
+	})
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, m.Size())
+	assert.Equal(t, 0, m.Count("a"))
+	assert.Equal(t, 2, m.Count("b"))
+}
+
+func TestHashMultiset_Conformance(t *testing.T) {
+	collectiontest.TestCollection(t, func() collection.Collection {
+		return NewHashMultiset()
+	})
+}
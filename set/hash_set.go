@@ -0,0 +1,351 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+// Package set 集的实现
+package set
+
+import (
+	"reflect"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/function"
+	"github.com/chenquan/go-util/internal/codec"
+	"github.com/chenquan/go-util/internal/hashcode"
+)
+
+var _ collection.Set = (*HashSet)(nil)
+
+// HashSet 基于哈希表实现 collection.Set 接口
+//
+// 元素必须是可比较的(可作为 map 的键),迭代顺序是不确定的.
+// 注意 HashSet 协程不安全,不能用于高并发.
+type HashSet struct {
+	data        map[collection.Element]struct{} // 数据
+	elementType reflect.Type                    // 解码时元素的类型
+}
+
+// NewHashSet 创建空的哈希集
+func NewHashSet() *HashSet {
+	return &HashSet{data: make(map[collection.Element]struct{})}
+}
+
+// NewHashSetWithCollection 由指定集合创建哈希集
+func NewHashSetWithCollection(c collection.Collection) *HashSet {
+	s := &HashSet{data: make(map[collection.Element]struct{}, c.Size())}
+	for _, e := range c.Slice() {
+		s.data[e] = struct{}{}
+	}
+	return s
+}
+
+// NewHashSetWithElements 由指定元素创建哈希集
+func NewHashSetWithElements(elements ...collection.Element) *HashSet {
+	s := &HashSet{data: make(map[collection.Element]struct{}, len(elements))}
+	for _, e := range elements {
+		s.data[e] = struct{}{}
+	}
+	return s
+}
+
+// Size 返回当前集的大小
+func (s *HashSet) Size() int {
+	return len(s.data)
+}
+
+// IsEmpty 如果不存在元素则返回 true,否则返回 false
+func (s *HashSet) IsEmpty() bool {
+	return len(s.data) == 0
+}
+
+// Contains 如果当前集包含元素 e 则返回 true,否则返回 false
+//
+// 当前返回的error接口总为 nil
+func (s *HashSet) Contains(e collection.Element) (bool, error) {
+	_, ok := s.data[e]
+	return ok, nil
+}
+
+// Add 添加指定元素
+//
+// 如果当前集已经包含该元素则返回 false,否则返回 true.
+// 当前返回的error接口总为 nil
+func (s *HashSet) Add(e collection.Element) (bool, error) {
+	if _, ok := s.data[e]; ok {
+		return false, nil
+	}
+	s.data[e] = struct{}{}
+	return true, nil
+}
+
+// Remove 删除指定元素
+//
+// 如果当前集中存在指定元素,则删除该元素并返回 true,否则返回 false.
+// 当前返回的error接口总为 nil
+func (s *HashSet) Remove(e collection.Element) (bool, error) {
+	if _, ok := s.data[e]; !ok {
+		return false, nil
+	}
+	delete(s.data, e)
+	return true, nil
+}
+
+// ContainsAll 如果当前集包含指定集合中的所有元素，则返回 true,否则返回 false.
+func (s *HashSet) ContainsAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	for _, e := range c.Slice() {
+		if _, ok := s.data[e]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// AddAll 将指定集合中的所有元素添加到当前集中
+//
+// 如果调用 AddAll 改变了当前集,则返回 true,否则返回 false.
+func (s *HashSet) AddAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for _, e := range c.Slice() {
+		if _, ok := s.data[e]; !ok {
+			s.data[e] = struct{}{}
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// RemoveAll 删除当前集中与指定集合相同的所有元素
+//
+// 如果调用 RemoveAll 改变了当前集,则返回 true,否则返回 false.
+func (s *HashSet) RemoveAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for _, e := range c.Slice() {
+		if _, ok := s.data[e]; ok {
+			delete(s.data, e)
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// RetainAll 仅保留当前集中包含在指定集合中的元素
+//
+// 如果调用 RetainAll 改变了当前集,则返回 true,否则返回 false.
+func (s *HashSet) RetainAll(c collection.Collection) (bool, error) {
+	if c == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for e := range s.data {
+		contains, err := c.Contains(e)
+		if err != nil {
+			return modified, err
+		}
+		if !contains {
+			delete(s.data, e)
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// Clear 清空集中所有元素
+//
+// 当前返回的error接口总为 nil
+func (s *HashSet) Clear() error {
+	s.data = make(map[collection.Element]struct{})
+	return nil
+}
+
+// Equals 如果指定集合与当前集包含相同的元素且不含重复元素则返回 true,否则返回 false
+func (s *HashSet) Equals(c collection.Collection) bool {
+	if c == nil {
+		return false
+	}
+	if s == c {
+		return true
+	}
+	if s.Size() != c.Size() {
+		return false
+	}
+	// 大小相同时,c 中互不相同的元素都在当前集中且个数等于当前集的大小才相等
+	seen := make(map[collection.Element]struct{}, c.Size())
+	for _, e := range c.Slice() {
+		if _, ok := s.data[e]; !ok {
+			return false
+		}
+		seen[e] = struct{}{}
+	}
+	return len(seen) == s.Size()
+}
+
+// HashCode 返回当前集的哈希码,即所有元素哈希码之和
+func (s *HashSet) HashCode() int {
+	h := 0
+	for e := range s.data {
+		h += hashcode.Of(e)
+	}
+	return h
+}
+
+// Slice 返回一个包含当前集中所有元素的切片
+//
+// 返回的切片是安全的,可任意修改不会影响当前集.
+func (s *HashSet) Slice() []collection.Element {
+	elements := make([]collection.Element, 0, len(s.data))
+	for e := range s.data {
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+// ForEach 对集中的每个元素执行 action,执行顺序不确定
+func (s *HashSet) ForEach(action function.Consumer) {
+	for e := range s.data {
+		action(e)
+	}
+}
+
+// RemoveIf 删除集中满足 filter 的所有元素
+//
+// 如果有元素被删除则返回 true,否则返回 false.
+// 如果 filter 为 nil 则返回 errs.NilPointer.
+func (s *HashSet) RemoveIf(filter function.Predicate) (bool, error) {
+	if filter == nil {
+		return false, errs.NilPointer
+	}
+	modified := false
+	for e := range s.data {
+		if filter(e) {
+			delete(s.data, e)
+			modified = true
+		}
+	}
+	return modified, nil
+}
+
+// Iterator 返回当前集中元素的迭代器
+//
+// 迭代器遍历的是创建时刻的元素快照,迭代顺序是不确定的.
+func (s *HashSet) Iterator() collection.Iterator {
+	return &itrHashSet{data: s, elements: s.Slice(), lastRet: -1}
+}
+
+// itrHashSet 实现 collection.Iterator 接口
+type itrHashSet struct {
+	data     *HashSet             // 集
+	elements []collection.Element // 元素快照
+	cursor   int                  // 游标,指向下一个元素
+	lastRet  int                  // 最近一次返回的下标
+}
+
+// HasNext 如果当前迭代还有更多的元素则返回 true,否则返回 false
+func (itr *itrHashSet) HasNext() bool {
+	return itr.cursor < len(itr.elements)
+}
+
+// Next 返回当前迭代中的下一个元素
+func (itr *itrHashSet) Next() (collection.Element, error) {
+	if !itr.HasNext() {
+		return nil, errs.NoSuchElement
+	}
+	itr.lastRet = itr.cursor
+	itr.cursor++
+	return itr.elements[itr.lastRet], nil
+}
+
+// Remove 从集中移除当前迭代器返回的最后一个元素
+//
+// 每次调用 Next 方法,才可以调用一次此方法.
+func (itr *itrHashSet) Remove() error {
+	if itr.lastRet < 0 {
+		return errs.IllegalState
+	}
+	delete(itr.data.data, itr.elements[itr.lastRet])
+	itr.lastRet = -1
+	return nil
+}
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (s *HashSet) SetElementType(t reflect.Type) {
+	s.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,编码为 JSON 数组
+func (s *HashSet) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(s.Slice())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换集中的所有元素
+func (s *HashSet) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, s.elementType)
+	if err != nil {
+		return err
+	}
+	s.setElements(elements)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (s *HashSet) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(s.Slice(), s.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换集中的所有元素
+func (s *HashSet) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, s.elementType)
+	if err != nil {
+		return err
+	}
+	s.setElements(elements)
+	return nil
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (s *HashSet) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (s *HashSet) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// String 返回集的字符串表示,形如 [1, 2, 3]
+func (s *HashSet) String() string {
+	return codec.String(s.Slice())
+}
+
+// setElements 使用指定元素替换集中的所有元素
+func (s *HashSet) setElements(elements []collection.Element) {
+	s.data = make(map[collection.Element]struct{}, len(elements))
+	for _, e := range elements {
+		s.data[e] = struct{}{}
+	}
+}
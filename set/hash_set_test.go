@@ -0,0 +1,140 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/backend/collection/collectiontest"
+	"github.com/chenquan/go-util/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedInts(elements []collection.Element) []int {
+	ints := make([]int, 0, len(elements))
+	for _, e := range elements {
+		ints = append(ints, e.(int))
+	}
+	sort.Ints(ints)
+	return ints
+}
+
+func TestHashSet(t *testing.T) {
+	s := NewHashSet()
+	assert.True(t, s.IsEmpty())
+
+	added, err := s.Add(1)
+	assert.Nil(t, err)
+	assert.True(t, added)
+	added, _ = s.Add(1)
+	assert.False(t, added)
+	_, _ = s.Add(2)
+	assert.Equal(t, 2, s.Size())
+
+	contains, _ := s.Contains(2)
+	assert.True(t, contains)
+	removed, _ := s.Remove(2)
+	assert.True(t, removed)
+	removed, _ = s.Remove(2)
+	assert.False(t, removed)
+	assert.Equal(t, []int{1}, sortedInts(s.Slice()))
+
+	assert.Nil(t, s.Clear())
+	assert.True(t, s.IsEmpty())
+}
+
+func TestHashSet_Bulk(t *testing.T) {
+	s := NewHashSetWithElements(1, 2, 3)
+	o := NewHashSetWithElements(3, 4)
+
+	modified, _ := s.AddAll(o)
+	assert.True(t, modified)
+	assert.Equal(t, []int{1, 2, 3, 4}, sortedInts(s.Slice()))
+	contains, _ := s.ContainsAll(o)
+	assert.True(t, contains)
+
+	modified, _ = s.RemoveAll(NewHashSetWithElements(1, 9))
+	assert.True(t, modified)
+	assert.Equal(t, []int{2, 3, 4}, sortedInts(s.Slice()))
+
+	modified, _ = s.RetainAll(o)
+	assert.True(t, modified)
+	assert.Equal(t, []int{3, 4}, sortedInts(s.Slice()))
+	assert.True(t, s.Equals(o))
+	assert.Equal(t, o.HashCode(), s.HashCode())
+	assert.False(t, s.Equals(NewHashSetWithElements(3)))
+
+	// 含重复元素的集合即使大小相同且元素都在当前集中也不相等
+	m := NewHashMultiset()
+	_, _ = m.AddCount(3, 2)
+	assert.Equal(t, s.Size(), m.Size())
+	assert.False(t, s.Equals(m))
+
+	_, err := s.AddAll(nil)
+	assert.Equal(t, errs.NilPointer, err)
+}
+
+func TestHashSet_Iterator(t *testing.T) {
+	s := NewHashSetWithElements(1, 2, 3)
+	itr := s.Iterator()
+	assert.Equal(t, errs.IllegalState, itr.Remove())
+	for itr.HasNext() {
+		e, err := itr.Next()
+		assert.Nil(t, err)
+		if e.(int)%2 == 1 {
+			assert.Nil(t, itr.Remove())
+		}
+	}
+	_, err := itr.Next()
+	assert.Equal(t, errs.NoSuchElement, err)
+	assert.Equal(t, []int{2}, sortedInts(s.Slice()))
+}
+
+func TestHashSet_MarshalJSON(t *testing.T) {
+	s := NewHashSetWithElements(1, 2, 3)
+	data, err := json.Marshal(s)
+	assert.Nil(t, err)
+
+	decoded := NewHashSet()
+	decoded.SetElementType(reflect.TypeOf(0))
+	assert.Nil(t, json.Unmarshal(data, decoded))
+	assert.True(t, s.Equals(decoded))
+
+	data, err = s.GobEncode()
+	assert.Nil(t, err)
+	decoded = NewHashSet()
+	assert.Nil(t, decoded.GobDecode(data))
+	assert.True(t, s.Equals(decoded))
+	assert.Equal(t, "[1]", NewHashSetWithElements(1).String())
+}
+
+func TestHashSet_Conformance(t *testing.T) {
+	newSet := func() collection.Set {
+		return NewHashSet()
+	}
+	t.Run("Set", func(t *testing.T) {
+		collectiontest.TestSet(t, newSet)
+	})
+	t.Run("SetModel", func(t *testing.T) {
+		collectiontest.TestSetModel(t, newSet)
+	})
+}
@@ -18,13 +18,18 @@
 package stack
 
 import (
-	"errors"
+	"reflect"
+
+	"github.com/chenquan/go-util/backend/collection"
+	"github.com/chenquan/go-util/errs"
+	"github.com/chenquan/go-util/internal/codec"
 )
 
 // 实现栈
 var _ Stacker = (*Stack)(nil)
 var (
-	NotExistErr = errors.New("not exist")
+	// NotExistErr 栈为空,与 errs.NoSuchElement 相同
+	NotExistErr = errs.NoSuchElement
 )
 
 // NewStack 创建栈
@@ -35,8 +40,9 @@ func NewStack() *Stack {
 
 type (
 	Stack struct {
-		top    *node
-		length int
+		top         *node
+		length      int
+		elementType reflect.Type // 解码时元素的类型
 	}
 	node struct {
 		data interface{}
@@ -55,19 +61,19 @@ func (stack *Stack) Len() int {
 }
 
 // Peek 返回栈顶
-// 当栈顶为空时,返回 nil, NotExistErr
+// 当栈顶为空时,返回 nil 与 errs.NoSuchElement
 func (stack *Stack) Peek() (interface{}, error) {
 	if stack.length == 0 {
-		return nil, NotExistErr
+		return nil, errs.NewError(errs.NoSuchElement, "Stack.Peek")
 	}
 	return stack.top.data, nil
 }
 
 // Pop 移除并返回当前栈顶
-// 当栈顶为空时,返回 nil, NotExistErr
+// 当栈顶为空时,返回 nil 与 errs.NoSuchElement
 func (stack *Stack) Pop() (interface{}, error) {
 	if stack.length == 0 {
-		return nil, NotExistErr
+		return nil, errs.NewError(errs.NoSuchElement, "Stack.Pop")
 	}
 	stack.length--
 	top := stack.top
@@ -91,3 +97,77 @@ func (stack *Stack) Clean() {
 	stack.top = nil
 	stack.length = 0
 }
+
+// SetElementType 设置解码时元素的类型
+//
+// 设置后 UnmarshalJSON 会将每个元素解码为该类型,
+// MarshalBinary 与 UnmarshalBinary 会自动向 encoding/gob 注册该类型.
+func (stack *Stack) SetElementType(t reflect.Type) {
+	stack.elementType = t
+}
+
+// MarshalJSON 实现 json.Marshaler 接口,按从栈底到栈顶的顺序编码为 JSON 数组
+func (stack *Stack) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(stack.elements())
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口,使用 JSON 数组替换栈中的所有元素
+//
+// 数组的最后一个元素为栈顶.
+func (stack *Stack) UnmarshalJSON(data []byte) error {
+	elements, err := codec.UnmarshalJSON(data, stack.elementType)
+	if err != nil {
+		return err
+	}
+	stack.setElements(elements)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+func (stack *Stack) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(stack.elements(), stack.elementType)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口,替换栈中的所有元素
+func (stack *Stack) UnmarshalBinary(data []byte) error {
+	elements, err := codec.UnmarshalBinary(data, stack.elementType)
+	if err != nil {
+		return err
+	}
+	stack.setElements(elements)
+	return nil
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (stack *Stack) GobEncode() ([]byte, error) {
+	return stack.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (stack *Stack) GobDecode(data []byte) error {
+	return stack.UnmarshalBinary(data)
+}
+
+// String 返回栈的字符串表示,按从栈底到栈顶的顺序,形如 [1, 2, 3]
+func (stack *Stack) String() string {
+	return codec.String(stack.elements())
+}
+
+// elements 返回从栈底到栈顶的所有元素
+func (stack *Stack) elements() []collection.Element {
+	elements := make([]collection.Element, stack.length)
+	i := stack.length - 1
+	for n := stack.top; n != nil; n = n.prev {
+		elements[i] = n.data
+		i--
+	}
+	return elements
+}
+
+// setElements 使用指定元素替换栈中的所有元素,最后一个元素为栈顶
+func (stack *Stack) setElements(elements []collection.Element) {
+	stack.Clean()
+	for _, e := range elements {
+		stack.Push(e)
+	}
+}
@@ -18,6 +18,8 @@
 package stack
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"reflect"
 	"testing"
@@ -72,12 +74,12 @@ func testStack(t *testing.T, stack Stacker) {
 	assert.Equal(t, 0, stack.Len())
 
 	pop, err = stack.Pop()
-	assert.Equal(t, NotExistErr, err)
+	assert.True(t, errors.Is(err, NotExistErr))
 	assert.Equal(t, nil, pop)
 	assert.Equal(t, 0, stack.Len())
 
 	peek, err = stack.Peek()
-	assert.Equal(t, NotExistErr, err)
+	assert.True(t, errors.Is(err, NotExistErr))
 	assert.Equal(t, nil, peek)
 	assert.Equal(t, 0, stack.Len())
 }
@@ -92,3 +94,36 @@ func TestStack(t *testing.T) {
 		testStack(t, s)
 	}
 }
+
+func TestStack_MarshalJSON(t *testing.T) {
+	stack := NewStack()
+	stack.Push(1)
+	stack.Push(2)
+	data, err := json.Marshal(stack)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[1,2]", string(data))
+	assert.Equal(t, "[1, 2]", stack.String())
+
+	decoded := NewStack()
+	decoded.SetElementType(reflect.TypeOf(0))
+	assert.Equal(t, nil, json.Unmarshal(data, decoded))
+	top, _ := decoded.Pop()
+	assert.Equal(t, 2, top)
+	assert.Equal(t, 1, decoded.Len())
+
+	data, err = stack.MarshalBinary()
+	assert.Equal(t, nil, err)
+	decoded = NewStack()
+	assert.Equal(t, nil, decoded.GobDecode(data))
+	assert.Equal(t, "[1, 2]", decoded.String())
+
+	syncStack := NewDefaultSyncStack()
+	assert.Equal(t, nil, syncStack.UnmarshalJSON([]byte("[1,2]")))
+	data, err = json.Marshal(syncStack)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[1,2]", string(data))
+	assert.Equal(t, "[1, 2]", syncStack.String())
+
+	_, err = (&SyncStack{}).MarshalBinary()
+	assert.NotNil(t, err)
+}
@@ -17,7 +17,12 @@
 
 package stack
 
-import "sync"
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
 
 var _ Stacker = (*SyncStack)(nil)
 
@@ -79,3 +84,72 @@ func (stack *SyncStack) Clean() {
 	defer stack.lock.Unlock()
 	stack.stack.Clean()
 }
+
+// MarshalJSON 实现 json.Marshaler 接口
+//
+// 底层栈需要实现 json.Marshaler 接口,否则返回错误.
+func (stack *SyncStack) MarshalJSON() ([]byte, error) {
+	stack.lock.RLock()
+	defer stack.lock.RUnlock()
+	m, ok := stack.stack.(json.Marshaler)
+	if !ok {
+		return nil, fmt.Errorf("stack: %T does not implement json.Marshaler", stack.stack)
+	}
+	return m.MarshalJSON()
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+//
+// 底层栈需要实现 json.Unmarshaler 接口,否则返回错误.
+func (stack *SyncStack) UnmarshalJSON(data []byte) error {
+	stack.lock.Lock()
+	defer stack.lock.Unlock()
+	u, ok := stack.stack.(json.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("stack: %T does not implement json.Unmarshaler", stack.stack)
+	}
+	return u.UnmarshalJSON(data)
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler 接口
+//
+// 底层栈需要实现 encoding.BinaryMarshaler 接口,否则返回错误.
+func (stack *SyncStack) MarshalBinary() ([]byte, error) {
+	stack.lock.RLock()
+	defer stack.lock.RUnlock()
+	m, ok := stack.stack.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("stack: %T does not implement encoding.BinaryMarshaler", stack.stack)
+	}
+	return m.MarshalBinary()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler 接口
+//
+// 底层栈需要实现 encoding.BinaryUnmarshaler 接口,否则返回错误.
+func (stack *SyncStack) UnmarshalBinary(data []byte) error {
+	stack.lock.Lock()
+	defer stack.lock.Unlock()
+	u, ok := stack.stack.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("stack: %T does not implement encoding.BinaryUnmarshaler", stack.stack)
+	}
+	return u.UnmarshalBinary(data)
+}
+
+// GobEncode 实现 gob.GobEncoder 接口
+func (stack *SyncStack) GobEncode() ([]byte, error) {
+	return stack.MarshalBinary()
+}
+
+// GobDecode 实现 gob.GobDecoder 接口
+func (stack *SyncStack) GobDecode(data []byte) error {
+	return stack.UnmarshalBinary(data)
+}
+
+// String 返回底层栈的字符串表示
+func (stack *SyncStack) String() string {
+	stack.lock.RLock()
+	defer stack.lock.RUnlock()
+	return fmt.Sprint(stack.stack)
+}
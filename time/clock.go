@@ -0,0 +1,193 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package time
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时钟
+//
+// 需要读取当前时间或等待一段时间的组件应依赖 Clock 而不是直接调用 time 包,
+// 以便在测试中注入 ManualClock 精确地控制时间.
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+	// NewTimer 创建一个在经过 d 后向通道发送当前时间的定时器
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer 定时器
+type Timer interface {
+	// C 返回定时器到期时接收时间的通道
+	C() <-chan time.Time
+	// Stop 停止定时器
+	//
+	// 如果定时器被停止则返回 true,如果定时器已经到期或已被停止则返回 false.
+	Stop() bool
+}
+
+// SystemClock 使用系统时间的时钟
+var SystemClock Clock = systemClock{}
+
+// systemClock 使用系统时间的时钟
+type systemClock struct{}
+
+// Now 返回当前系统时间
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer 创建系统定时器
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer 系统定时器
+type systemTimer struct {
+	timer *time.Timer
+}
+
+// C 返回定时器到期时接收时间的通道
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop 停止定时器
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// ManualClock 手动控制的时钟
+//
+// 时间只在调用 Advance 或 Set 时改变,到期的定时器随之触发,常用于测试.
+// ManualClock 协程安全.
+type ManualClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*manualTimer // 未到期的定时器
+}
+
+// NewManualClock 创建当前时间为 now 的手动时钟
+func NewManualClock(now time.Time) *ManualClock {
+	c := &ManualClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 返回时钟的当前时间
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer 创建一个在时钟前进 d 后触发的定时器
+//
+// 如果 d 小于等于0,定时器立即触发.
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance 将时钟前进 d,并触发所有到期的定时器
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set 将时钟设置为 t,并触发所有到期的定时器
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// setLocked 在持有锁的情况下设置当前时间
+func (c *ManualClock) setLocked(t time.Time) {
+	c.now = t
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+		} else {
+			timer.c <- t
+		}
+	}
+	for i := len(pending); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// Timers 返回未到期且未停止的定时器个数
+func (c *ManualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// WaitTimers 阻塞直到未到期且未停止的定时器个数不少于 n
+//
+// 用于在调用 Advance 前确认其他协程已经开始等待.
+func (c *ManualClock) WaitTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// manualTimer 手动时钟的定时器
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+// C 返回定时器到期时接收时间的通道
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop 停止定时器
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+/*
+ *    Copyright 2021 Chen Quan
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ *
+ */
+
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	now := SystemClock.Now()
+	assert.False(t, now.Before(before))
+
+	timer := SystemClock.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		assert.Fail(t, "timer did not fire")
+	}
+	assert.False(t, timer.Stop())
+	assert.True(t, SystemClock.NewTimer(time.Hour).Stop())
+}
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+	assert.Equal(t, start, c.Now())
+
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(3 * time.Second)
+	t3 := c.NewTimer(2 * time.Second)
+	assert.Equal(t, 3, c.Timers())
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), c.Now())
+	assert.Equal(t, start.Add(time.Second), <-t1.C())
+	assert.Equal(t, 2, c.Timers())
+	assert.False(t, t1.Stop())
+
+	assert.True(t, t3.Stop())
+	assert.False(t, t3.Stop())
+	assert.Equal(t, 1, c.Timers())
+
+	c.Set(start.Add(time.Minute))
+	assert.Equal(t, start.Add(time.Minute), <-t2.C())
+	assert.Equal(t, 0, c.Timers())
+	select {
+	case <-t3.C():
+		assert.Fail(t, "stopped timer fired")
+	default:
+	}
+
+	// 非正的时长立即触发
+	t4 := c.NewTimer(0)
+	assert.Equal(t, start.Add(time.Minute), <-t4.C())
+	assert.Equal(t, 0, c.Timers())
+}
+
+func TestManualClock_WaitTimers(t *testing.T) {
+	c := NewManualClock(time.Unix(0, 0))
+	fired := make(chan time.Time)
+	go func() {
+		fired <- <-c.NewTimer(time.Second).C()
+	}()
+	c.WaitTimers(1)
+	c.Advance(time.Second)
+	assert.Equal(t, time.Unix(1, 0), <-fired)
+}